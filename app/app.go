@@ -4,5 +4,23 @@ TUI for hulaki
 */
 package app
 
-func Render() {
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// Render opens the interactive request builder. Variables from env, if any,
+// are substituted into every request before it is sent.
+func Render(env *utils.Environment) error {
+	_, err := tea.NewProgram(NewRequestBuilder(env), tea.WithAltScreen(), tea.WithMouseCellMotion()).Run()
+	return err
+}
+
+// RenderGRPC opens the gRPC explorer for the server at address. args hold
+// the connection and descriptor options used for every call.
+func RenderGRPC(address string, env *utils.Environment, args []utils.Args) error {
+	explorer := NewGRPCExplorer(address, env, args)
+	defer explorer.Close()
+	_, err := tea.NewProgram(explorer, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run()
+	return err
 }
@@ -0,0 +1,618 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/textarea"
+	"github.com/charmbracelet/bubbles/v2/viewport"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/suryanshu-09/hulaki/styles"
+	"github.com/suryanshu-09/hulaki/utils"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	focusTree focus = iota
+	focusRequest
+	focusReply
+)
+
+var (
+	serviceStyle  = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Foreground(pink).Bold(true)
+)
+
+// grpcService is a service in the tree. Its methods are loaded the first
+// time it is expanded.
+type grpcService struct {
+	name     string
+	methods  []grpcMethod
+	loaded   bool
+	expanded bool
+}
+
+type grpcMethod struct {
+	name            string
+	clientStreaming bool
+	serverStreaming bool
+}
+
+// kind describes how the method streams, e.g. "server streaming".
+func (m grpcMethod) kind() string {
+	switch {
+	case m.clientStreaming && m.serverStreaming:
+		return "bidirectional streaming"
+	case m.clientStreaming:
+		return "client streaming"
+	case m.serverStreaming:
+		return "server streaming"
+	}
+	return "unary"
+}
+
+// grpcRow is a visible line of the tree: a service, or one of its methods
+// when method is not negative.
+type grpcRow struct {
+	service int
+	method  int
+}
+
+// grpcServicesMsg carries the client the explorer calls through, and the
+// services it lists.
+type grpcServicesMsg struct {
+	client   *utils.GRPCClient
+	services []string
+	err      error
+}
+
+// grpcMethodsMsg carries the methods of a service.
+type grpcMethodsMsg struct {
+	service int
+	methods []grpcMethod
+	err     error
+}
+
+// grpcTemplateMsg carries a request for the selected method.
+type grpcTemplateMsg struct {
+	template string
+	err      error
+}
+
+// grpcReplyMsg is a response message received during a call.
+type grpcReplyMsg struct {
+	message any
+}
+
+// grpcCallEndMsg reports that a call finished.
+type grpcCallEndMsg struct {
+	resp    *utils.GRPCResponse
+	elapsed time.Duration
+	err     error
+}
+
+// GRPCExplorer browses the services of a gRPC server in a tree and calls
+// their methods. The request editor is pre-filled with a request for the
+// method that can be sent as it is, and streamed responses are appended as
+// they arrive. One connection is used for the life of the explorer, and
+// closed by Close.
+type GRPCExplorer struct {
+	address  string
+	args     []utils.Args
+	client   *utils.GRPCClient
+	env      *utils.Environment
+	services []grpcService
+	cursor   int
+	service  string
+	method   *grpcMethod
+	request  textarea.Model
+	reply    viewport.Model
+	replies  []string
+	calls    chan tea.Msg
+	cancel   context.CancelFunc
+	status   string
+	err      error
+	focus    focus
+	loading  bool
+	sending  bool
+	width    int
+	height   int
+}
+
+// NewGRPCExplorer creates an explorer for the server at address. args are
+// passed to every call, and env is interpolated into each request.
+func NewGRPCExplorer(address string, env *utils.Environment, args []utils.Args) *GRPCExplorer {
+	ta := textarea.New()
+	ta.Styles = textarea.DefaultDarkStyles()
+	ta.VirtualCursor = true
+	ta.ShowLineNumbers = false
+	ta.Placeholder = "select a method to get a request template"
+	ta.CharLimit = 0
+
+	v := viewport.New()
+	v.MouseWheelEnabled = true
+	v.SoftWrap = true
+
+	return &GRPCExplorer{
+		address: address,
+		args:    args,
+		env:     env,
+		request: ta,
+		reply:   v,
+		loading: true,
+	}
+}
+
+func (ge *GRPCExplorer) Init() tea.Cmd {
+	address, args := ge.address, ge.args
+	return func() tea.Msg {
+		client, err := utils.NewGRPCClient(address, args...)
+		if err != nil {
+			return grpcServicesMsg{err: err}
+		}
+		services, err := client.Descriptors().ListServices()
+		if err != nil {
+			return grpcServicesMsg{client: client, err: lookupError(err)}
+		}
+		return grpcServicesMsg{client: client, services: services}
+	}
+}
+
+// Close cancels the call in progress and closes the connection to the
+// server.
+func (ge *GRPCExplorer) Close() error {
+	ge.stop()
+	if ge.client == nil {
+		return nil
+	}
+	return ge.client.Close()
+}
+
+func (ge *GRPCExplorer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		ge.resize(msg.Width, msg.Height)
+		return ge, nil
+
+	case grpcServicesMsg:
+		ge.loading = false
+		ge.client = msg.client
+		ge.err = msg.err
+		for _, name := range msg.services {
+			ge.services = append(ge.services, grpcService{name: name})
+		}
+		return ge, nil
+
+	case grpcMethodsMsg:
+		ge.loading = false
+		ge.err = msg.err
+		if msg.err == nil {
+			ge.services[msg.service].methods = msg.methods
+			ge.services[msg.service].loaded = true
+			ge.services[msg.service].expanded = true
+		}
+		return ge, nil
+
+	case grpcTemplateMsg:
+		ge.loading = false
+		ge.err = msg.err
+		if msg.err == nil {
+			ge.request.SetValue(msg.template)
+			return ge, ge.setFocus(focusRequest)
+		}
+		return ge, nil
+
+	case grpcReplyMsg:
+		data, _ := json.MarshalIndent(msg.message, "", "  ")
+		ge.replies = append(ge.replies, string(data))
+		ge.reply.SetContent(strings.Join(ge.replies, "\n"))
+		ge.reply.GotoBottom()
+		return ge, ge.nextCallMsg()
+
+	case grpcCallEndMsg:
+		ge.sending = false
+		ge.err = msg.err
+		if msg.err == nil {
+			ge.status = callStatus(msg.resp, msg.elapsed)
+			ge.reply.SetContent(formatGRPCResponse(msg.resp, ge.replies))
+		}
+		return ge, nil
+
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		ge.reply, cmd = ge.reply.Update(msg)
+		return ge, cmd
+
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			ge.stop()
+			return ge, tea.Quit
+		case "ctrl+s":
+			return ge, ge.send()
+		case "tab":
+			return ge, ge.setFocus((ge.focus + 1) % 3)
+		case "shift+tab":
+			return ge, ge.setFocus((ge.focus + 2) % 3)
+		}
+
+		switch ge.focus {
+		case focusTree:
+			return ge, ge.updateTree(msg)
+		case focusReply:
+			var cmd tea.Cmd
+			ge.reply, cmd = ge.reply.Update(msg)
+			return ge, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	ge.request, cmd = ge.request.Update(msg)
+	return ge, cmd
+}
+
+// updateTree moves through the tree. Enter expands a service or selects a
+// method.
+func (ge *GRPCExplorer) updateTree(msg tea.KeyPressMsg) tea.Cmd {
+	rows := ge.rows()
+	if len(rows) == 0 {
+		return nil
+	}
+	row := rows[min(ge.cursor, len(rows)-1)]
+
+	switch msg.String() {
+	case "up", "k":
+		ge.cursor = max(ge.cursor-1, 0)
+	case "down", "j":
+		ge.cursor = min(ge.cursor+1, len(rows)-1)
+	case "left", "h":
+		service := &ge.services[row.service]
+		if row.method < 0 && service.expanded {
+			service.expanded = false
+			return nil
+		}
+		// Jump back to the service of a method.
+		for i, r := range rows {
+			if r.service == row.service && r.method < 0 {
+				ge.cursor = i
+			}
+		}
+	case "enter", "right", "l":
+		if row.method >= 0 {
+			return ge.selectMethod(row)
+		}
+		service := &ge.services[row.service]
+		if service.loaded {
+			service.expanded = msg.String() == "right" || msg.String() == "l" || !service.expanded
+			return nil
+		}
+		return ge.loadMethods(row.service)
+	}
+	return nil
+}
+
+// rows lists the visible lines of the tree.
+func (ge *GRPCExplorer) rows() []grpcRow {
+	var rows []grpcRow
+	for i, service := range ge.services {
+		rows = append(rows, grpcRow{service: i, method: -1})
+		if service.expanded {
+			for j := range service.methods {
+				rows = append(rows, grpcRow{service: i, method: j})
+			}
+		}
+	}
+	return rows
+}
+
+// loadMethods describes a service in the background to list its methods.
+func (ge *GRPCExplorer) loadMethods(service int) tea.Cmd {
+	if ge.loading {
+		return nil
+	}
+	ge.loading = true
+
+	source, name := ge.client.Descriptors(), ge.services[service].name
+	return func() tea.Msg {
+		d, err := source.FindSymbol(name)
+		if err != nil {
+			return grpcMethodsMsg{service: service, err: lookupError(err)}
+		}
+		sd, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return grpcMethodsMsg{service: service, err: fmt.Errorf("%s is not a service", name)}
+		}
+		var methods []grpcMethod
+		for i := range sd.Methods().Len() {
+			md := sd.Methods().Get(i)
+			methods = append(methods, grpcMethod{
+				name:            string(md.Name()),
+				clientStreaming: md.IsStreamingClient(),
+				serverStreaming: md.IsStreamingServer(),
+			})
+		}
+		return grpcMethodsMsg{service: service, methods: methods}
+	}
+}
+
+// selectMethod makes row the method to call and loads a request for it
+// into the editor.
+func (ge *GRPCExplorer) selectMethod(row grpcRow) tea.Cmd {
+	if ge.loading || ge.sending {
+		return nil
+	}
+	ge.loading = true
+	ge.service = ge.services[row.service].name
+	ge.method = &ge.services[row.service].methods[row.method]
+	ge.status = ""
+	ge.replies = nil
+	ge.reply.SetContent("")
+
+	source, service, method := ge.client.Descriptors(), ge.service, ge.method.name
+	return func() tea.Msg {
+		md, err := utils.ResolveMethod(source, service, method)
+		if err != nil {
+			return grpcTemplateMsg{err: lookupError(err)}
+		}
+		// Unlike --template, which documents oneofs for editing, the editor
+		// gets a request that can be sent unedited.
+		data, err := json.MarshalIndent(utils.RequestTemplate(md.Input()), "", "  ")
+		return grpcTemplateMsg{template: string(data), err: err}
+	}
+}
+
+// send calls the selected method with the request in the editor. Response
+// messages are delivered one by one as grpcReplyMsg, then a grpcCallEndMsg.
+func (ge *GRPCExplorer) send() tea.Cmd {
+	if ge.sending {
+		return nil
+	}
+	if ge.method == nil {
+		ge.err = fmt.Errorf("please select a method")
+		return nil
+	}
+	// The request is sent as written when no environment is selected.
+	// Otherwise it is decoded first, as the grpc command does, so values
+	// holding quotes or backslashes keep it valid JSON.
+	body := []byte(ge.request.Value())
+	if ge.env != nil {
+		var err error
+		if body, err = ge.env.InterpolateJSON(body); err != nil {
+			ge.err = err
+			return nil
+		}
+	}
+
+	ge.sending = true
+	ge.err = nil
+	ge.status = ""
+	ge.replies = nil
+	ge.reply.SetContent("")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := make(chan tea.Msg)
+	ge.calls, ge.cancel = calls, cancel
+	// Once the call is cancelled nothing reads its messages any more.
+	deliver := func(msg tea.Msg) {
+		select {
+		case calls <- msg:
+		case <-ctx.Done():
+		}
+	}
+	client, service, method := ge.client, ge.service, ge.method.name
+	go func() {
+		defer close(calls)
+		defer cancel()
+		start := time.Now()
+		resp, err := client.StreamCall(ctx, service, method, utils.JSONRequestSource(bytes.NewReader(body)), func(msg any) {
+			deliver(grpcReplyMsg{message: msg})
+		})
+		deliver(grpcCallEndMsg{resp: resp, elapsed: time.Since(start), err: err})
+	}()
+	return ge.nextCallMsg()
+}
+
+// stop cancels the call in progress, if any.
+func (ge *GRPCExplorer) stop() {
+	if ge.cancel != nil {
+		ge.cancel()
+	}
+}
+
+// nextCallMsg waits for the next message of the call in progress. There is
+// none once the call is cancelled.
+func (ge *GRPCExplorer) nextCallMsg() tea.Cmd {
+	calls := ge.calls
+	return func() tea.Msg {
+		return <-calls
+	}
+}
+
+func (ge *GRPCExplorer) View() string {
+	if ge.width == 0 {
+		return ""
+	}
+
+	treeWidth := ge.treeWidth()
+	tree := ge.box(focusTree, treeWidth).Render(ge.treeView())
+
+	var title string
+	if ge.method != nil {
+		title = styles.Key.Render(fmt.Sprintf("%s/%s · %s", ge.service, ge.method.name, ge.method.kind()))
+	} else {
+		title = styles.Key.Render(ge.address)
+	}
+
+	var status string
+	switch {
+	case ge.sending:
+		status = styles.Key.Render("Sending...")
+	case ge.loading:
+		status = styles.Key.Render("Loading...")
+	case ge.err != nil:
+		status = errorStyle.Render(ge.err.Error())
+	case ge.status != "":
+		status = styles.Key.Render(ge.status)
+	default:
+		status = styles.Key.Render("No response yet")
+	}
+
+	right := lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		ge.box(focusRequest, ge.width-treeWidth).Render(ge.request.View()),
+		status,
+		ge.box(focusReply, ge.width-treeWidth).Render(ge.reply.View()),
+	)
+
+	help := helpStyle.Render("↑/↓ move · enter expand/select · ctrl+s send · tab focus · ctrl+c quit")
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Top, tree, right),
+		help,
+	)
+}
+
+// treeView renders the visible part of the tree, scrolled to the cursor.
+func (ge *GRPCExplorer) treeView() string {
+	rows := ge.rows()
+	height := ge.height - 3
+	width := ge.treeWidth() - 4
+	start := max(ge.cursor-height+1, 0)
+
+	lines := make([]string, 0, height)
+	for i := start; i < len(rows) && len(lines) < height; i++ {
+		row := rows[i]
+		service := ge.services[row.service]
+
+		var line string
+		if row.method < 0 {
+			marker := "▸ "
+			if service.expanded {
+				marker = "▾ "
+			}
+			line = marker + serviceStyle.Render(truncate(service.name, width-4))
+		} else {
+			method := service.methods[row.method]
+			name := truncate(method.name, width-6)
+			line = "    " + name
+			if kind := method.kind(); kind != "unary" && len(name)+len(kind)+3 <= width-6 {
+				line += tabStyle.Render("(" + kind + ")")
+			}
+		}
+		if i == ge.cursor && ge.focus == focusTree {
+			line = selectedStyle.Render("› ") + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	if len(rows) == 0 && !ge.loading {
+		lines = append(lines, tabStyle.Render("no services"))
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncate shortens s to at most width characters, marking the cut with an
+// ellipsis.
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width || width < 1 {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func (ge *GRPCExplorer) treeWidth() int {
+	return max(ge.width/3, 24)
+}
+
+func (ge *GRPCExplorer) box(f focus, width int) lipgloss.Style {
+	border := purple
+	if ge.focus == f {
+		border = pink
+	}
+	return lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(border).Width(width - 2)
+}
+
+func (ge *GRPCExplorer) resize(width, height int) {
+	ge.width = width
+	ge.height = height
+
+	// title (1), request border (2), status (1), reply border (2), help (1)
+	free := max(height-7, 2)
+	requestHeight := max(free/2, 1)
+	rightWidth := width - ge.treeWidth()
+
+	ge.request.SetWidth(rightWidth - 4)
+	ge.request.SetHeight(requestHeight)
+	ge.reply.SetWidth(rightWidth - 4)
+	ge.reply.SetHeight(free - requestHeight)
+}
+
+func (ge *GRPCExplorer) setFocus(f focus) tea.Cmd {
+	ge.focus = f
+	ge.request.Blur()
+	if f == focusRequest {
+		return ge.request.Focus()
+	}
+	return nil
+}
+
+// lookupError turns a failed reflection or descriptor lookup into an error
+// for the status line, without the RPC error prefix.
+func lookupError(err error) error {
+	return errors.New(status.Convert(err).Message())
+}
+
+// callStatus summarises how a call ended.
+func callStatus(resp *utils.GRPCResponse, elapsed time.Duration) string {
+	if resp.Error != nil {
+		return fmt.Sprintf("%s: %s · %s", resp.Error.Code, resp.Error.Message, elapsed.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("OK · %s", elapsed.Round(time.Millisecond))
+}
+
+// formatGRPCResponse lays out a finished call: headers, the messages that
+// were received, error details and trailers.
+func formatGRPCResponse(resp *utils.GRPCResponse, replies []string) string {
+	var buf strings.Builder
+	writeMetadata(&buf, "HEADERS", resp.Metadata)
+
+	if len(replies) > 0 {
+		buf.WriteString(styles.Heading.Render("MESSAGES") + "\n")
+		buf.WriteString(strings.Join(replies, "\n") + "\n")
+	}
+
+	if resp.Error != nil && len(resp.Error.Details) > 0 {
+		details, _ := json.MarshalIndent(resp.Error.Details, "", "  ")
+		buf.WriteString(styles.Heading.Render("DETAILS") + "\n")
+		buf.WriteString(string(details) + "\n")
+	}
+
+	writeMetadata(&buf, "TRAILERS", resp.Trailers)
+	return buf.String()
+}
+
+func writeMetadata(buf *strings.Builder, heading string, md map[string][]string) {
+	if len(md) == 0 {
+		return
+	}
+	buf.WriteString(styles.Heading.Render(heading) + "\n")
+	for _, key := range slices.Sorted(maps.Keys(md)) {
+		for _, value := range md[key] {
+			fmt.Fprintf(buf, "%s: %s\n", styles.Key.Render(key), value)
+		}
+	}
+}
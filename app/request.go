@@ -0,0 +1,399 @@
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/textarea"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	"github.com/charmbracelet/bubbles/v2/viewport"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/suryanshu-09/hulaki/styles"
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+type focus int
+
+const (
+	focusURL focus = iota
+	focusEditor
+	focusResponse
+)
+
+const (
+	tabParams = iota
+	tabHeaders
+	tabAuth
+	tabBody
+)
+
+var (
+	tabNames = []string{"Params", "Headers", "Auth", "Body"}
+	methods  = []string{
+		http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodHead, http.MethodOptions,
+	}
+	placeholders = []string{
+		"key=value, one per line",
+		"Header-Name=value, one per line",
+		"type=basic\nusername=user\npassword=pass\n\nor\n\ntype=bearer\ntoken=...",
+		"raw request body",
+	}
+)
+
+var (
+	pink   = lipgloss.Color("#ff1493")
+	purple = lipgloss.Color("#8a2be2")
+
+	methodStyle    = lipgloss.NewStyle().Background(pink).Bold(true).Padding(0, 1)
+	activeTabStyle = lipgloss.NewStyle().Foreground(pink).Bold(true).Underline(true).Padding(0, 1)
+	tabStyle       = lipgloss.NewStyle().Faint(true).Padding(0, 1)
+	helpStyle      = lipgloss.NewStyle().Faint(true).Margin(0, 0, 0, 1)
+	errorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5f5f")).Bold(true).Margin(0, 0, 0, 1)
+)
+
+// responseMsg carries the result of a request back to the UI.
+type responseMsg struct {
+	status  string
+	header  http.Header
+	body    []byte
+	elapsed time.Duration
+	err     error
+}
+
+// RequestBuilder is the HTTP request builder: a method/URL bar, tabs for
+// params, headers, auth and body, and a scrollable response pane.
+type RequestBuilder struct {
+	env      *utils.Environment
+	method   int
+	url      textinput.Model
+	tab      int
+	editors  []textarea.Model
+	response viewport.Model
+	status   string
+	err      error
+	focus    focus
+	sending  bool
+	width    int
+	height   int
+}
+
+// NewRequestBuilder creates a request builder that interpolates env into
+// each request it sends.
+func NewRequestBuilder(env *utils.Environment) *RequestBuilder {
+	url := textinput.New()
+	url.Prompt = ""
+	url.Placeholder = "https://api.example.com/users"
+	url.VirtualCursor = true
+	url.Focus()
+
+	editors := make([]textarea.Model, len(tabNames))
+	for i := range editors {
+		ta := textarea.New()
+		ta.Styles = textarea.DefaultDarkStyles()
+		ta.VirtualCursor = true
+		ta.ShowLineNumbers = false
+		ta.Placeholder = placeholders[i]
+		ta.CharLimit = 0
+		editors[i] = ta
+	}
+
+	v := viewport.New()
+	v.MouseWheelEnabled = true
+	v.SoftWrap = true
+
+	return &RequestBuilder{
+		env:      env,
+		url:      url,
+		editors:  editors,
+		response: v,
+	}
+}
+
+func (rb *RequestBuilder) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (rb *RequestBuilder) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		rb.resize(msg.Width, msg.Height)
+		return rb, nil
+
+	case responseMsg:
+		rb.sending = false
+		rb.err = msg.err
+		if msg.err == nil {
+			rb.status = fmt.Sprintf("%s · %s", msg.status, msg.elapsed.Round(time.Millisecond))
+			rb.response.SetContent(formatResponse(msg.header, msg.body))
+			rb.response.GotoTop()
+		}
+		return rb, nil
+
+	case tea.MouseWheelMsg:
+		var cmd tea.Cmd
+		rb.response, cmd = rb.response.Update(msg)
+		return rb, cmd
+
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return rb, tea.Quit
+		case "ctrl+s":
+			return rb, rb.send()
+		case "tab":
+			return rb, rb.setFocus((rb.focus + 1) % 3)
+		case "shift+tab":
+			return rb, rb.setFocus((rb.focus + 2) % 3)
+		case "ctrl+right", "alt+right":
+			return rb, rb.setTab((rb.tab + 1) % len(tabNames))
+		case "ctrl+left", "alt+left":
+			return rb, rb.setTab((rb.tab + len(tabNames) - 1) % len(tabNames))
+		case "alt+1", "alt+2", "alt+3", "alt+4":
+			return rb, rb.setTab(int(msg.String()[4] - '1'))
+		}
+
+		switch rb.focus {
+		case focusURL:
+			switch msg.String() {
+			case "enter":
+				return rb, rb.send()
+			case "up":
+				rb.method = (rb.method + len(methods) - 1) % len(methods)
+				return rb, nil
+			case "down":
+				rb.method = (rb.method + 1) % len(methods)
+				return rb, nil
+			}
+		case focusResponse:
+			var cmd tea.Cmd
+			rb.response, cmd = rb.response.Update(msg)
+			return rb, cmd
+		}
+	}
+
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+	rb.url, cmd = rb.url.Update(msg)
+	cmds = append(cmds, cmd)
+	rb.editors[rb.tab], cmd = rb.editors[rb.tab].Update(msg)
+	cmds = append(cmds, cmd)
+	return rb, tea.Batch(cmds...)
+}
+
+func (rb *RequestBuilder) View() string {
+	if rb.width == 0 {
+		return ""
+	}
+
+	urlBar := lipgloss.JoinHorizontal(lipgloss.Top, methodStyle.Render(methods[rb.method]), " ", rb.url.View())
+
+	tabs := make([]string, len(tabNames))
+	for i, name := range tabNames {
+		if i == rb.tab {
+			tabs[i] = activeTabStyle.Render(name)
+		} else {
+			tabs[i] = tabStyle.Render(name)
+		}
+	}
+
+	var status string
+	switch {
+	case rb.sending:
+		status = styles.Key.Render("Sending...")
+	case rb.err != nil:
+		status = errorStyle.Render(rb.err.Error())
+	case rb.status != "":
+		status = styles.Key.Render(rb.status)
+	default:
+		status = styles.Key.Render("No response yet")
+	}
+
+	help := helpStyle.Render("enter/ctrl+s send · ↑/↓ method · tab focus · ctrl+←/→ switch tab · ctrl+c quit")
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		rb.box(focusURL).Render(urlBar),
+		lipgloss.JoinHorizontal(lipgloss.Top, tabs...),
+		rb.box(focusEditor).Render(rb.editors[rb.tab].View()),
+		status,
+		rb.box(focusResponse).Render(rb.response.View()),
+		help,
+	)
+}
+
+func (rb *RequestBuilder) box(f focus) lipgloss.Style {
+	border := purple
+	if rb.focus == f {
+		border = pink
+	}
+	return lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(border).Width(rb.width - 2)
+}
+
+func (rb *RequestBuilder) resize(width, height int) {
+	rb.width = width
+	rb.height = height
+
+	// url box (3), tabs (1), editor border (2), status (1), response border (2), help (1)
+	free := max(height-10, 2)
+	editorHeight := max(free/3, 1)
+
+	rb.url.SetWidth(width - 16)
+	for i := range rb.editors {
+		rb.editors[i].SetWidth(width - 4)
+		rb.editors[i].SetHeight(editorHeight)
+	}
+	rb.response.SetWidth(width - 4)
+	rb.response.SetHeight(free - editorHeight)
+}
+
+func (rb *RequestBuilder) setFocus(f focus) tea.Cmd {
+	rb.focus = f
+	rb.url.Blur()
+	rb.editors[rb.tab].Blur()
+	switch f {
+	case focusURL:
+		return rb.url.Focus()
+	case focusEditor:
+		return rb.editors[rb.tab].Focus()
+	}
+	return nil
+}
+
+func (rb *RequestBuilder) setTab(tab int) tea.Cmd {
+	rb.editors[rb.tab].Blur()
+	rb.tab = tab
+	if rb.focus == focusEditor {
+		return rb.editors[rb.tab].Focus()
+	}
+	return nil
+}
+
+// send builds the request from the current inputs and sends it in the
+// background, reporting back with a responseMsg.
+func (rb *RequestBuilder) send() tea.Cmd {
+	if rb.sending {
+		return nil
+	}
+	rb.sending = true
+
+	method := methods[rb.method]
+	url := strings.TrimSpace(rb.url.Value())
+	params := parseKeyValueLines(rb.editors[tabParams].Value())
+	headers := parseKeyValueLines(rb.editors[tabHeaders].Value())
+	auth := parseKeyValueLines(rb.editors[tabAuth].Value())
+	body := rb.editors[tabBody].Value()
+	env := rb.env
+
+	return func() tea.Msg {
+		if url == "" {
+			return responseMsg{err: fmt.Errorf("please provide a url")}
+		}
+		var err error
+		if url, err = env.Interpolate(url); err != nil {
+			return responseMsg{err: err}
+		}
+		if params, err = env.InterpolateMap(params); err != nil {
+			return responseMsg{err: err}
+		}
+		if headers, err = env.InterpolateMap(headers); err != nil {
+			return responseMsg{err: err}
+		}
+		// Credentials are substituted before basic auth encodes them
+		if auth, err = env.InterpolateMap(auth); err != nil {
+			return responseMsg{err: err}
+		}
+		if err := applyAuth(headers, auth); err != nil {
+			return responseMsg{err: err}
+		}
+		// The body is sent as written when no environment is selected
+		if env != nil {
+			if body, err = env.Interpolate(body); err != nil {
+				return responseMsg{err: err}
+			}
+		}
+
+		start := time.Now()
+		resp, err := utils.HTTPMethods[method](url, utils.WithHeaders(headers), utils.WithParams(params), utils.WithBody(strings.NewReader(body)))
+		if err != nil {
+			return responseMsg{err: err}
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		return responseMsg{
+			status:  resp.Status,
+			header:  resp.Header,
+			body:    data,
+			elapsed: time.Since(start),
+			err:     err,
+		}
+	}
+}
+
+// applyAuth turns the auth tab into an Authorization header.
+func applyAuth(headers, auth map[string]string) error {
+	switch strings.ToLower(auth["type"]) {
+	case "":
+		if len(auth) > 0 {
+			return fmt.Errorf("auth: missing type (basic or bearer)")
+		}
+	case "basic":
+		creds := auth["username"] + ":" + auth["password"]
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(creds))
+	case "bearer":
+		headers["Authorization"] = "Bearer " + auth["token"]
+	default:
+		return fmt.Errorf("auth: unsupported type %q (must be basic or bearer)", auth["type"])
+	}
+	return nil
+}
+
+// parseKeyValueLines parses one key=value pair per line. "Key: value" is
+// accepted too, and blank lines and lines starting with # are skipped.
+func parseKeyValueLines(input string) map[string]string {
+	result := make(map[string]string)
+	for line := range strings.Lines(input) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sep := strings.IndexAny(line, "=:")
+		if sep <= 0 {
+			continue
+		}
+		result[strings.TrimSpace(line[:sep])] = strings.TrimSpace(line[sep+1:])
+	}
+	return result
+}
+
+func formatResponse(header http.Header, body []byte) string {
+	var buf strings.Builder
+	buf.WriteString(styles.Heading.Render("HEADERS") + "\n")
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			fmt.Fprintf(&buf, "%s: %s\n", styles.Key.Render(key), value)
+		}
+	}
+
+	buf.WriteString(styles.Heading.Render("BODY") + "\n")
+	pretty := new(bytes.Buffer)
+	if json.Indent(pretty, body, "", "  ") == nil {
+		buf.WriteString(pretty.String())
+	} else {
+		buf.Write(body)
+	}
+	return buf.String()
+}
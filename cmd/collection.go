@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/suryanshu-09/hulaki/styles"
+	"github.com/suryanshu-09/hulaki/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// collectionCmd represents the collection command
+var collectionCmd = &cobra.Command{
+	Use:     "collection",
+	Aliases: []string{"col"},
+	Short:   "Manage collections of saved requests",
+	Long: `The 'collection' command manages collections of saved, named requests.
+A collection is a directory of YAML or JSON request files, one request per file.
+Collections are stored in the hulaki config directory (override with HULAKI_HOME),
+or can be referenced by path (e.g. ./api) to keep them next to your code.
+Saved requests are sent with 'hulaki run <collection>/<request>'.`,
+}
+
+var collectionAddCmd = &cobra.Command{
+	Use:   "add <collection>/<request>",
+	Short: "Save a request into a collection",
+	Long: `The 'add' command saves a named request into a collection, creating the collection if it does not exist.
+An existing request with the same name is replaced.`,
+	Example: `Examples:
+1. Save an HTTP request:
+   hulaki collection add users/list --protocol=http --method=GET --url=https://api.example.com/users --params=active=true
+
+2. Save a GraphQL query:
+   hulaki collection add users/get --protocol=graphql --url=https://api.example.com/graphql --query="query GetUser($id: ID!) { user(id: $id) { name } }" --variables='{"id":"1"}'
+
+3. Save a gRPC call:
+   hulaki collection add users/create --protocol=grpc --url=localhost:50051 --service=UserService --method=CreateUser --body='{"name":"John"}'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("please provide a request reference (e.g., users/list)")
+		}
+		collection, name, err := utils.SplitCollectionRef(args[0])
+		if err != nil {
+			return err
+		}
+
+		req, err := collectionIn(cmd)
+		if err != nil {
+			return err
+		}
+		req.Name = name
+
+		if err := utils.SaveCollectionRequest(collection, req); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s/%s\n", styles.Key.Render("Saved"), collection, name)
+		return nil
+	},
+}
+
+var collectionListCmd = &cobra.Command{
+	Use:     "list [collection]",
+	Aliases: []string{"ls"},
+	Short:   "List collections, or the requests in a collection",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		if len(args) == 0 {
+			collections, err := utils.ListCollections()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s\n", styles.Heading.Render("COLLECTIONS"))
+			for _, collection := range collections {
+				fmt.Fprintf(out, "%s\n", styles.Content.Render(collection))
+			}
+			return nil
+		}
+
+		requests, err := utils.ListCollectionRequests(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s\n", styles.Heading.Render(strings.ToUpper(args[0])))
+		for _, name := range requests {
+			req, err := utils.LoadCollectionRequest(args[0], name)
+			if err != nil {
+				fmt.Fprintf(out, "%s: %s\n", styles.Key.Render(name), err.Error())
+				continue
+			}
+			fmt.Fprintf(out, "%s: %s %s\n", styles.Key.Render(name), describeCollectionRequest(req), req.URL)
+		}
+		return nil
+	},
+}
+
+var collectionShowCmd = &cobra.Command{
+	Use:   "show <collection>/<request>",
+	Short: "Show a saved request",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("please provide a request reference (e.g., users/list)")
+		}
+		collection, name, err := utils.SplitCollectionRef(args[0])
+		if err != nil {
+			return err
+		}
+		req, err := utils.LoadCollectionRequest(collection, name)
+		if err != nil {
+			return err
+		}
+
+		asJSON, _ := cmd.Flags().GetBool("json")
+		var data []byte
+		if asJSON {
+			data, err = json.MarshalIndent(req, "", "  ")
+		} else {
+			data, err = yaml.Marshal(req)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\n", strings.TrimRight(string(data), "\n"))
+		return nil
+	},
+}
+
+var collectionRmCmd = &cobra.Command{
+	Use:     "rm <collection>[/<request>]",
+	Aliases: []string{"remove"},
+	Short:   "Remove a saved request, or a whole collection",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("please provide a collection or request reference")
+		}
+		out := cmd.OutOrStdout()
+		collection, name, err := utils.SplitCollectionRef(args[0])
+		if err != nil {
+			if err := utils.RemoveCollection(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s: %s\n", styles.Key.Render("Removed"), args[0])
+			return nil
+		}
+		if err := utils.RemoveCollectionRequest(collection, name); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s: %s/%s\n", styles.Key.Render("Removed"), collection, name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(collectionCmd)
+	collectionCmd.AddCommand(collectionAddCmd, collectionListCmd, collectionShowCmd, collectionRmCmd)
+
+	collectionAddCmd.Flags().String("protocol", utils.ProtocolHTTP, "Protocol of the request: http, graphql, grpc, ws or socketio")
+	collectionAddCmd.Flags().String("url", "", "URL or address the request is sent to (required)")
+	collectionAddCmd.Flags().StringP("method", "m", "", "HTTP verb, or gRPC method name")
+	collectionAddCmd.Flags().StringP("service", "s", "", "gRPC service name")
+	collectionAddCmd.Flags().String("event", "", "Socket.IO event to emit")
+	collectionAddCmd.Flags().String("namespace", "", "Socket.IO namespace to connect to")
+	collectionAddCmd.Flags().String("headers", "", "Custom headers for the request, formatted as key=value pairs separated by commas")
+	collectionAddCmd.Flags().StringP("params", "p", "", "Query parameters for the request, formatted as key=value pairs separated by commas")
+	collectionAddCmd.Flags().StringP("body", "b", "", "Raw request body, gRPC request JSON, Socket.IO event data or WebSocket message")
+	collectionAddCmd.Flags().StringP("query", "q", "", "GraphQL query or mutation string")
+	collectionAddCmd.Flags().String("variables", "", "GraphQL variables as JSON string")
+
+	collectionShowCmd.Flags().Bool("json", false, "Show the request as JSON instead of YAML")
+}
+
+func collectionIn(cmd *cobra.Command) (*utils.CollectionRequest, error) {
+	req := &utils.CollectionRequest{}
+	req.Protocol, _ = cmd.Flags().GetString("protocol")
+	req.URL, _ = cmd.Flags().GetString("url")
+	req.Method, _ = cmd.Flags().GetString("method")
+	req.Service, _ = cmd.Flags().GetString("service")
+	req.Event, _ = cmd.Flags().GetString("event")
+	req.Namespace, _ = cmd.Flags().GetString("namespace")
+	req.Body, _ = cmd.Flags().GetString("body")
+	req.Query, _ = cmd.Flags().GetString("query")
+
+	h, _ := cmd.Flags().GetString("headers")
+	if headers := parseKeyValuePairs(h); len(headers) > 0 {
+		req.Headers = headers
+	}
+	p, _ := cmd.Flags().GetString("params")
+	if params := parseKeyValuePairs(p); len(params) > 0 {
+		req.Params = params
+	}
+
+	varsStr, _ := cmd.Flags().GetString("variables")
+	if varsStr != "" {
+		if err := json.Unmarshal([]byte(varsStr), &req.Variables); err != nil {
+			return nil, fmt.Errorf("invalid variables JSON: %w", err)
+		}
+	}
+	return req, nil
+}
+
+func describeCollectionRequest(req *utils.CollectionRequest) string {
+	switch req.Protocol {
+	case utils.ProtocolHTTP:
+		return req.Method
+	case utils.ProtocolGRPC:
+		return fmt.Sprintf("grpc %s/%s", req.Service, req.Method)
+	default:
+		return req.Protocol
+	}
+}
+
+func parseKeyValuePairs(input string) map[string]string {
+	result := make(map[string]string)
+	if input == "" {
+		return result
+	}
+	for pair := range strings.SplitSeq(input, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 {
+			result[kv[0]] = kv[1]
+		}
+	}
+	return result
+}
@@ -30,7 +30,10 @@ This command is commonly used to delete resources on a server, such as removing
 		if err != nil {
 			return err
 		}
-		url := args[0]
+		url, err := interpolate(cmd, args[0])
+		if err != nil {
+			return err
+		}
 		resp, err := utils.HTTPDelete(url, utils.WithHeaders(headers), utils.WithParams(params), utils.WithBody(&iBody))
 		if err != nil {
 			return err
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/suryanshu-09/hulaki/styles"
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage environments of request variables",
+	Long: `The 'env' command manages named environments.
+An environment is a set of variables that are substituted wherever {{variable}} appears
+in a URL, header, query parameter or body. Select an environment with the global --env flag.`,
+	Example: `Examples:
+1. Create an environment and add variables to it:
+   hulaki env create staging
+   hulaki env set staging baseUrl=https://staging.example.com token=abc123
+
+2. Use the environment in a request:
+   hulaki http get {{baseUrl}}/users --headers=Authorization={{token}} --env staging`,
+}
+
+var envCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a new environment",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("please provide an environment name")
+		}
+		if _, err := utils.CreateEnvironment(args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", styles.Key.Render("Created"), args[0])
+		return nil
+	},
+}
+
+var envSetCmd = &cobra.Command{
+	Use:   "set <name> key=value...",
+	Short: "Set variables in an environment",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.New("please provide an environment name and at least one key=value pair")
+		}
+		env, err := utils.LoadEnvironment(args[0])
+		if err != nil {
+			return err
+		}
+		for _, pair := range args[1:] {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return fmt.Errorf("invalid variable %q, expected key=value", pair)
+			}
+			env.Variables[kv[0]] = kv[1]
+		}
+		return utils.SaveEnvironment(env)
+	},
+}
+
+var envUnsetCmd = &cobra.Command{
+	Use:   "unset <name> key...",
+	Short: "Remove variables from an environment",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.New("please provide an environment name and at least one variable")
+		}
+		env, err := utils.LoadEnvironment(args[0])
+		if err != nil {
+			return err
+		}
+		for _, key := range args[1:] {
+			delete(env.Variables, key)
+		}
+		return utils.SaveEnvironment(env)
+	},
+}
+
+var envListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List environments",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		envs, err := utils.ListEnvironments()
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "%s\n", styles.Heading.Render("ENVIRONMENTS"))
+		for _, env := range envs {
+			fmt.Fprintf(out, "%s\n", styles.Content.Render(env))
+		}
+		return nil
+	},
+}
+
+var envShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show the variables of an environment",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("please provide an environment name")
+		}
+		env, err := utils.LoadEnvironment(args[0])
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "%s\n", styles.Heading.Render(strings.ToUpper(env.Name)))
+		keys := make([]string, 0, len(env.Variables))
+		for key := range env.Variables {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			fmt.Fprintf(out, "%s: %s\n", styles.Key.Render(key), env.Variables[key])
+		}
+		return nil
+	},
+}
+
+var envRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Remove an environment",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("please provide an environment name")
+		}
+		if err := utils.RemoveEnvironment(args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", styles.Key.Render("Removed"), args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.AddCommand(envCreateCmd, envSetCmd, envUnsetCmd, envListCmd, envShowCmd, envRmCmd)
+}
+
+// activeEnvironment loads the environment selected with --env, or returns
+// nil when none is selected.
+func activeEnvironment(cmd *cobra.Command) (*utils.Environment, error) {
+	name, _ := cmd.Flags().GetString("env")
+	if name == "" {
+		return nil, nil
+	}
+	return utils.LoadEnvironment(name)
+}
+
+func interpolate(cmd *cobra.Command, s string) (string, error) {
+	env, err := activeEnvironment(cmd)
+	if err != nil {
+		return "", err
+	}
+	return env.Interpolate(s)
+}
+
+func interpolateMap(cmd *cobra.Command, m map[string]string) (map[string]string, error) {
+	env, err := activeEnvironment(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return env.InterpolateMap(m)
+}
+
+// interpolateBody substitutes the active environment into a request body.
+// Unlike URLs, headers and params, bodies are sent as written when no
+// environment is selected, so text that only looks like a variable (a
+// mustache template, say) needs no --env.
+func interpolateBody(cmd *cobra.Command, s string) (string, error) {
+	env, err := activeEnvironment(cmd)
+	if err != nil || env == nil {
+		return s, err
+	}
+	return env.Interpolate(s)
+}
+
+// interpolateBodyMap is interpolateBody for bodies given as key=value pairs.
+func interpolateBodyMap(cmd *cobra.Command, m map[string]string) (map[string]string, error) {
+	env, err := activeEnvironment(cmd)
+	if err != nil || env == nil {
+		return m, err
+	}
+	return env.InterpolateMap(m)
+}
+
+// interpolateBodyValues is interpolateBody for bodies decoded from JSON,
+// such as GraphQL variables and Socket.IO data.
+func interpolateBodyValues(cmd *cobra.Command, m map[string]any) (map[string]any, error) {
+	env, err := activeEnvironment(cmd)
+	if err != nil || env == nil {
+		return m, err
+	}
+	v, err := env.InterpolateValue(m)
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]any), nil
+}
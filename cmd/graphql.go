@@ -6,8 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -21,7 +25,31 @@ var graphqlCmd = &cobra.Command{
 	Short: "Make a GraphQL request",
 	Long: `The 'graphql' command sends GraphQL queries and mutations to a specified endpoint.
 GraphQL is a query language for APIs that allows you to request exactly the data you need.
-You can include variables and headers to customize the request.`,
+You can include variables and headers to customize the request.
+The document is parsed to route the operation: queries and mutations are sent with POST,
+queries with GET when --get is given, and subscriptions over a streaming transport. Required
+variables that are not given are reported before anything is sent.
+With --schema, queries are validated against a saved schema before they are sent, so
+typos are reported with their line and column without a round trip. --validate fetches
+the schema by introspecting the endpoint instead, at the cost of an extra request. Use
+--no-validate to send documents that do not parse, or lack required variables.
+Subscriptions are run over WebSocket, or over Server-Sent Events with --sse, and every
+event is printed as it arrives until the server completes the subscription or you press
+Ctrl-C. Streamed responses, such as the incremental payloads of @defer and @stream, are
+printed chunk by chunk and then merged into the DATA section. With --less the chunks are
+printed to stderr, so stdout holds only the merged data.
+Errors are shown with the line of the query they point at, the path of the field they
+concern along with what data holds there, and their extensions such as code. Servers
+report errors with HTTP 200, so use --fail-on-errors for a non-zero exit status.
+Operations can be kept in .graphql files and run with --file; when a file holds several
+operations, --operation picks the one to run. Fragments the operations spread are taken
+from the same file, or from the .graphql files under --fragments. With --batch every
+operation of the document is sent in one request and each result is shown on its own.
+Servers requiring automatic persisted queries are supported with --persisted, which sends
+the hash of the query first and the query itself only when the server asks for it.
+Files are uploaded with --file variables.path=@file, which sends the operation as
+multipart/form-data as the GraphQL multipart request spec describes; the variable holding
+each file is set to null. Values of --file without "=@" name the document instead.`,
 	Example: `Examples:
 1. Perform a basic GraphQL query:
    hulaki graphql https://api.example.com/graphql --query="{ users { name email } }"
@@ -33,47 +61,498 @@ You can include variables and headers to customize the request.`,
    hulaki graphql https://api.example.com/graphql --query="mutation CreateUser($input: UserInput!) { createUser(input: $input) { id } }" --variables='{"input":{"name":"John"}}'
 
 4. Perform a GraphQL request with custom headers:
-   hulaki graphql https://api.example.com/graphql --query="{ users { name } }" --headers=Authorization=Bearer token`,
+   hulaki graphql https://api.example.com/graphql --query="{ users { name } }" --headers=Authorization=Bearer token
+
+5. Print the schema of an endpoint as SDL:
+   hulaki graphql https://api.example.com/graphql --introspect
+
+6. Save the schema, or print the raw introspection result:
+   hulaki graphql https://api.example.com/graphql --introspect --save schema.graphql
+   hulaki graphql https://api.example.com/graphql --introspect --json
+
+7. Validate against a saved schema, or against the schema of the endpoint:
+   hulaki graphql https://api.example.com/graphql --query="{ users { name } }" --schema schema.graphql
+   hulaki graphql https://api.example.com/graphql --query="{ users { name } }" --validate
+
+8. Stream a subscription, authenticating in the connection_init payload:
+   hulaki graphql wss://api.example.com/graphql --query="subscription { userCreated { name } }" --init-payload='{"token":"abc"}'
+
+9. Stream a subscription over Server-Sent Events:
+   hulaki graphql https://api.example.com/graphql --query="subscription { userCreated { name } }" --sse
+
+10. Receive deferred fields as they resolve:
+   hulaki graphql https://api.example.com/graphql --query="{ user(id: 1) { name ... @defer { friends { name } } } }"
+
+11. Run one of the operations of a file, with fragments shared across files:
+   hulaki graphql https://api.example.com/graphql --file ops.graphql --operation GetUser --fragments graphql/fragments --variables='{"id":"123"}'
+
+12. Read the operation from stdin:
+   cat ops.graphql | hulaki graphql https://api.example.com/graphql --file -
+
+13. Send a persisted query, by hash over GET so a CDN can cache it:
+   hulaki graphql https://api.example.com/graphql --query="{ users { name } }" --persisted --get
+
+14. Send every operation of a file in one batch:
+   hulaki graphql https://api.example.com/graphql --file ops.graphql --batch
+
+15. Upload files, one for a single variable and two filling a list:
+   hulaki graphql https://api.example.com/graphql --query="mutation($photo: Upload!, $docs: [Upload!]!) { upload(photo: $photo, docs: $docs) }" --file variables.photo=@./photo.png --file variables.docs=@./a.pdf --file variables.docs=@./b.pdf
+
+16. Fail a script when the response has errors, even though the status is 200:
+   hulaki graphql https://api.example.com/graphql --file ops.graphql --operation GetUser --fail-on-errors`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
 			return errors.New("please provide a GraphQL endpoint URL")
 		}
 
-		url := args[0]
-		query, err := cmd.Flags().GetString("query")
-		if err != nil || query == "" {
-			return errors.New("please provide a GraphQL query using --query flag")
+		url, err := interpolate(cmd, args[0])
+		if err != nil {
+			return err
 		}
+		if introspect, _ := cmd.Flags().GetBool("introspect"); introspect {
+			return graphQLIntrospect(cmd, url)
+		}
+		query, err := graphQLDocument(cmd)
+		if err != nil {
+			return err
+		}
+		operation, _ := cmd.Flags().GetString("operation")
 
 		variables, params, headers, err := graphQLIn(cmd)
 		if err != nil {
 			return err
 		}
 
+		uploads, err := graphQLUploads(cmd)
+		if err != nil {
+			return err
+		}
+
+		if batch, _ := cmd.Flags().GetBool("batch"); batch {
+			if len(uploads) > 0 {
+				return errors.New("files cannot be uploaded with --batch")
+			}
+			return graphQLBatch(cmd, url, query, variables, params, headers)
+		}
+
+		// The type of the operation decides how it is sent. Documents that
+		// do not parse are only sent with --no-validate, as queries.
+		noValidate, _ := cmd.Flags().GetBool("no-validate")
+		kind := "query"
+		op, err := utils.ParseGraphQLOperation(query, operation)
+		switch {
+		case err == nil:
+			kind = op.Type
+		case !noValidate:
+			return invalidQuery([]string{err.Error()})
+		}
+
+		if !noValidate {
+			checked := variables
+			if len(uploads) > 0 {
+				if checked, err = utils.GraphQLUploadVariables(variables, uploads); err != nil {
+					return err
+				}
+			}
+			if err := graphQLCheckVariables(op, checked); err != nil {
+				return err
+			}
+			if err := graphQLValidate(cmd, url, query, []string{operation}, checked, params, headers); err != nil {
+				return err
+			}
+		}
+
+		sse, _ := cmd.Flags().GetBool("sse")
+		if len(uploads) > 0 && (sse || kind == "subscription") {
+			return errors.New("files can only be uploaded with queries and mutations sent over HTTP")
+		}
+		if kind == "subscription" && !sse {
+			return graphQLSubscribe(cmd, url, query, operation, variables, params, headers)
+		}
+
+		reqArgs := []utils.Args{utils.WithVariables(variables), utils.WithHeaders(headers), utils.WithParams(params), utils.WithOperationName(operation), utils.WithUploads(uploads...)}
+		if get, _ := cmd.Flags().GetBool("get"); get {
+			reqArgs = append(reqArgs, utils.WithGET())
+		}
+		if persisted, _ := cmd.Flags().GetBool("persisted"); persisted {
+			reqArgs = append(reqArgs, utils.WithPersistedQuery())
+		}
 		var resp *http.Response
-		if isMutation(query) {
-			resp, err = utils.GraphQLMutation(url, query, utils.WithVariables(variables), utils.WithHeaders(headers), utils.WithParams(params))
-		} else {
-			resp, err = utils.GraphQLQuery(url, query, utils.WithVariables(variables), utils.WithHeaders(headers), utils.WithParams(params))
+		switch {
+		case sse:
+			resp, err = utils.GraphQLSSE(url, query, reqArgs...)
+		case kind == "mutation":
+			resp, err = utils.GraphQLMutation(url, query, reqArgs...)
+		default:
+			resp, err = utils.GraphQLQuery(url, query, reqArgs...)
 		}
 
 		if err != nil {
 			return err
 		}
 
-		return graphQLOut(cmd, resp)
+		return graphQLOut(cmd, resp, query)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(graphqlCmd)
 
-	graphqlCmd.Flags().StringP("query", "q", "", "GraphQL query or mutation string (required)")
+	graphqlCmd.Flags().StringP("query", "q", "", "GraphQL query or mutation string")
+	graphqlCmd.Flags().StringArrayP("file", "f", nil, "File to read the GraphQL document from, or - for stdin, instead of --query; or a file to upload as variables.path=@file (repeatable; repeating a path uploads a list)")
+	graphqlCmd.Flags().String("operation", "", "Name of the operation to run when the document holds several")
+	graphqlCmd.Flags().String("fragments", "", "Directory of .graphql files defining fragments the operation may spread")
+	graphqlCmd.Flags().Bool("batch", false, "Send every operation of the document in one batched request and show each result")
+	graphqlCmd.Flags().Bool("persisted", false, "Send the query as an automatic persisted query, by its sha256 hash first")
+	graphqlCmd.Flags().Bool("get", false, "Send queries with GET so a CDN can cache the response, by hash with --persisted; mutations are always sent with POST")
 	graphqlCmd.Flags().String("variables", "", "GraphQL variables as JSON string")
 	graphqlCmd.Flags().String("headers", "", "Custom headers for the GraphQL request, formatted as key=value pairs separated by commas")
 	graphqlCmd.Flags().StringP("params", "p", "", "Query parameters for the GraphQL request, formatted as key=value pairs separated by commas")
 	graphqlCmd.Flags().BoolP("less", "l", false, "Show only the response data, omitting headers and formatted output")
 	graphqlCmd.Flags().Bool("raw", false, "Show raw JSON response without parsing GraphQL structure")
+	graphqlCmd.Flags().Bool("fail-on-errors", false, "Exit with a non-zero status when the response has GraphQL errors, even with HTTP 200")
+	graphqlCmd.Flags().Bool("introspect", false, "Fetch the schema of the endpoint and print it as SDL")
+	graphqlCmd.Flags().Bool("json", false, "With --introspect, print the raw introspection JSON instead of SDL")
+	graphqlCmd.Flags().String("save", "", "With --introspect, write the schema to this file instead of printing it")
+	graphqlCmd.Flags().String("schema", "", "Schema to validate the query against, as SDL or introspection JSON")
+	graphqlCmd.Flags().Bool("validate", false, "Validate the query against the schema of the endpoint, fetched by introspection before it is sent")
+	graphqlCmd.Flags().Bool("no-validate", false, "Send the query without parsing or validating it first")
+	graphqlCmd.MarkFlagsMutuallyExclusive("validate", "no-validate")
+	graphqlCmd.Flags().String("subprotocol", "", "WebSocket subprotocol for subscriptions: graphql-transport-ws or graphql-ws (defaults to the one the server picks)")
+	graphqlCmd.Flags().Bool("sse", false, "Send the request over Server-Sent Events (graphql-sse), e.g. to run a subscription without WebSocket")
+	graphqlCmd.Flags().String("init-payload", "", "Payload of the connection_init message of subscriptions, as a JSON object")
+}
+
+// graphQLSubscribe streams the events of a subscription until the server
+// completes it or the user interrupts.
+func graphQLSubscribe(cmd *cobra.Command, url, query, operation string, variables map[string]any, params, headers map[string]string) error {
+	subArgs := []utils.Args{utils.WithVariables(variables), utils.WithHeaders(headers), utils.WithParams(params), utils.WithOperationName(operation)}
+	if protocol, _ := cmd.Flags().GetString("subprotocol"); protocol != "" {
+		if !utils.ValidGraphQLSubprotocol(protocol) {
+			return fmt.Errorf("unknown subprotocol %q, expected %s or %s", protocol, utils.GraphQLTransportWS, utils.GraphQLWS)
+		}
+		subArgs = append(subArgs, utils.WithGraphQLSubprotocol(protocol))
+	}
+	if p, _ := cmd.Flags().GetString("init-payload"); p != "" {
+		payload := map[string]any{}
+		if err := json.Unmarshal([]byte(p), &payload); err != nil {
+			return fmt.Errorf("invalid init payload JSON: %w", err)
+		}
+		payload, err := interpolateBodyValues(cmd, payload)
+		if err != nil {
+			return err
+		}
+		subArgs = append(subArgs, utils.WithConnectionPayload(payload))
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	less, _ := cmd.Flags().GetBool("less")
+	out := cmd.OutOrStdout()
+	errorCount := 0
+	err := utils.GraphQLSubscribe(ctx, url, query, func(event *utils.GraphQLResponse) {
+		if !less {
+			fmt.Fprintf(out, "%s\n", styles.Heading.Render("EVENT"))
+		}
+		for _, gqlErr := range event.Errors {
+			if less {
+				fmt.Fprintf(out, "Error: %s\n", gqlErr.Message)
+			} else {
+				graphQLErrorOut(out, gqlErr, event.Data, query)
+			}
+		}
+		errorCount += len(event.Errors)
+		if event.Data != nil {
+			dataJSON, _ := json.MarshalIndent(event.Data, "", "  ")
+			fmt.Fprintf(out, "%s\n", styles.Content.Render(string(dataJSON)))
+		}
+	}, subArgs...)
+	if err != nil {
+		return err
+	}
+	return graphQLFailOnErrors(cmd, errorCount)
+}
+
+// graphQLFiles splits the values of --file into the file holding the
+// document and the files to upload, written as path=@file.
+func graphQLFiles(cmd *cobra.Command) (document string, uploads []string, err error) {
+	values, _ := cmd.Flags().GetStringArray("file")
+	for _, value := range values {
+		switch {
+		case strings.Contains(value, "=@"):
+			uploads = append(uploads, value)
+		case document != "":
+			return "", nil, errors.New("only one --file can hold the document; files to upload are given as variables.path=@file")
+		default:
+			document = value
+		}
+	}
+	return document, uploads, nil
+}
+
+// graphQLUploads reads the files to upload from --file.
+func graphQLUploads(cmd *cobra.Command) ([]utils.GraphQLUpload, error) {
+	_, values, err := graphQLFiles(cmd)
+	if err != nil {
+		return nil, err
+	}
+	uploads := make([]utils.GraphQLUpload, 0, len(values))
+	for _, value := range values {
+		value, err := interpolate(cmd, value)
+		if err != nil {
+			return nil, err
+		}
+		upload, err := utils.ParseGraphQLUpload(value)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
+// graphQLBatch sends every operation of the document in one request and
+// prints the result of each.
+func graphQLBatch(cmd *cobra.Command, url, query string, variables map[string]any, params, headers map[string]string) error {
+	persisted, _ := cmd.Flags().GetBool("persisted")
+	get, _ := cmd.Flags().GetBool("get")
+	sse, _ := cmd.Flags().GetBool("sse")
+	if persisted || get || sse {
+		return errors.New("--batch cannot be combined with --persisted, --get or --sse")
+	}
+
+	requests, err := utils.SplitGraphQLDocument(query)
+	if err != nil {
+		return err
+	}
+	noValidate, _ := cmd.Flags().GetBool("no-validate")
+	operations := make([]string, 0, len(requests))
+	for _, req := range requests {
+		op, err := utils.ParseGraphQLOperation(req.Query, req.OperationName)
+		if err != nil {
+			return err
+		}
+		if op.Type == "subscription" {
+			return fmt.Errorf("subscription %q cannot be batched", req.OperationName)
+		}
+		if !noValidate {
+			if err := graphQLCheckVariables(op, variables); err != nil {
+				return err
+			}
+		}
+		operations = append(operations, req.OperationName)
+	}
+	if !noValidate {
+		if err := graphQLValidate(cmd, url, query, operations, variables, params, headers); err != nil {
+			return err
+		}
+	}
+
+	resp, err := utils.GraphQLBatch(url, requests, utils.WithVariables(variables), utils.WithHeaders(headers), utils.WithParams(params))
+	if err != nil {
+		return err
+	}
+	if raw, _ := cmd.Flags().GetBool("raw"); raw {
+		return graphQLOut(cmd, resp, query)
+	}
+
+	less, _ := cmd.Flags().GetBool("less")
+	out := cmd.OutOrStdout()
+	if !less {
+		fmt.Fprintf(out, "%s\n", styles.Heading.Render("HEADERS"))
+		for key, values := range resp.Header {
+			for _, value := range values {
+				fmt.Fprintf(out, "%s: %s\n", styles.Key.Render(key), value)
+			}
+		}
+	}
+	results, err := utils.ParseGraphQLBatchResponse(resp)
+	if err != nil {
+		return err
+	}
+	errorCount := 0
+	for i, result := range results {
+		if !less {
+			heading := fmt.Sprintf("RESULT %d", i+1)
+			if i < len(operations) && operations[i] != "" {
+				heading += ": " + operations[i]
+			}
+			fmt.Fprintf(out, "%s\n", styles.Heading.Render(heading))
+		}
+		sent := ""
+		if i < len(requests) {
+			sent = requests[i].Query
+		}
+		if err := graphQLResult(out, &result, sent, less); err != nil {
+			return err
+		}
+		errorCount += len(result.Errors)
+	}
+	return graphQLFailOnErrors(cmd, errorCount)
+}
+
+// graphQLValidate checks the query and variables against the schema before
+// anything is sent, for each of the operations to run. The schema is the one
+// given with --schema, or with --validate the endpoint's, introspected;
+// without either nothing is checked, so no request is sent behind the
+// user's back.
+func graphQLValidate(cmd *cobra.Command, url, query string, operations []string, variables map[string]any, params, headers map[string]string) error {
+	var schema *utils.GraphQLSchema
+	path, _ := cmd.Flags().GetString("schema")
+	validate, _ := cmd.Flags().GetBool("validate")
+	switch {
+	case path != "":
+		var err error
+		if schema, err = utils.LoadGraphQLSchema(path); err != nil {
+			return err
+		}
+	case validate:
+		data, err := utils.GraphQLIntrospect(url, utils.WithHeaders(headers), utils.WithParams(params))
+		if err != nil {
+			return fmt.Errorf("failed to introspect the schema to validate against (use --schema or --no-validate): %w", err)
+		}
+		if schema, err = utils.ParseIntrospection(data); err != nil {
+			return fmt.Errorf("failed to introspect the schema to validate against (use --schema or --no-validate): %w", err)
+		}
+	default:
+		return nil
+	}
+
+	var messages []string
+	seen := map[string]bool{}
+	for _, operation := range operations {
+		// Problems outside the operations are found for each of them.
+		for _, problem := range utils.ValidateGraphQL(schema, query, operation, variables) {
+			if message := problem.Error(); !seen[message] {
+				seen[message] = true
+				messages = append(messages, message)
+			}
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return invalidQuery(messages)
+}
+
+// graphQLCheckVariables reports the required variables of op that were not
+// given. Unlike graphQLValidate it needs no schema.
+func graphQLCheckVariables(op *utils.GraphQLOperation, variables map[string]any) error {
+	missing := op.MissingVariables(variables)
+	if len(missing) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(missing))
+	for _, problem := range missing {
+		messages = append(messages, problem.Error())
+	}
+	return invalidQuery(messages)
+}
+
+func invalidQuery(messages []string) error {
+	return fmt.Errorf("the query is invalid and was not sent (use --no-validate to send it anyway):\n%s", strings.Join(messages, "\n"))
+}
+
+// graphQLDocument returns the document to send, from --query or --file. When
+// an operation is picked, fragments are shared or operations are batched, the
+// fragments the document spreads but does not define are appended from
+// --fragments.
+func graphQLDocument(cmd *cobra.Command) (string, error) {
+	query, _ := cmd.Flags().GetString("query")
+	path, _, err := graphQLFiles(cmd)
+	if err != nil {
+		return "", err
+	}
+	source := "the query"
+	switch {
+	case query != "" && path != "":
+		return "", errors.New("please provide the GraphQL query using either --query or --file, not both")
+	case path == "-":
+		if vars, _ := cmd.Flags().GetString("variables"); vars == "-" {
+			return "", errors.New("stdin cannot hold both the document and the variables")
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the document from stdin: %w", err)
+		}
+		query, source = string(data), "stdin"
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the document: %w", err)
+		}
+		query, source = string(data), path
+	}
+	if strings.TrimSpace(query) == "" {
+		return "", errors.New("please provide a GraphQL query using --query or --file flag")
+	}
+
+	operation, _ := cmd.Flags().GetString("operation")
+	dir, _ := cmd.Flags().GetString("fragments")
+	batch, _ := cmd.Flags().GetBool("batch")
+	if batch && operation != "" {
+		return "", errors.New("--batch runs every operation of the document and cannot be combined with --operation")
+	}
+	if path == "" && operation == "" && dir == "" && !batch {
+		return query, nil
+	}
+	var fragments map[string]string
+	if dir != "" {
+		if fragments, err = utils.LoadGraphQLFragments(dir); err != nil {
+			return "", fmt.Errorf("failed to load fragments: %w", err)
+		}
+	}
+	var document string
+	if batch {
+		document, err = utils.AppendGraphQLFragments(query, fragments)
+	} else {
+		document, err = utils.PrepareGraphQLDocument(query, operation, fragments)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", source, err)
+	}
+	return document, nil
+}
+
+// graphQLIntrospect fetches the schema of the endpoint at url and prints or
+// saves it as SDL, or as the raw introspection JSON with --json.
+func graphQLIntrospect(cmd *cobra.Command, url string) error {
+	_, params, headers, err := graphQLIn(cmd)
+	if err != nil {
+		return err
+	}
+	data, err := utils.GraphQLIntrospect(url, utils.WithHeaders(headers), utils.WithParams(params))
+	if err != nil {
+		return err
+	}
+
+	var schema []byte
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		indented := bytes.Buffer{}
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			return fmt.Errorf("failed to format introspection result: %w", err)
+		}
+		indented.WriteByte('\n')
+		schema = indented.Bytes()
+	} else {
+		parsed, err := utils.ParseIntrospection(data)
+		if err != nil {
+			return err
+		}
+		schema = []byte(parsed.SDL())
+	}
+
+	out := cmd.OutOrStdout()
+	if path, _ := cmd.Flags().GetString("save"); path != "" {
+		if err := os.WriteFile(path, schema, 0o644); err != nil {
+			return fmt.Errorf("failed to save schema: %w", err)
+		}
+		fmt.Fprintf(out, "%s: %s\n", styles.Key.Render("Schema saved to"), path)
+		return nil
+	}
+	out.Write(schema)
+	return nil
 }
 
 func graphQLIn(cmd *cobra.Command) (variables map[string]any, params map[string]string, headers map[string]string, err error) {
@@ -128,10 +607,22 @@ func graphQLIn(cmd *cobra.Command) (variables map[string]any, params map[string]
 		}
 	}
 
+	if variables, err = interpolateBodyValues(cmd, variables); err != nil {
+		return nil, nil, nil, err
+	}
+	if params, err = interpolateMap(cmd, params); err != nil {
+		return nil, nil, nil, err
+	}
+	if headers, err = interpolateMap(cmd, headers); err != nil {
+		return nil, nil, nil, err
+	}
+
 	return variables, params, headers, nil
 }
 
-func graphQLOut(cmd *cobra.Command, resp *http.Response) error {
+// graphQLOut prints a GraphQL response. query is the document that was
+// sent, which error locations point into.
+func graphQLOut(cmd *cobra.Command, resp *http.Response, query string) error {
 	defer resp.Body.Close()
 
 	raw, _ := cmd.Flags().GetBool("raw")
@@ -157,15 +648,21 @@ func graphQLOut(cmd *cobra.Command, resp *http.Response) error {
 		}
 
 		fmt.Fprintf(out, "%s\n", styles.Content.Render(body.String()))
+		var gqlResp utils.GraphQLResponse
+		if json.Unmarshal(body.Bytes(), &gqlResp) == nil {
+			return graphQLFailOnErrors(cmd, len(gqlResp.Errors))
+		}
+		var batch []utils.GraphQLResponse
+		if json.Unmarshal(body.Bytes(), &batch) == nil {
+			count := 0
+			for _, result := range batch {
+				count += len(result.Errors)
+			}
+			return graphQLFailOnErrors(cmd, count)
+		}
 		return nil
 	}
 
-	// Parse GraphQL response
-	gqlResp, err := utils.ParseGraphQLResponse(resp)
-	if err != nil {
-		return fmt.Errorf("failed to parse GraphQL response: %w", err)
-	}
-
 	if !less {
 		fmt.Fprintf(out, "%s\n", styles.Heading.Render("HEADERS"))
 		for key, values := range resp.Header {
@@ -173,21 +670,93 @@ func graphQLOut(cmd *cobra.Command, resp *http.Response) error {
 				fmt.Fprintf(out, "%s: %s\n", styles.Key.Render(key), value)
 			}
 		}
+	}
+
+	// Parse GraphQL response, printing the chunks of streamed ones as they
+	// arrive
+	gqlResp, err := utils.ReadGraphQLStream(resp, func(chunk json.RawMessage) {
+		if less {
+			// Chunks go to stderr so stdout holds only the merged data
+			graphQLChunkData(cmd.ErrOrStderr(), chunk)
+			return
+		}
+		indented := bytes.Buffer{}
+		if json.Indent(&indented, chunk, "", "  ") != nil {
+			indented.Write(chunk)
+		}
+		fmt.Fprintf(out, "%s\n", styles.Heading.Render("CHUNK"))
+		fmt.Fprintf(out, "%s\n", styles.Content.Render(indented.String()))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to parse GraphQL response: %w", err)
+	}
+	if err := graphQLResult(out, gqlResp, query, less); err != nil {
+		return err
+	}
+	return graphQLFailOnErrors(cmd, len(gqlResp.Errors))
+}
 
+// graphQLChunkData prints the errors and data of one chunk of a streamed
+// response, the way subscription events are printed with --less. Chunks of
+// incremental responses carry their data in incremental results.
+func graphQLChunkData(out io.Writer, chunk json.RawMessage) {
+	type result struct {
+		Data   any                  `json:"data"`
+		Items  []any                `json:"items"`
+		Errors []utils.GraphQLError `json:"errors"`
+	}
+	var payload struct {
+		result
+		Incremental []result `json:"incremental"`
+	}
+	if json.Unmarshal(chunk, &payload) != nil {
+		fmt.Fprintf(out, "%s\n", styles.Content.Render(string(chunk)))
+		return
+	}
+	for _, r := range append([]result{payload.result}, payload.Incremental...) {
+		for _, gqlErr := range r.Errors {
+			fmt.Fprintf(out, "Error: %s\n", gqlErr.Message)
+		}
+		var data any
+		switch {
+		case r.Data != nil:
+			data = r.Data
+		case r.Items != nil:
+			data = r.Items
+		default:
+			continue
+		}
+		dataJSON, _ := json.MarshalIndent(data, "", "  ")
+		fmt.Fprintf(out, "%s\n", styles.Content.Render(string(dataJSON)))
+	}
+}
+
+// graphQLFailOnErrors turns GraphQL errors into a failure with
+// --fail-on-errors, as servers report them with a 200 status.
+func graphQLFailOnErrors(cmd *cobra.Command, count int) error {
+	if fail, _ := cmd.Flags().GetBool("fail-on-errors"); fail && count > 0 {
+		return fmt.Errorf("the response has %d GraphQL error(s)", count)
+	}
+	return nil
+}
+
+// graphQLResult prints the errors and data of a GraphQL result. Data that
+// came back along with errors is marked as partial.
+func graphQLResult(out io.Writer, gqlResp *utils.GraphQLResponse, query string, less bool) error {
+	if !less {
 		// Show GraphQL errors if any
 		if len(gqlResp.Errors) > 0 {
 			fmt.Fprintf(out, "%s\n", styles.Heading.Render("ERRORS"))
 			for _, gqlErr := range gqlResp.Errors {
-				fmt.Fprintf(out, "%s\n", styles.Content.Render(gqlErr.Message))
-				if len(gqlErr.Locations) > 0 {
-					for _, loc := range gqlErr.Locations {
-						fmt.Fprintf(out, "  Location: Line %d, Column %d\n", loc.Line, loc.Column)
-					}
-				}
+				graphQLErrorOut(out, gqlErr, gqlResp.Data, query)
 			}
 		}
 
-		fmt.Fprintf(out, "%s\n", styles.Heading.Render("DATA"))
+		if gqlResp.Data != nil && len(gqlResp.Errors) > 0 {
+			fmt.Fprintf(out, "%s\n", styles.Heading.Render("DATA (PARTIAL)"))
+		} else {
+			fmt.Fprintf(out, "%s\n", styles.Heading.Render("DATA"))
+		}
 	}
 
 	// Format and display data
@@ -207,7 +776,55 @@ func graphQLOut(cmd *cobra.Command, resp *http.Response) error {
 	return nil
 }
 
-func isMutation(query string) bool {
-	trimmed := strings.TrimSpace(strings.ToLower(query))
-	return strings.HasPrefix(trimmed, "mutation")
+// graphQLErrorOut prints an error with everything the server said about it:
+// where it is in the query, the path of the field in data and what data
+// holds there, and its extensions, such as code and classification.
+func graphQLErrorOut(out io.Writer, gqlErr utils.GraphQLError, data any, query string) {
+	fmt.Fprintf(out, "%s\n", styles.Content.Render(gqlErr.Message))
+	for _, loc := range gqlErr.Locations {
+		fmt.Fprintf(out, "  Location: Line %d, Column %d\n", loc.Line, loc.Column)
+		if excerpt := graphQLSourceExcerpt(query, loc); excerpt != "" {
+			fmt.Fprint(out, excerpt)
+		}
+	}
+	if len(gqlErr.Path) > 0 {
+		path := utils.FormatGraphQLPath(gqlErr.Path)
+		if value, ok := utils.GraphQLDataAt(data, gqlErr.Path); ok {
+			valueJSON, _ := json.Marshal(value)
+			fmt.Fprintf(out, "  Path: %s = %s\n", path, valueJSON)
+		} else {
+			fmt.Fprintf(out, "  Path: %s\n", path)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(gqlErr.Extensions)) {
+		value, ok := gqlErr.Extensions[key].(string)
+		if !ok {
+			valueJSON, _ := json.Marshal(gqlErr.Extensions[key])
+			value = string(valueJSON)
+		}
+		fmt.Fprintf(out, "  %s: %s\n", key, value)
+	}
+}
+
+// graphQLSourceExcerpt returns the line of query a location points at, with
+// a caret under its column, or "" if the location is outside the query.
+func graphQLSourceExcerpt(query string, loc utils.GraphQLErrorLocation) string {
+	lines := strings.Split(strings.ReplaceAll(query, "\r\n", "\n"), "\n")
+	if loc.Line < 1 || loc.Line > len(lines) || loc.Column < 1 {
+		return ""
+	}
+	line := []rune(lines[loc.Line-1])
+	if loc.Column > len(line)+1 {
+		return ""
+	}
+	// Keep the tabs before the column so the caret lines up.
+	padding := []rune(strings.Repeat(" ", loc.Column-1))
+	for i, r := range line[:loc.Column-1] {
+		if r == '\t' {
+			padding[i] = '\t'
+		}
+	}
+	number := strconv.Itoa(loc.Line)
+	gutter := strings.Repeat(" ", len(number))
+	return fmt.Sprintf("    %s | %s\n    %s | %s^\n", number, string(line), gutter, string(padding))
 }
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/suryanshu-09/hulaki/styles"
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// graphqlDiffCmd represents the graphql diff command
+var graphqlDiffCmd = &cobra.Command{
+	Use:   "diff <old schema> <new schema>",
+	Short: "Compare two GraphQL schemas and find breaking changes",
+	Long: `The 'diff' command compares two versions of a GraphQL schema and lists the types,
+fields, arguments, enum values and directives that were added, removed or changed.
+Each schema is an endpoint URL, which is introspected, or a file holding SDL or an
+introspection result.
+Every change is classified as BREAKING when it makes valid operations fail, DANGEROUS
+when operations stay valid but may behave differently, or SAFE. The command exits with a
+non-zero status when there are breaking changes, so it can gate deployments in CI.`,
+	Example: `Examples:
+1. Compare the deployed schema with the one about to be deployed:
+   hulaki graphql diff https://api.example.com/graphql schema.graphql
+
+2. Compare two endpoints, authenticating against both:
+   hulaki graphql diff https://api.example.com/graphql https://staging.example.com/graphql --headers=Authorization=Bearer token
+
+3. Print the changes as JSON for other tools:
+   hulaki graphql diff old.graphql new.graphql --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.New("please provide the old and the new schema, as endpoint URLs or files")
+		}
+
+		oldSchema, err := graphQLDiffSchema(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		newSchema, err := graphQLDiffSchema(cmd, args[1])
+		if err != nil {
+			return err
+		}
+		changes := utils.DiffGraphQLSchemas(oldSchema, newSchema)
+
+		out := cmd.OutOrStdout()
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if changes == nil {
+				changes = []utils.GraphQLSchemaChange{}
+			}
+			changesJSON, err := json.MarshalIndent(changes, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to format changes: %w", err)
+			}
+			fmt.Fprintf(out, "%s\n", changesJSON)
+		} else {
+			graphQLDiffOut(cmd, changes)
+		}
+
+		breaking := 0
+		for _, change := range changes {
+			if change.Criticality == utils.ChangeBreaking {
+				breaking++
+			}
+		}
+		if breaking > 0 {
+			return fmt.Errorf("found %d breaking change(s)", breaking)
+		}
+		return nil
+	},
+}
+
+func init() {
+	graphqlCmd.AddCommand(graphqlDiffCmd)
+
+	graphqlDiffCmd.Flags().String("headers", "", "Custom headers for introspecting endpoints, formatted as key=value pairs separated by commas")
+	graphqlDiffCmd.Flags().StringP("params", "p", "", "Query parameters for introspecting endpoints, formatted as key=value pairs separated by commas")
+	graphqlDiffCmd.Flags().Bool("json", false, "Print the changes as JSON")
+}
+
+// graphQLDiffSchema loads one side of a diff: an endpoint is introspected,
+// anything else is read as a schema file.
+func graphQLDiffSchema(cmd *cobra.Command, source string) (*utils.GraphQLSchema, error) {
+	source, err := interpolate(cmd, source)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return utils.LoadGraphQLSchema(source)
+	}
+
+	_, params, headers, err := graphQLIn(cmd)
+	if err != nil {
+		return nil, err
+	}
+	data, err := utils.GraphQLIntrospect(source, utils.WithHeaders(headers), utils.WithParams(params))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return utils.ParseIntrospection(data)
+}
+
+// graphQLDiffOut prints the changes grouped by criticality, most severe
+// first, followed by a count of each.
+func graphQLDiffOut(cmd *cobra.Command, changes []utils.GraphQLSchemaChange) {
+	out := cmd.OutOrStdout()
+	if len(changes) == 0 {
+		fmt.Fprintf(out, "%s\n", styles.Content.Render("The schemas are the same"))
+		return
+	}
+
+	counts := map[string]int{}
+	for _, criticality := range []string{utils.ChangeBreaking, utils.ChangeDangerous, utils.ChangeSafe} {
+		for _, change := range changes {
+			if change.Criticality != criticality {
+				continue
+			}
+			if counts[criticality] == 0 {
+				fmt.Fprintf(out, "%s\n", styles.Heading.Render(criticality))
+			}
+			counts[criticality]++
+			fmt.Fprintf(out, "%s\n", styles.Content.Render(change.Message))
+		}
+	}
+
+	fmt.Fprintf(out, "%s\n", styles.Heading.Render("SUMMARY"))
+	fmt.Fprintf(out, "%s: %d\n", styles.Key.Render("Breaking"), counts[utils.ChangeBreaking])
+	fmt.Fprintf(out, "%s: %d\n", styles.Key.Render("Dangerous"), counts[utils.ChangeDangerous])
+	fmt.Fprintf(out, "%s: %d\n", styles.Key.Render("Safe"), counts[utils.ChangeSafe])
+}
@@ -1,15 +1,18 @@
 package cmd
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/suryanshu-09/hulaki/app"
 	"github.com/suryanshu-09/hulaki/styles"
 	"github.com/suryanshu-09/hulaki/utils"
 )
@@ -28,23 +31,66 @@ You can connect to servers, call methods, and send data.`,
 2. Call a gRPC method with data:
    hulaki grpc localhost:50051 --service=UserService --method=CreateUser --data='{"name":"John","email":"john@example.com"}'
 
-3. Call gRPC with custom headers:
+3. Stream several messages to a client-streaming or bidirectional method:
+   hulaki grpc localhost:50051 --service=ChatService --method=Chat --data=@messages.ndjson --ndjson
+   hulaki grpc localhost:50051 --service=ChatService --method=Chat --interactive
+
+4. Call gRPC with custom headers:
    hulaki grpc localhost:50051 --service=UserService --method=GetUser --headers=authorization=Bearer token
 
-4. Reflect on gRPC services:
-   hulaki grpc localhost:50051 --reflect`,
+5. Reflect on gRPC services:
+   hulaki grpc localhost:50051 --reflect
+
+6. Describe a service, method or message:
+   hulaki grpc localhost:50051 --describe=users.v1.UserService
+
+7. Call a server without reflection using local proto files or a protoset:
+   hulaki grpc localhost:50051 --import-path=./protos --proto=users/v1/users.proto --service=users.v1.UserService --method=GetUser
+   hulaki grpc localhost:50051 --protoset=users.protoset --describe=users.v1.UserService
+
+8. Call a server over TLS, or with mutual TLS:
+   hulaki grpc api.example.com:443 --tls --service=UserService --method=GetUser
+   hulaki grpc localhost:50051 --cacert=ca.pem --cert=client.pem --key=client-key.pem --service=UserService --method=GetUser
+
+9. Give up on a slow call or an unreachable server:
+   hulaki grpc localhost:50051 --service=UserService --method=GetUser --timeout=2s --connect-timeout=5s
+
+10. Print a sample request to fill in for --data:
+   hulaki grpc localhost:50051 --template=users.v1.UserService/CreateUser > user.json
+
+11. Browse the services of a server and call them interactively:
+   hulaki grpc localhost:50051 --explore
+
+12. Call a service through a gRPC-Web proxy or a Connect server:
+   hulaki grpc localhost:8080 --protocol=grpc-web --proto=users/v1/users.proto --service=users.v1.UserService --method=GetUser
+   hulaki grpc https://api.example.com --protocol=connect --protoset=users.protoset --service=users.v1.UserService --method=GetUser`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
 			return errors.New("please provide a gRPC server address (e.g., localhost:50051)")
 		}
 
-		address := args[0]
+		address, err := interpolate(cmd, args[0])
+		if err != nil {
+			return err
+		}
 		reflect, _ := cmd.Flags().GetBool("reflect")
 
 		if reflect {
 			return grpcReflect(cmd, address)
 		}
 
+		if symbol, _ := cmd.Flags().GetString("describe"); symbol != "" {
+			return grpcDescribe(cmd, address, symbol)
+		}
+
+		if symbol, _ := cmd.Flags().GetString("template"); symbol != "" {
+			return grpcTemplate(cmd, address, symbol)
+		}
+
+		if explore, _ := cmd.Flags().GetBool("explore"); explore {
+			return grpcExplore(cmd, address)
+		}
+
 		service, err := cmd.Flags().GetString("service")
 		if err != nil || service == "" {
 			return errors.New("please provide a service name using --service flag")
@@ -55,27 +101,41 @@ You can connect to servers, call methods, and send data.`,
 			return errors.New("please provide a method name using --method flag")
 		}
 
-		data, params, headers, err := grpcIn(cmd)
+		requests, closeData, params, headers, err := grpcIn(cmd)
 		if err != nil {
 			return err
 		}
+		defer closeData()
 
-		var grpcArgs []utils.Args
+		grpcArgs, err := grpcOptions(cmd)
+		if err != nil {
+			return err
+		}
 		if len(headers) > 0 {
 			grpcArgs = append(grpcArgs, utils.WithHeaders(headers))
 		}
 		if len(params) > 0 {
 			grpcArgs = append(grpcArgs, utils.WithParams(params))
 		}
-		if data != nil {
-			jsonData, _ := json.Marshal(data)
-			grpcArgs = append(grpcArgs, utils.WithBody(bytes.NewBuffer(jsonData)))
+
+		if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+			return grpcInteractive(cmd, address, service, method, grpcArgs)
 		}
 
-		resp, err := utils.GRPCCall(address, service, method, grpcArgs...)
+		resp, err := utils.GRPCStreamCall(address, service, method, requests, grpcStreamOut(cmd), grpcArgs...)
 		if err != nil {
 			return err
 		}
+		if resp.Error == nil {
+			// Messages were printed as they arrived; headers and trailers
+			// are only complete once the call has ended.
+			less, _ := cmd.Flags().GetBool("less")
+			ndjson, _ := cmd.Flags().GetBool("ndjson")
+			if !less && !ndjson {
+				grpcMetadataOut(cmd.OutOrStdout(), resp)
+			}
+			return nil
+		}
 
 		return grpcOut(cmd, resp)
 	},
@@ -86,32 +146,93 @@ func init() {
 
 	grpcCmd.Flags().StringP("service", "s", "", "gRPC service name (required)")
 	grpcCmd.Flags().StringP("method", "m", "", "gRPC method name (required)")
-	grpcCmd.Flags().String("data", "", "Request data as JSON string")
+	grpcCmd.Flags().String("data", "", "Request data as JSON string, - to read from stdin or @file to read from a file. Client-streaming methods take several messages")
+	grpcCmd.Flags().Bool("ndjson", false, "Print each response message as a single line of JSON")
+	grpcCmd.Flags().BoolP("interactive", "i", false, "Open an interactive client for streaming methods")
 	grpcCmd.Flags().String("headers", "", "Custom headers/metadata for the gRPC request, formatted as key=value pairs separated by commas")
 	grpcCmd.Flags().StringP("params", "p", "", "Query parameters for the gRPC request, formatted as key=value pairs separated by commas")
 	grpcCmd.Flags().BoolP("less", "l", false, "Show only the response data, omitting headers and formatted output")
 	grpcCmd.Flags().Bool("reflect", false, "Reflect on available gRPC services")
+	grpcCmd.Flags().String("describe", "", "Describe a service, method, message or enum")
+	grpcCmd.Flags().Bool("explore", false, "Browse services and methods and call them in an interactive explorer")
+	grpcCmd.Flags().String("template", "", "Print a sample request JSON for a method, given as Service/Method")
+	addDescriptorFlags(grpcCmd)
+	grpcCmd.Flags().String("protocol", utils.ProtocolGRPC, "Protocol to call the method with: grpc, grpc-web or connect (the last two need --proto or --protoset)")
+
+	// Connection flags are shared with the grpc subcommands.
+	grpcCmd.PersistentFlags().Bool("tls", false, "Connect over TLS (implied by the other TLS flags)")
+	grpcCmd.PersistentFlags().String("cacert", "", "CA certificate (PEM) to verify the server with")
+	grpcCmd.PersistentFlags().String("cert", "", "Client certificate (PEM) for mutual TLS")
+	grpcCmd.PersistentFlags().String("key", "", "Client private key (PEM) for mutual TLS")
+	grpcCmd.PersistentFlags().String("servername", "", "Override the server name used to verify the certificate")
+	grpcCmd.PersistentFlags().Bool("insecure-skip-verify", false, "Skip verification of the server certificate")
+	grpcCmd.PersistentFlags().Duration("timeout", 0, "Deadline for each call, e.g. 500ms or 10s (0 for none)")
+	grpcCmd.PersistentFlags().Duration("connect-timeout", time.Second, "How long to wait for the connection to the server")
+}
+
+// addDescriptorFlags declares the flags that load descriptors from local
+// files, for the commands that resolve methods.
+func addDescriptorFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("proto", nil, "Proto file to load descriptors from instead of server reflection (repeatable)")
+	cmd.Flags().StringArray("import-path", nil, "Directory to resolve --proto files and their imports from (repeatable)")
+	cmd.Flags().StringArray("protoset", nil, "Protoset file (serialized FileDescriptorSet) to load descriptors from (repeatable)")
 }
 
-func grpcIn(cmd *cobra.Command) (data map[string]any, params map[string]string, headers map[string]string, err error) {
-	// Parse data
-	data = make(map[string]any)
+// grpcIn parses the request data, params and headers of a call. The
+// returned func closes the file opened for --data=@file, and must be called
+// once the call is done.
+func grpcIn(cmd *cobra.Command) (requests utils.GRPCRequestSource, closeData func() error, params map[string]string, headers map[string]string, err error) {
+	if requests, closeData, err = grpcDataIn(cmd); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if params, err = grpcParamsIn(cmd); err != nil {
+		closeData()
+		return nil, nil, nil, nil, err
+	}
+	if headers, err = grpcHeadersIn(cmd); err != nil {
+		closeData()
+		return nil, nil, nil, nil, err
+	}
+
+	return requests, closeData, params, headers, nil
+}
+
+// grpcDataIn reads the --data flag: inline JSON, "-" for stdin or "@file".
+// Client-streaming methods take several messages, one after another or as a
+// JSON array. The returned func closes the file, if one was opened.
+func grpcDataIn(cmd *cobra.Command) (utils.GRPCRequestSource, func() error, error) {
+	closeData := func() error { return nil }
+	var input io.Reader
 	dataStr, err := cmd.Flags().GetString("data")
 	if err == nil && dataStr != "" {
-		if dataStr == "-" {
-			// Read from stdin
-			buf := new(bytes.Buffer)
-			io.Copy(buf, os.Stdin)
-			dataStr = buf.String()
-		}
-		if err := json.Unmarshal([]byte(dataStr), &data); err != nil {
-			return nil, nil, nil, fmt.Errorf("invalid data JSON: %w", err)
+		switch {
+		case dataStr == "-":
+			input = os.Stdin
+		case strings.HasPrefix(dataStr, "@"):
+			f, err := os.Open(dataStr[1:])
+			if err != nil {
+				return nil, nil, err
+			}
+			input = f
+			closeData = f.Close
+		default:
+			input = strings.NewReader(dataStr)
 		}
 	}
 
-	// Parse params
+	requests, err := interpolateRequests(cmd, utils.JSONRequestSource(input))
+	if err != nil {
+		closeData()
+		return nil, nil, err
+	}
+	return requests, closeData, nil
+}
+
+// grpcParamsIn parses the --params flag, used as URL query parameters by
+// gRPC-Web and Connect.
+func grpcParamsIn(cmd *cobra.Command) (map[string]string, error) {
 	p, err := cmd.Flags().GetString("params")
-	params = make(map[string]string)
+	params := make(map[string]string)
 	if err == nil && p != "" {
 		if strings.Contains(p, ",") {
 			paramsArr := strings.Split(p, ",")
@@ -127,9 +248,13 @@ func grpcIn(cmd *cobra.Command) (data map[string]any, params map[string]string,
 		}
 	}
 
-	// Parse headers
+	return interpolateMap(cmd, params)
+}
+
+// grpcHeadersIn parses the --headers flag into request metadata.
+func grpcHeadersIn(cmd *cobra.Command) (map[string]string, error) {
 	h, err := cmd.Flags().GetString("headers")
-	headers = make(map[string]string)
+	headers := make(map[string]string)
 	if err == nil && h != "" {
 		if strings.Contains(h, ",") {
 			headersArr := strings.Split(h, ",")
@@ -145,7 +270,93 @@ func grpcIn(cmd *cobra.Command) (data map[string]any, params map[string]string,
 		}
 	}
 
-	return data, params, headers, nil
+	return interpolateMap(cmd, headers)
+}
+
+// interpolateRequests substitutes the active environment into every
+// request message as it is read. Like other bodies, messages are sent as
+// written when no environment is selected.
+func interpolateRequests(cmd *cobra.Command, requests utils.GRPCRequestSource) (utils.GRPCRequestSource, error) {
+	env, err := activeEnvironment(cmd)
+	if err != nil || env == nil {
+		return requests, err
+	}
+	return func() (json.RawMessage, error) {
+		raw, err := requests()
+		if err != nil {
+			return nil, err
+		}
+		return env.InterpolateJSON(raw)
+	}, nil
+}
+
+// grpcOptions builds the options of the grpc command from its flags: the
+// protocol, the descriptors and the connection.
+func grpcOptions(cmd *cobra.Command) ([]utils.Args, error) {
+	var grpcArgs []utils.Args
+
+	if protocol, _ := cmd.Flags().GetString("protocol"); protocol != "" {
+		if !utils.ValidGRPCProtocol(protocol) {
+			return nil, fmt.Errorf("unknown protocol %q (must be grpc, grpc-web or connect)", protocol)
+		}
+		grpcArgs = append(grpcArgs, utils.WithGRPCProtocol(protocol))
+	}
+
+	descriptorArgs, err := grpcDescriptorOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	connectionArgs, err := grpcConnectionOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Concat(grpcArgs, descriptorArgs, connectionArgs), nil
+}
+
+// grpcDescriptorOptions loads the descriptors given with the flags declared
+// by addDescriptorFlags.
+func grpcDescriptorOptions(cmd *cobra.Command) ([]utils.Args, error) {
+	protos, _ := cmd.Flags().GetStringArray("proto")
+	importPaths, _ := cmd.Flags().GetStringArray("import-path")
+	protosets, _ := cmd.Flags().GetStringArray("protoset")
+	if len(protos) == 0 && len(protosets) == 0 {
+		return nil, nil
+	}
+	src, err := utils.LoadDescriptorSource(protos, importPaths, protosets)
+	if err != nil {
+		return nil, err
+	}
+	return []utils.Args{utils.WithDescriptorSource(src)}, nil
+}
+
+// grpcConnectionOptions builds the timeout and TLS options from the
+// connection flags every gRPC subcommand shares.
+func grpcConnectionOptions(cmd *cobra.Command) ([]utils.Args, error) {
+	var grpcArgs []utils.Args
+
+	if timeout, _ := cmd.Flags().GetDuration("timeout"); timeout > 0 {
+		grpcArgs = append(grpcArgs, utils.WithGRPCTimeout(timeout))
+	}
+	if timeout, _ := cmd.Flags().GetDuration("connect-timeout"); timeout > 0 {
+		grpcArgs = append(grpcArgs, utils.WithGRPCConnectTimeout(timeout))
+	}
+
+	useTLS, _ := cmd.Flags().GetBool("tls")
+	var tlsOpts utils.TLSOptions
+	tlsOpts.CACert, _ = cmd.Flags().GetString("cacert")
+	tlsOpts.Cert, _ = cmd.Flags().GetString("cert")
+	tlsOpts.Key, _ = cmd.Flags().GetString("key")
+	tlsOpts.ServerName, _ = cmd.Flags().GetString("servername")
+	tlsOpts.InsecureSkipVerify, _ = cmd.Flags().GetBool("insecure-skip-verify")
+	if useTLS || tlsOpts != (utils.TLSOptions{}) {
+		cfg, err := utils.LoadTLSConfig(tlsOpts)
+		if err != nil {
+			return nil, err
+		}
+		grpcArgs = append(grpcArgs, utils.WithTLSConfig(cfg))
+	}
+
+	return grpcArgs, nil
 }
 
 func grpcOut(cmd *cobra.Command, resp *utils.GRPCResponse) error {
@@ -156,21 +367,23 @@ func grpcOut(cmd *cobra.Command, resp *utils.GRPCResponse) error {
 		fmt.Fprintf(out, "%s\n", styles.Heading.Render("ERROR"))
 		fmt.Fprintf(out, "%s: %s\n", styles.Key.Render("Code"), resp.Error.Code)
 		fmt.Fprintf(out, "%s: %s\n", styles.Key.Render("Message"), resp.Error.Message)
+		if len(resp.Error.Details) > 0 {
+			detailsJSON, err := json.MarshalIndent(resp.Error.Details, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to format error details: %w", err)
+			}
+			fmt.Fprintf(out, "%s\n", styles.Heading.Render("DETAILS"))
+			fmt.Fprintf(out, "%s\n", styles.Content.Render(string(detailsJSON)))
+		}
+		if !less {
+			grpcMetadataOut(out, resp)
+		}
 		return nil
 	}
 
 	if !less {
 		fmt.Fprintf(out, "%s\n", styles.Heading.Render("GRPC RESPONSE"))
-
-		if resp.Metadata != nil && len(resp.Metadata) > 0 {
-			fmt.Fprintf(out, "%s\n", styles.Heading.Render("METADATA"))
-			for key, values := range resp.Metadata {
-				for _, value := range values {
-					fmt.Fprintf(out, "%s: %s\n", styles.Key.Render(key), value)
-				}
-			}
-		}
-
+		grpcMetadataOut(out, resp)
 		fmt.Fprintf(out, "%s\n", styles.Heading.Render("DATA"))
 	}
 
@@ -186,11 +399,141 @@ func grpcOut(cmd *cobra.Command, resp *utils.GRPCResponse) error {
 	return nil
 }
 
+// grpcMetadataOut prints the response headers and trailers, if any.
+func grpcMetadataOut(out io.Writer, resp *utils.GRPCResponse) {
+	sections := []struct {
+		heading string
+		md      map[string][]string
+	}{
+		{"HEADERS", resp.Metadata},
+		{"TRAILERS", resp.Trailers},
+	}
+	for _, section := range sections {
+		if len(section.md) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "%s\n", styles.Heading.Render(section.heading))
+		keys := slices.Sorted(maps.Keys(section.md))
+		for _, key := range keys {
+			for _, value := range section.md[key] {
+				fmt.Fprintf(out, "%s: %s\n", styles.Key.Render(key), value)
+			}
+		}
+	}
+}
+
+// grpcStreamOut returns a handler that prints each response message as it
+// arrives, either pretty-printed or as NDJSON.
+func grpcStreamOut(cmd *cobra.Command) func(any) {
+	less, _ := cmd.Flags().GetBool("less")
+	ndjson, _ := cmd.Flags().GetBool("ndjson")
+	out := cmd.OutOrStdout()
+	first := true
+
+	return func(msg any) {
+		if ndjson {
+			line, _ := json.Marshal(msg)
+			fmt.Fprintf(out, "%s\n", line)
+			return
+		}
+		if first && !less {
+			fmt.Fprintf(out, "%s\n", styles.Heading.Render("GRPC RESPONSE"))
+			fmt.Fprintf(out, "%s\n", styles.Heading.Render("DATA"))
+		}
+		first = false
+		dataJSON, _ := json.MarshalIndent(msg, "", "  ")
+		fmt.Fprintf(out, "%s\n", styles.Content.Render(string(dataJSON)))
+	}
+}
+
 func grpcReflect(cmd *cobra.Command, address string) error {
-	resp, err := utils.GRPCReflect(address)
+	headers, err := grpcHeadersIn(cmd)
+	if err != nil {
+		return err
+	}
+
+	grpcArgs, err := grpcOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	resp, err := utils.GRPCReflect(address, append(grpcArgs, utils.WithHeaders(headers))...)
+	if err != nil {
+		return err
+	}
+
+	return grpcOut(cmd, resp)
+}
+
+func grpcDescribe(cmd *cobra.Command, address, symbol string) error {
+	headers, err := grpcHeadersIn(cmd)
+	if err != nil {
+		return err
+	}
+
+	grpcArgs, err := grpcOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	resp, err := utils.GRPCDescribe(address, symbol, append(grpcArgs, utils.WithHeaders(headers))...)
 	if err != nil {
 		return err
 	}
 
 	return grpcOut(cmd, resp)
 }
+
+// grpcTemplate prints a sample request for a method as plain JSON, so it
+// can be redirected to a file and edited.
+func grpcTemplate(cmd *cobra.Command, address, symbol string) error {
+	headers, err := grpcHeadersIn(cmd)
+	if err != nil {
+		return err
+	}
+
+	grpcArgs, err := grpcOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	resp, err := utils.GRPCTemplate(address, symbol, append(grpcArgs, utils.WithHeaders(headers))...)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return grpcOut(cmd, resp)
+	}
+
+	dataJSON, err := json.MarshalIndent(resp.Data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format template: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s\n", dataJSON)
+	return nil
+}
+
+// grpcExplore opens the interactive explorer. Requests typed in it are
+// interpolated with the active environment when they are sent.
+func grpcExplore(cmd *cobra.Command, address string) error {
+	params, err := grpcParamsIn(cmd)
+	if err != nil {
+		return err
+	}
+	headers, err := grpcHeadersIn(cmd)
+	if err != nil {
+		return err
+	}
+
+	grpcArgs, err := grpcOptions(cmd)
+	if err != nil {
+		return err
+	}
+	grpcArgs = append(grpcArgs, utils.WithHeaders(headers), utils.WithParams(params))
+
+	env, err := activeEnvironment(cmd)
+	if err != nil {
+		return err
+	}
+	return app.RenderGRPC(address, env, grpcArgs)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/suryanshu-09/hulaki/styles"
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// grpcBenchCmd represents the grpc bench command
+var grpcBenchCmd = &cobra.Command{
+	Use:   "bench <address>",
+	Short: "Load test a gRPC method",
+	Long: `The 'bench' command calls a gRPC method over and over and reports the latency
+distribution, throughput and the count of each status code.
+It stops after --requests calls or once --duration has passed, whichever comes first.
+Calls are always made over native gRPC; gRPC-Web and Connect are not supported.`,
+	Example: `Examples:
+1. Make 10000 calls, 50 at a time:
+   hulaki grpc bench localhost:50051 --call=users.v1.UserService/GetUser --data='{"id":1}' -c 50 -n 10000
+
+2. Hold 200 calls per second for 30 seconds over 4 connections:
+   hulaki grpc bench localhost:50051 --call=users.v1.UserService/GetUser --duration=30s --rps=200 --connections=4
+
+3. Save a JSON report to compare in CI:
+   hulaki grpc bench localhost:50051 --call=users.v1.UserService/GetUser -n 1000 --json > bench.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("please provide a gRPC server address (e.g., localhost:50051)")
+		}
+
+		address, err := interpolate(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		call, _ := cmd.Flags().GetString("call")
+		if call == "" {
+			return errors.New("please provide a method to call using --call=Service/Method")
+		}
+
+		var opts utils.GRPCBenchOptions
+		opts.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+		opts.Requests, _ = cmd.Flags().GetInt("requests")
+		opts.Duration, _ = cmd.Flags().GetDuration("duration")
+		opts.RPS, _ = cmd.Flags().GetInt("rps")
+		opts.Connections, _ = cmd.Flags().GetInt("connections")
+		if opts.Duration > 0 && !cmd.Flags().Changed("requests") {
+			// Run for the whole duration unless a count was given too.
+			opts.Requests = 0
+		}
+
+		requests, closeData, err := grpcDataIn(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeData()
+		headers, err := grpcHeadersIn(cmd)
+		if err != nil {
+			return err
+		}
+		descriptorArgs, err := grpcDescriptorOptions(cmd)
+		if err != nil {
+			return err
+		}
+		connectionArgs, err := grpcConnectionOptions(cmd)
+		if err != nil {
+			return err
+		}
+		grpcArgs := append(slices.Concat(descriptorArgs, connectionArgs), utils.WithHeaders(headers))
+
+		report, err := utils.GRPCBench(address, call, requests, opts, grpcArgs...)
+		if err != nil {
+			return err
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			reportJSON, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to format report: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\n", reportJSON)
+			return nil
+		}
+		grpcBenchOut(cmd, report)
+		return nil
+	},
+}
+
+func init() {
+	grpcCmd.AddCommand(grpcBenchCmd)
+
+	grpcBenchCmd.Flags().String("call", "", "Method to call, as Service/Method (required)")
+	grpcBenchCmd.Flags().String("data", "", "Request data as JSON string, - to read from stdin or @file to read from a file")
+	grpcBenchCmd.Flags().String("headers", "", "Custom headers/metadata for every call, formatted as key=value pairs separated by commas")
+	grpcBenchCmd.Flags().IntP("concurrency", "c", 10, "Number of calls in flight at once")
+	grpcBenchCmd.Flags().IntP("requests", "n", 200, "Total number of calls to make")
+	grpcBenchCmd.Flags().Duration("duration", 0, "Keep calling for this long, e.g. 30s (overrides --requests unless it is given too)")
+	grpcBenchCmd.Flags().Int("rps", 0, "Limit the rate calls are started at (0 for no limit)")
+	grpcBenchCmd.Flags().Int("connections", 1, "Number of connections to spread the calls over")
+	grpcBenchCmd.Flags().Bool("json", false, "Print the report as JSON")
+	addDescriptorFlags(grpcBenchCmd)
+}
+
+// grpcBenchOut prints a load test report for people.
+func grpcBenchOut(cmd *cobra.Command, report *utils.GRPCBenchReport) {
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintf(out, "%s\n", styles.Heading.Render("SUMMARY"))
+	fmt.Fprintf(out, "%s: %d\n", styles.Key.Render("Count"), report.Count)
+	fmt.Fprintf(out, "%s: %.2f ms\n", styles.Key.Render("Total"), report.TotalMs)
+	fmt.Fprintf(out, "%s: %.2f ms\n", styles.Key.Render("Fastest"), report.Latency.FastestMs)
+	fmt.Fprintf(out, "%s: %.2f ms\n", styles.Key.Render("Slowest"), report.Latency.SlowestMs)
+	fmt.Fprintf(out, "%s: %.2f ms\n", styles.Key.Render("Average"), report.Latency.AverageMs)
+	fmt.Fprintf(out, "%s: %.2f\n", styles.Key.Render("Requests/sec"), report.RPS)
+
+	fmt.Fprintf(out, "%s\n", styles.Heading.Render("LATENCY"))
+	fmt.Fprintf(out, "%s: %.2f ms\n", styles.Key.Render("p50"), report.Latency.P50Ms)
+	fmt.Fprintf(out, "%s: %.2f ms\n", styles.Key.Render("p90"), report.Latency.P90Ms)
+	fmt.Fprintf(out, "%s: %.2f ms\n", styles.Key.Render("p99"), report.Latency.P99Ms)
+
+	if len(report.Histogram) > 0 {
+		fmt.Fprintf(out, "%s\n", styles.Heading.Render("HISTOGRAM"))
+		largest := 0
+		for _, bucket := range report.Histogram {
+			largest = max(largest, bucket.Count)
+		}
+		for _, bucket := range report.Histogram {
+			bar := strings.Repeat("∎", bucket.Count*40/max(largest, 1))
+			fmt.Fprintf(out, "%s [%d]\t|%s\n", styles.Key.Render(fmt.Sprintf("%10.2f ms", bucket.UpToMs)), bucket.Count, bar)
+		}
+	}
+
+	fmt.Fprintf(out, "%s\n", styles.Heading.Render("STATUS CODES"))
+	for _, code := range slices.Sorted(maps.Keys(report.StatusCodes)) {
+		fmt.Fprintf(out, "%s: %d\n", styles.Key.Render(code), report.StatusCodes[code])
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Fprintf(out, "%s\n", styles.Heading.Render("ERRORS"))
+		for _, message := range slices.Sorted(maps.Keys(report.Errors)) {
+			fmt.Fprintf(out, "%s: %d\n", styles.Key.Render(message), report.Errors[message])
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/suryanshu-09/hulaki/styles"
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// grpcHealthCmd represents the grpc health command
+var grpcHealthCmd = &cobra.Command{
+	Use:   "health <address>",
+	Short: "Check the health of a gRPC server",
+	Long: `The 'health' command asks a gRPC server for its status using the standard
+grpc.health.v1 protocol, as used by Kubernetes gRPC probes.
+It exits with a non-zero status when the service is not SERVING.`,
+	Example: `Examples:
+1. Check the server as a whole:
+   hulaki grpc health localhost:50051
+
+2. Check a single service:
+   hulaki grpc health localhost:50051 --service=users.v1.UserService
+
+3. Follow status changes as they happen:
+   hulaki grpc health localhost:50051 --service=users.v1.UserService --watch`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("please provide a gRPC server address (e.g., localhost:50051)")
+		}
+
+		address, err := interpolate(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		service, _ := cmd.Flags().GetString("service")
+		watch, _ := cmd.Flags().GetBool("watch")
+
+		headers, err := grpcHeadersIn(cmd)
+		if err != nil {
+			return err
+		}
+		grpcArgs, err := grpcConnectionOptions(cmd)
+		if err != nil {
+			return err
+		}
+		grpcArgs = append(grpcArgs, utils.WithHeaders(headers))
+
+		var resp *utils.GRPCResponse
+		if watch {
+			out := cmd.OutOrStdout()
+			resp, err = utils.GRPCHealthWatch(address, service, func(status string) {
+				fmt.Fprintf(out, "%s: %s\n", styles.Key.Render("Status"), status)
+			}, grpcArgs...)
+		} else {
+			resp, err = utils.GRPCHealthCheck(address, service, grpcArgs...)
+		}
+		if err != nil {
+			return err
+		}
+
+		return grpcHealthOut(cmd, resp, watch)
+	},
+}
+
+func init() {
+	grpcCmd.AddCommand(grpcHealthCmd)
+
+	grpcHealthCmd.Flags().String("service", "", "Service to check (empty for the whole server)")
+	grpcHealthCmd.Flags().Bool("watch", false, "Stream status updates until the server ends the stream")
+	grpcHealthCmd.Flags().String("headers", "", "Custom headers/metadata for the request, formatted as key=value pairs separated by commas")
+}
+
+// grpcHealthOut prints the result of a health check and turns anything but
+// SERVING into an error, so the exit code can be used in scripts.
+func grpcHealthOut(cmd *cobra.Command, resp *utils.GRPCResponse, watch bool) error {
+	if resp.Error != nil {
+		if err := grpcOut(cmd, resp); err != nil {
+			return err
+		}
+		return fmt.Errorf("health check failed: %s", resp.Error.Code)
+	}
+
+	data, _ := resp.Data.(map[string]any)
+	status, _ := data["status"].(string)
+	if !watch {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", styles.Key.Render("Status"), status)
+	}
+	if status != utils.HealthServing {
+		if status == "" {
+			status = "unknown"
+		}
+		return fmt.Errorf("service is %s", status)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/textarea"
+	"github.com/charmbracelet/bubbles/v2/viewport"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/spf13/cobra"
+	"github.com/suryanshu-09/hulaki/styles"
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// grpcStreamMsg is a response message received on the stream.
+type grpcStreamMsg string
+
+// grpcStreamEndMsg reports that the call finished.
+type grpcStreamEndMsg struct {
+	resp *utils.GRPCResponse
+	err  error
+}
+
+// grpcInteractive runs a streaming call in a TUI: every message typed in is
+// sent on the stream, and replies are shown as they arrive.
+func grpcInteractive(cmd *cobra.Command, address, service, method string, grpcArgs []utils.Args) error {
+	pr, pw := io.Pipe()
+	requests, err := interpolateRequests(cmd, utils.JSONRequestSource(pr))
+	if err != nil {
+		return err
+	}
+
+	gc := NewGRPCStreamCli(pw)
+	p := tea.NewProgram(gc, tea.WithMouseAllMotion())
+
+	go func() {
+		resp, err := utils.GRPCStreamCall(address, service, method, requests, func(msg any) {
+			dataJSON, _ := json.MarshalIndent(msg, "", "  ")
+			p.Send(grpcStreamMsg(dataJSON))
+		}, grpcArgs...)
+		pr.CloseWithError(io.ErrClosedPipe)
+		p.Send(grpcStreamEndMsg{resp: resp, err: err})
+	}()
+
+	_, err = p.Run()
+	return err
+}
+
+type GRPCStreamCli struct {
+	Input    textarea.Model
+	ViewPort viewport.Model
+	requests *io.PipeWriter
+	messages []string
+	closed   bool
+}
+
+func (gc *GRPCStreamCli) Init() tea.Cmd {
+	return nil
+}
+
+func (gc *GRPCStreamCli) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		termHeight = msg.Height
+		termWidth = msg.Width
+
+		gc.Input.SetWidth(msg.Width - 4)
+		gc.ViewPort.SetWidth(msg.Width - 2)
+		gc.ViewPort.SetHeight(msg.Height / 2)
+	case grpcStreamMsg:
+		gc.AddMessage(string(msg))
+	case grpcStreamEndMsg:
+		gc.closed = true
+		switch {
+		case msg.err != nil:
+			gc.AddMessage(styles.Key.Render("Error: ") + msg.err.Error())
+		case msg.resp.Error != nil:
+			gc.AddMessage(styles.Key.Render(msg.resp.Error.Code+": ") + msg.resp.Error.Message)
+			if len(msg.resp.Error.Details) > 0 {
+				detailsJSON, _ := json.MarshalIndent(msg.resp.Error.Details, "", "  ")
+				gc.AddMessage(string(detailsJSON))
+			}
+		default:
+			gc.AddMessage(styles.Key.Render("Stream closed: OK"))
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return gc, tea.Quit
+		case "ctrl+d":
+			// Half-close: no more requests, but keep reading replies.
+			gc.requests.Close()
+			gc.AddMessage(styles.Key.Render("Request stream closed"))
+			return gc, nil
+		case "enter":
+			if gc.closed {
+				return gc, nil
+			}
+			send := gc.Input.Value()
+			if _, err := fmt.Fprintln(gc.requests, send); err != nil {
+				gc.AddMessage(styles.Key.Render("Error sending message: ") + err.Error())
+			} else {
+				gc.AddMessage(styles.Key.Render("> ") + send)
+			}
+			gc.Input.Reset()
+			return gc, nil
+		}
+	}
+
+	var cmds []tea.Cmd
+
+	updatedInput, cmd := gc.Input.Update(msg)
+	cmds = append(cmds, cmd)
+	gc.Input = updatedInput
+
+	updatedView, cmd := gc.ViewPort.Update(msg)
+	cmds = append(cmds, cmd)
+	gc.ViewPort = updatedView
+
+	return gc, tea.Batch(cmds...)
+}
+
+func (gc *GRPCStreamCli) View() string {
+	GRPCOutputStyle := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Width(termWidth - 2).BorderForeground(lipgloss.Color("#8a2be2"))
+	return lipgloss.JoinVertical(lipgloss.Left, styles.Key.Render("Send a message (JSON, enter to send, ctrl+d to close the request stream):"), GRPCOutputStyle.Render(gc.Input.View()), "\n", GRPCOutputStyle.Render(gc.ViewPort.View()))
+}
+
+func (gc *GRPCStreamCli) AddMessage(message string) {
+	gc.messages = append(gc.messages, message)
+	gc.ViewPort.SetContent(gc.formatMessages())
+	gc.ViewPort.GotoBottom()
+}
+
+func (gc *GRPCStreamCli) formatMessages() string {
+	var buffer bytes.Buffer
+	for _, msg := range gc.messages {
+		buffer.WriteString(msg + "\n")
+	}
+	return buffer.String()
+}
+
+func NewGRPCStreamCli(requests *io.PipeWriter) *GRPCStreamCli {
+	ta := textarea.New()
+	ta.CharLimit = 0
+	ta.Styles = textarea.DefaultDarkStyles()
+	ta.SetHeight(1)
+	ta.VirtualCursor = true
+	ta.ShowLineNumbers = false
+	ta.Placeholder = `{"message": "..."}`
+	ta.Styles.Cursor.Shape = tea.CursorBlock
+	ta.Styles.Cursor.Blink = true
+	ta.Styles.Cursor.BlinkSpeed = 2 * time.Second
+	ta.Styles.Cursor.Color = lipgloss.Color("#ff1493")
+	ta.Focus()
+
+	v := viewport.New()
+	v.SetWidth(termWidth - 2)
+	v.SetHeight(termHeight / 2)
+	v.FillHeight = true
+	v.MouseWheelEnabled = true
+	v.SoftWrap = true
+	v.KeyMap = viewport.KeyMap{}
+
+	return &GRPCStreamCli{
+		Input:    ta,
+		ViewPort: v,
+		requests: requests,
+		messages: []string{},
+	}
+}
@@ -18,7 +18,10 @@ You can include query parameters and headers to customize the request.`,
 		if err != nil {
 			return err
 		}
-		url := args[0]
+		url, err := interpolate(cmd, args[0])
+		if err != nil {
+			return err
+		}
 		resp, err := utils.HTTPHead(url, utils.WithHeaders(headers), utils.WithParams(params), utils.WithBody(&iBody))
 		if err != nil {
 			return err
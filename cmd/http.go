@@ -31,6 +31,11 @@ func HTTPIn(cmd *cobra.Command, args []string) (iBody bytes.Buffer, params map[s
 	if err == nil {
 		if b == "-" {
 			io.Copy(&iBody, os.Stdin)
+			raw, err := interpolateBody(cmd, iBody.String())
+			if err != nil {
+				return iBody, nil, nil, err
+			}
+			iBody = *bytes.NewBufferString(raw)
 		} else {
 			if i := strings.Index(b, ","); i != -1 {
 				bodyArr := strings.SplitSeq(b, ",")
@@ -47,6 +52,10 @@ func HTTPIn(cmd *cobra.Command, args []string) (iBody bytes.Buffer, params map[s
 				}
 			}
 			if len(b) > 0 {
+				jBody, err = interpolateBodyMap(cmd, jBody)
+				if err != nil {
+					return iBody, nil, nil, err
+				}
 				marshalled, err := json.Marshal(jBody)
 				if err != nil {
 					return iBody, nil, nil, err
@@ -97,6 +106,13 @@ func HTTPIn(cmd *cobra.Command, args []string) (iBody bytes.Buffer, params map[s
 	if len(args) < 1 {
 		return iBody, nil, nil, errors.New("please provide a url")
 	}
+
+	if params, err = interpolateMap(cmd, params); err != nil {
+		return iBody, nil, nil, err
+	}
+	if headers, err = interpolateMap(cmd, headers); err != nil {
+		return iBody, nil, nil, err
+	}
 	return
 }
 
@@ -29,7 +29,10 @@ You can include query parameters, headers, and a request body to customize the r
 		if err != nil {
 			return err
 		}
-		url := args[0]
+		url, err := interpolate(cmd, args[0])
+		if err != nil {
+			return err
+		}
 		resp, err := utils.HTTPPatch(url, utils.WithHeaders(headers), utils.WithParams(params), utils.WithBody(&iBody))
 		if err != nil {
 			return err
@@ -6,16 +6,23 @@ import (
 
 	"github.com/charmbracelet/fang"
 	"github.com/spf13/cobra"
+	"github.com/suryanshu-09/hulaki/app"
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "hulaki",
 	Short: "Hulalki, Postman for your terminal",
-	Long:  ``,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
-	// Run: func(cmd *cobra.Command, args []string) { },
+	Long: `Hulaki is Postman for your terminal.
+Run it without a subcommand to open the interactive request builder,
+or use one of the subcommands below to send a single request.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		env, err := activeEnvironment(cmd)
+		if err != nil {
+			return err
+		}
+		return app.Render(env)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -34,6 +41,7 @@ func init() {
 	// will be global for your application.
 
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.hulaki.yaml)")
+	rootCmd.PersistentFlags().String("env", "", "Environment whose variables replace {{variable}} placeholders in the request")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run <collection>/<request>",
+	Short: "Send a request saved in a collection",
+	Long: `The 'run' command loads a saved request from a collection and sends it.
+HTTP, GraphQL, gRPC and Socket.IO requests print their response like the matching command would.
+GraphQL subscriptions stream their events over WebSocket until the server completes them.
+WebSocket requests open the interactive WebSocket client, sending the saved body as the first message.`,
+	Example: `Examples:
+1. Run a saved request:
+   hulaki run users/list
+
+2. Run a request from a collection kept in the current directory:
+   hulaki run ./api/users/list
+
+3. Run a request and show only the response body:
+   hulaki run users/list --less`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("please provide a request reference (e.g., users/list)")
+		}
+		collection, name, err := utils.SplitCollectionRef(args[0])
+		if err != nil {
+			return err
+		}
+		req, err := utils.LoadCollectionRequest(collection, name)
+		if err != nil {
+			return err
+		}
+		if err := interpolateCollectionRequest(cmd, req); err != nil {
+			return err
+		}
+		return runCollectionRequest(cmd, req)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().BoolP("less", "l", false, "Show only the response body, omitting headers and formatted output")
+	runCmd.Flags().Bool("raw", false, "Show raw JSON response without parsing GraphQL structure")
+	runCmd.Flags().Bool("fail-on-errors", false, "Exit with a non-zero status when a GraphQL response has errors, even with HTTP 200")
+}
+
+func runCollectionRequest(cmd *cobra.Command, req *utils.CollectionRequest) error {
+	args := []utils.Args{utils.WithHeaders(req.Headers), utils.WithParams(req.Params)}
+
+	switch req.Protocol {
+	case utils.ProtocolHTTP:
+		send, ok := utils.HTTPMethods[req.Method]
+		if !ok {
+			return fmt.Errorf("unsupported HTTP method %q", req.Method)
+		}
+		resp, err := send(req.URL, append(args, utils.WithBody(bytes.NewBufferString(req.Body)))...)
+		if err != nil {
+			return err
+		}
+		return HTTPOut(cmd, resp)
+
+	case utils.ProtocolGraphQL:
+		// The type of the operation decides how it is sent, as with the
+		// graphql command. Documents that do not parse are sent as queries.
+		kind := "query"
+		if op, err := utils.ParseGraphQLOperation(req.Query, ""); err == nil {
+			kind = op.Type
+		}
+		var resp *http.Response
+		var err error
+		switch kind {
+		case "subscription":
+			return graphQLSubscribe(cmd, req.URL, req.Query, "", req.Variables, req.Params, req.Headers)
+		case "mutation":
+			resp, err = utils.GraphQLMutation(req.URL, req.Query, append(args, utils.WithVariables(req.Variables))...)
+		default:
+			resp, err = utils.GraphQLQuery(req.URL, req.Query, append(args, utils.WithVariables(req.Variables))...)
+		}
+		if err != nil {
+			return err
+		}
+		return graphQLOut(cmd, resp, req.Query)
+
+	case utils.ProtocolGRPC:
+		if req.Body != "" {
+			args = append(args, utils.WithBody(bytes.NewBufferString(req.Body)))
+		}
+		resp, err := utils.GRPCCall(req.URL, req.Service, req.Method, args...)
+		if err != nil {
+			return err
+		}
+		return grpcOut(cmd, resp)
+
+	case utils.ProtocolSocketIO:
+		if req.Namespace != "" {
+			params := map[string]string{"namespace": req.Namespace}
+			maps.Copy(params, req.Params)
+			args = append(args, utils.WithParams(params))
+		}
+		var resp *utils.SocketIOResponse
+		var err error
+		if req.Event != "" {
+			if req.Body != "" {
+				args = append(args, utils.WithBody(bytes.NewBufferString(req.Body)))
+			}
+			resp, err = utils.SocketIOEmit(req.URL, req.Event, args...)
+		} else {
+			resp, err = utils.SocketIOConnect(req.URL, args...)
+		}
+		if err != nil {
+			return err
+		}
+		return socketioOut(cmd, resp)
+
+	case utils.ProtocolWS:
+		return websocketSession(req.URL, req.Body, args...)
+	}
+	return fmt.Errorf("unknown protocol %q", req.Protocol)
+}
+
+// interpolateCollectionRequest substitutes the active environment into the
+// URL, headers, params, body and variables of req. The body and variables
+// are sent as written when no environment is selected.
+func interpolateCollectionRequest(cmd *cobra.Command, req *utils.CollectionRequest) error {
+	env, err := activeEnvironment(cmd)
+	if err != nil {
+		return err
+	}
+	if req.URL, err = env.Interpolate(req.URL); err != nil {
+		return err
+	}
+	if req.Headers, err = env.InterpolateMap(req.Headers); err != nil {
+		return err
+	}
+	if req.Params, err = env.InterpolateMap(req.Params); err != nil {
+		return err
+	}
+	if env == nil {
+		return nil
+	}
+	if req.Body, err = env.Interpolate(req.Body); err != nil {
+		return err
+	}
+	if req.Variables != nil {
+		variables, err := env.InterpolateValue(req.Variables)
+		if err != nil {
+			return err
+		}
+		req.Variables = variables.(map[string]any)
+	}
+	return nil
+}
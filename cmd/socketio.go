@@ -42,7 +42,10 @@ You can connect, emit events, and listen for events.`,
 			return errors.New("please provide a Socket.IO server URL (e.g., ws://localhost:3000)")
 		}
 
-		serverURL := args[0]
+		serverURL, err := interpolate(cmd, args[0])
+		if err != nil {
+			return err
+		}
 		emit, _ := cmd.Flags().GetString("emit")
 		listen, _ := cmd.Flags().GetString("listen")
 		durationStr, _ := cmd.Flags().GetString("duration")
@@ -135,6 +138,16 @@ func socketioIn(cmd *cobra.Command) (data map[string]any, params map[string]stri
 		}
 	}
 
+	if data, err = interpolateBodyValues(cmd, data); err != nil {
+		return nil, nil, nil, err
+	}
+	if params, err = interpolateMap(cmd, params); err != nil {
+		return nil, nil, nil, err
+	}
+	if headers, err = interpolateMap(cmd, headers); err != nil {
+		return nil, nil, nil, err
+	}
+
 	return data, params, headers, nil
 }
 
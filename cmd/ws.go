@@ -37,34 +37,49 @@ You can send and receive messages using a terminal-based user interface.`,
 		if err != nil {
 			return err
 		}
-		url := args[0]
-		ws, err := utils.NewWebsocketClient(url, utils.WithHeaders(headers), utils.WithParams(params))
+		url, err := interpolate(cmd, args[0])
 		if err != nil {
 			return err
 		}
-		defer ws.Close()
-
-		wc := NewWebsocketCli(ws)
-
-		go func() {
-			for {
-				recieve := new(bytes.Buffer)
-				err := ws.Read(recieve)
-				if err != nil {
-					log.Println("Error reading message:", err)
-					return
-				}
-				wc.AddMessage(recieve.String())
-			}
-		}()
+		return websocketSession(url, "", utils.WithHeaders(headers), utils.WithParams(params))
+	},
+}
+
+// websocketSession connects to url and runs the interactive WebSocket TUI.
+// A non-empty first message is sent as soon as the connection is up.
+func websocketSession(url, first string, args ...utils.Args) error {
+	ws, err := utils.NewWebsocketClient(url, args...)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
 
-		if _, err := tea.NewProgram(wc, tea.WithMouseAllMotion()).Run(); err != nil {
-			log.Fatal(err)
+	wc := NewWebsocketCli(ws)
+
+	if first != "" {
+		if err := ws.Write(websocket.TextMessage, bytes.NewBufferString(first)); err != nil {
+			return err
 		}
+	}
 
-		os.Exit(0)
-		return nil
-	},
+	go func() {
+		for {
+			recieve := new(bytes.Buffer)
+			err := ws.Read(recieve)
+			if err != nil {
+				log.Println("Error reading message:", err)
+				return
+			}
+			wc.AddMessage(recieve.String())
+		}
+	}()
+
+	if _, err := tea.NewProgram(wc, tea.WithMouseAllMotion()).Run(); err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(0)
+	return nil
 }
 
 func init() {
@@ -199,5 +214,12 @@ func WebsocketIn(cmd *cobra.Command, args []string) (params map[string]string, h
 	if len(args) < 1 {
 		return nil, nil, errors.New("please provide a url")
 	}
+
+	if params, err = interpolateMap(cmd, params); err != nil {
+		return nil, nil, err
+	}
+	if headers, err = interpolateMap(cmd, headers); err != nil {
+		return nil, nil, err
+	}
 	return
 }
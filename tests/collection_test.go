@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+func TestCollections(t *testing.T) {
+	t.Setenv("HULAKI_HOME", t.TempDir())
+
+	t.Run("test save and load request", func(t *testing.T) {
+		req := &utils.CollectionRequest{
+			Name:      "get-user",
+			Protocol:  utils.ProtocolGraphQL,
+			URL:       "https://api.example.com/graphql",
+			Query:     "query GetUser($id: ID!) { user(id: $id) { name } }",
+			Variables: map[string]any{"id": "1"},
+			Headers:   map[string]string{"Authorization": "Bearer token"},
+		}
+		if err := utils.SaveCollectionRequest("users", req); err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+
+		got, err := utils.LoadCollectionRequest("users", "get-user")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if got.Query != req.Query {
+			t.Errorf("got: %s, want: %s", got.Query, req.Query)
+		}
+		if got.Variables["id"] != "1" {
+			t.Errorf("got: %v, want: 1", got.Variables["id"])
+		}
+		if got.Headers["Authorization"] != "Bearer token" {
+			t.Errorf("got: %s, want: Bearer token", got.Headers["Authorization"])
+		}
+	})
+
+	t.Run("test HTTP method defaults to GET", func(t *testing.T) {
+		req := &utils.CollectionRequest{Name: "list", Protocol: utils.ProtocolHTTP, URL: "https://example.com"}
+		if err := utils.SaveCollectionRequest("users", req); err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		got, err := utils.LoadCollectionRequest("users", "list")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if got.Method != "GET" {
+			t.Errorf("got: %s, want: GET", got.Method)
+		}
+	})
+
+	t.Run("test list collections and requests", func(t *testing.T) {
+		collections, err := utils.ListCollections()
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if !slices.Equal(collections, []string{"users"}) {
+			t.Errorf("got: %v, want: [users]", collections)
+		}
+
+		requests, err := utils.ListCollectionRequests("users")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if !slices.Equal(requests, []string{"get-user", "list"}) {
+			t.Errorf("got: %v, want: [get-user list]", requests)
+		}
+	})
+
+	t.Run("test load JSON request from a path", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "api")
+		os.MkdirAll(dir, 0o755)
+		os.WriteFile(filepath.Join(dir, "create.json"), []byte(`{"protocol":"grpc","url":"localhost:50051","service":"UserService","method":"CreateUser","body":"{\"name\":\"John\"}"}`), 0o644)
+
+		collection, name, err := utils.SplitCollectionRef(dir + "/create")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		got, err := utils.LoadCollectionRequest(collection, name)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if got.Name != "create" || got.Service != "UserService" {
+			t.Errorf("got: %+v", got)
+		}
+	})
+
+	t.Run("test split request references", func(t *testing.T) {
+		tests := []struct {
+			ref, collection, request string
+		}{
+			{"users/list", "users", "list"},
+			{"./api/create", "./api", "create"},
+			{"../api/create", "../api", "create"},
+			{"/tmp/api/create", "/tmp/api", "create"},
+		}
+		for _, tt := range tests {
+			collection, request, err := utils.SplitCollectionRef(tt.ref)
+			if err != nil {
+				t.Errorf("got an error for %q: %s", tt.ref, err.Error())
+				continue
+			}
+			if collection != tt.collection || request != tt.request {
+				t.Errorf("got: %s, %s for %q, want: %s, %s", collection, request, tt.ref, tt.collection, tt.request)
+			}
+		}
+
+		for _, ref := range []string{"users", "users/", "/list", "users/nested/list", "./api", "../api", "/api", "./api/"} {
+			if _, _, err := utils.SplitCollectionRef(ref); err == nil {
+				t.Errorf("got no error for %q", ref)
+			}
+		}
+		if _, err := utils.CollectionPath(`users\list`); err == nil {
+			t.Error("expected an error for a collection name with a path separator")
+		}
+	})
+
+	t.Run("test invalid request is rejected", func(t *testing.T) {
+		req := &utils.CollectionRequest{Name: "broken", Protocol: utils.ProtocolGRPC, URL: "localhost:50051"}
+		if err := utils.SaveCollectionRequest("users", req); err == nil {
+			t.Error("expected an error for a gRPC request without service and method")
+		}
+	})
+
+	t.Run("test remove request and collection", func(t *testing.T) {
+		if err := utils.RemoveCollectionRequest("users", "list"); err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if _, err := utils.LoadCollectionRequest("users", "list"); err == nil {
+			t.Error("expected removed request to be gone")
+		}
+		if err := utils.RemoveCollection("users"); err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		collections, _ := utils.ListCollections()
+		if len(collections) != 0 {
+			t.Errorf("got: %v, want no collections", collections)
+		}
+	})
+
+	t.Run("test remove collection from a path", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "api")
+		os.MkdirAll(dir, 0o755)
+		os.WriteFile(filepath.Join(dir, "create.json"), []byte(`{"protocol":"http","url":"http://localhost"}`), 0o644)
+		os.WriteFile(filepath.Join(dir, "list.yaml"), []byte("protocol: http\nurl: http://localhost\n"), 0o644)
+
+		if err := utils.RemoveCollection(dir); err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", dir)
+		}
+	})
+
+	t.Run("test directory that is not a collection survives", func(t *testing.T) {
+		dir := t.TempDir()
+		os.MkdirAll(filepath.Join(dir, "src"), 0o755)
+		os.WriteFile(filepath.Join(dir, "create.json"), []byte(`{}`), 0o644)
+		os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644)
+
+		for _, path := range []string{dir, filepath.Join(dir, "src") + "/..", "/", ".", "..", "./"} {
+			if err := utils.RemoveCollection(path); err == nil {
+				t.Errorf("got no error removing %q", path)
+			}
+		}
+		for _, name := range []string{"src", "create.json", "main.go"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Errorf("expected %s to survive: %s", name, err.Error())
+			}
+		}
+	})
+}
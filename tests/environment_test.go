@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+func TestEnvironments(t *testing.T) {
+	t.Setenv("HULAKI_HOME", t.TempDir())
+
+	t.Run("test create, set and load environment", func(t *testing.T) {
+		env, err := utils.CreateEnvironment("staging")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		env.Variables["baseUrl"] = "https://staging.example.com"
+		env.Variables["token"] = "abc123"
+		if err := utils.SaveEnvironment(env); err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+
+		got, err := utils.LoadEnvironment("staging")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if got.Variables["token"] != "abc123" {
+			t.Errorf("got: %s, want: abc123", got.Variables["token"])
+		}
+
+		if _, err := utils.CreateEnvironment("staging"); err == nil {
+			t.Error("expected an error creating an existing environment")
+		}
+	})
+
+	t.Run("test interpolate", func(t *testing.T) {
+		env, _ := utils.LoadEnvironment("staging")
+		got, err := env.Interpolate("{{baseUrl}}/users?token={{ token }}")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		want := "https://staging.example.com/users?token=abc123"
+		if got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	})
+
+	t.Run("test interpolate map and values", func(t *testing.T) {
+		env, _ := utils.LoadEnvironment("staging")
+		headers, err := env.InterpolateMap(map[string]string{"Authorization": "Bearer {{token}}"})
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if headers["Authorization"] != "Bearer abc123" {
+			t.Errorf("got: %s, want: Bearer abc123", headers["Authorization"])
+		}
+
+		value, err := env.InterpolateValue(map[string]any{"input": map[string]any{"tokens": []any{"{{token}}", 1.0}}})
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		tokens := value.(map[string]any)["input"].(map[string]any)["tokens"].([]any)
+		if tokens[0] != "abc123" || tokens[1] != 1.0 {
+			t.Errorf("got: %v", tokens)
+		}
+	})
+
+	t.Run("test unknown variable", func(t *testing.T) {
+		env, _ := utils.LoadEnvironment("staging")
+		_, err := env.Interpolate("{{baseUrl}}/{{missing}}")
+		if err == nil || !strings.Contains(err.Error(), "missing") {
+			t.Errorf("expected unknown variable error, got: %v", err)
+		}
+
+	})
+
+	t.Run("test no environment selected", func(t *testing.T) {
+		var none *utils.Environment
+		if _, err := none.Interpolate("{{baseUrl}}/users"); err == nil || !strings.Contains(err.Error(), "no environment selected") {
+			t.Errorf("expected an error without an environment, got: %v", err)
+		}
+		if _, err := none.InterpolateMap(map[string]string{"Authorization": "Bearer {{token}}"}); err == nil {
+			t.Error("expected an error for a header without an environment")
+		}
+		got, err := none.Interpolate(`{"a":{"b":1}}`)
+		if err != nil || got != `{"a":{"b":1}}` {
+			t.Errorf("got: %s, %v", got, err)
+		}
+	})
+
+	t.Run("test list and remove environments", func(t *testing.T) {
+		envs, err := utils.ListEnvironments()
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if len(envs) != 1 || envs[0] != "staging" {
+			t.Errorf("got: %v, want: [staging]", envs)
+		}
+		if err := utils.RemoveEnvironment("staging"); err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if _, err := utils.LoadEnvironment("staging"); err == nil {
+			t.Error("expected removed environment to be gone")
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/suryanshu-09/hulaki/app"
+	"github.com/suryanshu-09/hulaki/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// connCounter counts the connections a server accepts.
+type connCounter struct {
+	conns atomic.Int32
+}
+
+func (c *connCounter) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (c *connCounter) HandleRPC(context.Context, stats.RPCStats) {}
+
+func (c *connCounter) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (c *connCounter) HandleConn(_ context.Context, s stats.ConnStats) {
+	if _, ok := s.(*stats.ConnBegin); ok {
+		c.conns.Add(1)
+	}
+}
+
+var (
+	keyUp    = tea.KeyPressMsg{Code: tea.KeyUp}
+	keyDown  = tea.KeyPressMsg{Code: tea.KeyDown}
+	keyLeft  = tea.KeyPressMsg{Code: tea.KeyLeft}
+	keyEnter = tea.KeyPressMsg{Code: tea.KeyEnter}
+	keySend  = tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl}
+	keyQuit  = tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl}
+)
+
+// explorerView renders the explorer as plain text.
+func explorerView(ge *app.GRPCExplorer) string {
+	return ansi.Strip(ge.View())
+}
+
+// selectedRow returns the tree line under the cursor.
+func selectedRow(ge *app.GRPCExplorer) string {
+	for line := range strings.Lines(explorerView(ge)) {
+		if _, row, ok := strings.Cut(line, "› "); ok {
+			return strings.TrimSpace(strings.Split(row, "│")[0])
+		}
+	}
+	return ""
+}
+
+// runCmd runs the command returned by Update and feeds its message back.
+// Commands that only blink the cursor are never returned for the keys these
+// tests press, except when the editor takes focus, which is ignored.
+func runCmd(t *testing.T, ge *app.GRPCExplorer, cmd tea.Cmd) tea.Cmd {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("got no command")
+	}
+	_, next := ge.Update(cmd())
+	return next
+}
+
+func TestGRPCExplorer(t *testing.T) {
+	counter := &connCounter{}
+	addr := startEchoServer(t, echoServerOptions{reflectionV1: true, serverOptions: []grpc.ServerOption{grpc.StatsHandler(counter)}})
+
+	ge := app.NewGRPCExplorer(addr, nil, nil)
+	defer ge.Close()
+	ge.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+	runCmd(t, ge, ge.Init())
+
+	t.Run("test services are listed", func(t *testing.T) {
+		view := explorerView(ge)
+		for _, service := range []string{"grpc.reflection.v1.ServerReflection", "hulaki.test.EchoService"} {
+			if !strings.Contains(view, service) {
+				t.Errorf("got view without %s:\n%s", service, view)
+			}
+		}
+		if got := selectedRow(ge); !strings.Contains(got, "grpc.reflection.v1.ServerReflection") {
+			t.Errorf("got selected row: %q, want the first service", got)
+		}
+	})
+
+	t.Run("test move and expand a service", func(t *testing.T) {
+		ge.Update(keyDown)
+		ge.Update(keyDown)
+		if got := selectedRow(ge); !strings.Contains(got, "hulaki.test.EchoService") {
+			t.Fatalf("got selected row: %q, want the last service", got)
+		}
+		ge.Update(keyUp)
+		ge.Update(keyDown)
+
+		_, cmd := ge.Update(keyEnter)
+		runCmd(t, ge, cmd)
+		view := explorerView(ge)
+		for _, method := range []string{"Echo", "ServerStream", "(server streaming)", "ClientStream", "(client streaming)", "Bidi", "(bidirectional streaming)"} {
+			if !strings.Contains(view, method) {
+				t.Errorf("got view without %s:\n%s", method, view)
+			}
+		}
+	})
+
+	t.Run("test collapse and expand again", func(t *testing.T) {
+		ge.Update(keyDown)
+		if got := selectedRow(ge); got != "Echo" {
+			t.Fatalf("got selected row: %q, want Echo", got)
+		}
+		ge.Update(keyLeft)
+		if got := selectedRow(ge); !strings.Contains(got, "hulaki.test.EchoService") {
+			t.Errorf("got selected row: %q, want the service of the method", got)
+		}
+		ge.Update(keyLeft)
+		if strings.Contains(explorerView(ge), "ServerStream") {
+			t.Error("got methods of a collapsed service")
+		}
+		if _, cmd := ge.Update(keyEnter); cmd != nil {
+			t.Error("got a command for a service whose methods are loaded")
+		}
+		if !strings.Contains(explorerView(ge), "ServerStream") {
+			t.Error("got no methods after expanding the service again")
+		}
+	})
+
+	t.Run("test select a method and send its request unedited", func(t *testing.T) {
+		ge.Update(keyDown)
+		_, cmd := ge.Update(keyEnter)
+		runCmd(t, ge, cmd)
+
+		view := explorerView(ge)
+		if !strings.Contains(view, "hulaki.test.EchoService/Echo · unary") {
+			t.Errorf("got view without the selected method:\n%s", view)
+		}
+		if !strings.Contains(view, `"text": ""`) || strings.Contains(view, `"number"`) || strings.Contains(view, "// oneof") {
+			t.Errorf("got request without only the first field of the oneof:\n%s", view)
+		}
+
+		_, cmd = ge.Update(keySend)
+		for cmd != nil {
+			cmd = runCmd(t, ge, cmd)
+		}
+		view = explorerView(ge)
+		if !strings.Contains(view, "OK ·") {
+			t.Errorf("got view without a successful call:\n%s", view)
+		}
+	})
+
+	t.Run("test one connection for every action", func(t *testing.T) {
+		if got := counter.conns.Load(); got != 1 {
+			t.Errorf("got %d connections, want 1", got)
+		}
+	})
+}
+
+// selectEcho selects EchoService/Echo and replaces its request with request.
+func selectEcho(t *testing.T, ge *app.GRPCExplorer, request string) {
+	t.Helper()
+	ge.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+	runCmd(t, ge, ge.Init())
+	ge.Update(keyDown)
+	_, cmd := ge.Update(keyEnter)
+	runCmd(t, ge, cmd)
+	ge.Update(keyDown)
+	_, cmd = ge.Update(keyEnter)
+	runCmd(t, ge, cmd)
+
+	ge.Update(tea.KeyPressMsg{Code: tea.KeyEnd, Mod: tea.ModCtrl})
+	for range 500 {
+		ge.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	}
+	typeText(ge, request)
+}
+
+func TestGRPCExplorerCalls(t *testing.T) {
+	addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+
+	t.Run("test environment values are escaped", func(t *testing.T) {
+		env := &utils.Environment{Name: "test", Variables: map[string]string{"message": `say "hi" \ bye`}}
+		ge := app.NewGRPCExplorer(addr, env, nil)
+		defer ge.Close()
+		selectEcho(t, ge, `{"message": "{{message}}"}`)
+
+		_, cmd := ge.Update(keySend)
+		for cmd != nil {
+			cmd = runCmd(t, ge, cmd)
+		}
+		view := explorerView(ge)
+		if !strings.Contains(view, "OK ·") || !strings.Contains(view, `"message": "say \"hi\" \\ bye"`) {
+			t.Errorf("got view without the interpolated message:\n%s", view)
+		}
+	})
+
+	t.Run("test quitting cancels the call in progress", func(t *testing.T) {
+		ge := app.NewGRPCExplorer(addr, nil, nil)
+		defer ge.Close()
+		selectEcho(t, ge, `{"message": "hang"}`)
+
+		_, cmd := ge.Update(keySend)
+		if cmd == nil {
+			t.Fatal("got no command")
+		}
+		ge.Update(keyQuit)
+
+		done := make(chan tea.Msg)
+		go func() { done <- cmd() }()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("the call is still waiting after quitting")
+		}
+	})
+}
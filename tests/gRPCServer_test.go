@@ -0,0 +1,235 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	rpbalpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// echoProto describes the service the in-process test server implements.
+const echoProto = `
+name: "hulaki/test/echo.proto"
+package: "hulaki.test"
+syntax: "proto3"
+message_type {
+  name: "EchoRequest"
+  field { name: "message" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "message" }
+  field { name: "count" number: 2 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "count" }
+  field { name: "tags" number: 3 label: LABEL_REPEATED type: TYPE_STRING json_name: "tags" }
+  field { name: "inner" number: 4 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".hulaki.test.Inner" json_name: "inner" }
+  field { name: "color" number: 5 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".hulaki.test.Color" json_name: "color" }
+  field { name: "text" number: 6 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0 json_name: "text" }
+  field { name: "number" number: 7 label: LABEL_OPTIONAL type: TYPE_INT64 oneof_index: 0 json_name: "number" }
+  oneof_decl { name: "choice" }
+}
+message_type {
+  name: "Inner"
+  field { name: "flag" number: 1 label: LABEL_OPTIONAL type: TYPE_BOOL json_name: "flag" }
+}
+message_type {
+  name: "EchoResponse"
+  field { name: "message" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "message" }
+  field { name: "count" number: 2 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "count" }
+}
+enum_type {
+  name: "Color"
+  value { name: "RED" number: 0 }
+  value { name: "GREEN" number: 1 }
+}
+service {
+  name: "EchoService"
+  method { name: "Echo" input_type: ".hulaki.test.EchoRequest" output_type: ".hulaki.test.EchoResponse" }
+  method { name: "ServerStream" input_type: ".hulaki.test.EchoRequest" output_type: ".hulaki.test.EchoResponse" server_streaming: true }
+  method { name: "ClientStream" input_type: ".hulaki.test.EchoRequest" output_type: ".hulaki.test.EchoResponse" client_streaming: true }
+  method { name: "Bidi" input_type: ".hulaki.test.EchoRequest" output_type: ".hulaki.test.EchoResponse" client_streaming: true server_streaming: true }
+}
+`
+
+var echoFile = func() protoreflect.FileDescriptor {
+	fdp := &descriptorpb.FileDescriptorProto{}
+	if err := prototext.Unmarshal([]byte(echoProto), fdp); err != nil {
+		panic(err)
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(err)
+	}
+	return fd
+}()
+
+type echoServerOptions struct {
+	reflectionV1      bool
+	reflectionV1alpha bool
+	health            *health.Server
+	serverOptions     []grpc.ServerOption
+}
+
+// startEchoServer runs the echo service on a random local port and returns
+// its address. The server is stopped when the test finishes.
+func startEchoServer(t *testing.T, opts echoServerOptions) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err.Error())
+	}
+
+	s := grpc.NewServer(opts.serverOptions...)
+	s.RegisterService(echoServiceDesc(), struct{}{})
+
+	files := &protoregistry.Files{}
+	files.RegisterFile(echoFile)
+	reflectionOpts := reflection.ServerOptions{Services: s, DescriptorResolver: files}
+	if opts.reflectionV1 {
+		rpb.RegisterServerReflectionServer(s, reflection.NewServerV1(reflectionOpts))
+	}
+	if opts.reflectionV1alpha {
+		rpbalpha.RegisterServerReflectionServer(s, reflection.NewServer(reflectionOpts))
+	}
+
+	if opts.health != nil {
+		healthpb.RegisterHealthServer(s, opts.health)
+	}
+
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func echoMessage(name string) protoreflect.MessageDescriptor {
+	return echoFile.Messages().ByName(protoreflect.Name(name))
+}
+
+func echoServiceDesc() *grpc.ServiceDesc {
+	req := echoMessage("EchoRequest")
+	res := echoMessage("EchoResponse")
+
+	echo := func(in *dynamicpb.Message) *dynamicpb.Message {
+		out := dynamicpb.NewMessage(res)
+		out.Set(res.Fields().ByName("message"), in.Get(req.Fields().ByName("message")))
+		out.Set(res.Fields().ByName("count"), in.Get(req.Fields().ByName("count")))
+		return out
+	}
+
+	return &grpc.ServiceDesc{
+		ServiceName: "hulaki.test.EchoService",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Echo",
+				Handler: func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+					in := dynamicpb.NewMessage(req)
+					if err := dec(in); err != nil {
+						return nil, err
+					}
+					grpc.SetHeader(ctx, metadata.Pairs("x-echo-header", "header"))
+					grpc.SetTrailer(ctx, metadata.Pairs("x-echo-trailer", "trailer"))
+					switch in.Get(req.Fields().ByName("message")).String() {
+					case "details":
+						st, _ := status.New(codes.InvalidArgument, "message is invalid").WithDetails(
+							&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+								{Field: "message", Description: "must not be details"},
+							}},
+							&errdetails.ErrorInfo{Reason: "BAD_MESSAGE", Domain: "hulaki.test"},
+							&errdetails.RetryInfo{RetryDelay: durationpb.New(2 * time.Second)},
+						)
+						return nil, st.Err()
+					case "fail":
+						return nil, status.Error(codes.InvalidArgument, "message cannot be fail")
+					case "cancel":
+						return nil, status.Error(codes.Canceled, "message was cancelled")
+					case "hang":
+						// Never answers; the call ends when the client gives up.
+						<-ctx.Done()
+						return nil, status.FromContextError(ctx.Err()).Err()
+					}
+					return echo(in), nil
+				},
+			},
+		},
+		Streams: []grpc.StreamDesc{
+			{
+				// ServerStream replies count times with the request message.
+				StreamName:    "ServerStream",
+				ServerStreams: true,
+				Handler: func(_ any, stream grpc.ServerStream) error {
+					in := dynamicpb.NewMessage(req)
+					if err := stream.RecvMsg(in); err != nil {
+						return err
+					}
+					count := in.Get(req.Fields().ByName("count")).Int()
+					for i := range count {
+						out := echo(in)
+						out.Set(res.Fields().ByName("count"), protoreflect.ValueOfInt32(int32(i+1)))
+						if err := stream.SendMsg(out); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+			{
+				// ClientStream joins every request message and counts them.
+				StreamName:    "ClientStream",
+				ClientStreams: true,
+				Handler: func(_ any, stream grpc.ServerStream) error {
+					var messages []string
+					for {
+						in := dynamicpb.NewMessage(req)
+						if err := stream.RecvMsg(in); err == io.EOF {
+							break
+						} else if err != nil {
+							return err
+						}
+						messages = append(messages, in.Get(req.Fields().ByName("message")).String())
+					}
+					out := dynamicpb.NewMessage(res)
+					out.Set(res.Fields().ByName("message"), protoreflect.ValueOfString(strings.Join(messages, " ")))
+					out.Set(res.Fields().ByName("count"), protoreflect.ValueOfInt32(int32(len(messages))))
+					return stream.SendMsg(out)
+				},
+			},
+			{
+				// Bidi echoes every request message as it arrives.
+				StreamName:    "Bidi",
+				ClientStreams: true,
+				ServerStreams: true,
+				Handler: func(_ any, stream grpc.ServerStream) error {
+					for {
+						in := dynamicpb.NewMessage(req)
+						if err := stream.RecvMsg(in); err == io.EOF {
+							return nil
+						} else if err != nil {
+							return err
+						}
+						if err := stream.SendMsg(echo(in)); err != nil {
+							return err
+						}
+					}
+				},
+			},
+		},
+		Metadata: echoFile.Path(),
+	}
+}
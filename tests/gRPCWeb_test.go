@@ -0,0 +1,378 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/suryanshu-09/hulaki/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// echoDescriptors writes the echo service to a protoset and loads it, as
+// gRPC-Web and Connect calls cannot use server reflection.
+func echoDescriptors(t *testing.T) utils.DescriptorSource {
+	t.Helper()
+
+	protoset := filepath.Join(t.TempDir(), "echo.protoset")
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(echoFile)}}
+	data, _ := proto.Marshal(set)
+	os.WriteFile(protoset, data, 0o644)
+
+	src, err := utils.LoadDescriptorSource(nil, nil, []string{protoset})
+	if err != nil {
+		t.Fatalf("failed to load descriptors: %s", err.Error())
+	}
+	return src
+}
+
+func writeTestFrame(w io.Writer, flags byte, payload []byte) {
+	var prefix [5]byte
+	prefix[0] = flags
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(payload)))
+	w.Write(prefix[:])
+	w.Write(payload)
+}
+
+func readTestFrames(r io.Reader) [][]byte {
+	var frames [][]byte
+	for {
+		var prefix [5]byte
+		if _, err := io.ReadFull(r, prefix[:]); err != nil {
+			return frames
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+		io.ReadFull(r, payload)
+		frames = append(frames, payload)
+	}
+}
+
+func decodeEchoRequest(payload []byte) *dynamicpb.Message {
+	in := dynamicpb.NewMessage(echoMessage("EchoRequest"))
+	proto.Unmarshal(payload, in)
+	return in
+}
+
+func encodeEchoResponse(message string, count int32) []byte {
+	res := echoMessage("EchoResponse")
+	out := dynamicpb.NewMessage(res)
+	out.Set(res.Fields().ByName("message"), protoreflect.ValueOfString(message))
+	out.Set(res.Fields().ByName("count"), protoreflect.ValueOfInt32(count))
+	data, _ := proto.Marshal(out)
+	return data
+}
+
+func echoRequestField(in *dynamicpb.Message, name string) protoreflect.Value {
+	return in.Get(echoMessage("EchoRequest").Fields().ByName(protoreflect.Name(name)))
+}
+
+// startGRPCWebServer serves the echo service over gRPC-Web, as a proxy
+// such as Envoy would.
+func startGRPCWebServer(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/grpc-web+proto" {
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		frames := readTestFrames(r.Body)
+		if len(frames) != 1 {
+			http.Error(w, "expected a single message", http.StatusBadRequest)
+			return
+		}
+		in := decodeEchoRequest(frames[0])
+		message := echoRequestField(in, "message").String()
+
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		w.Header().Set("X-Echo-Header", "header")
+		w.Header().Set("X-Echo-Authorization", r.Header.Get("Authorization"))
+
+		switch {
+		case message == "missing":
+			// What a proxy without a route for the method would answer.
+			http.NotFound(w, r)
+		case r.URL.Path == "/hulaki.test.EchoService/Echo" && message == "fail":
+			// A trailers-only response.
+			w.Header().Set("Grpc-Status", fmt.Sprint(int(codes.InvalidArgument)))
+			w.Header().Set("Grpc-Message", "message%20cannot%20be%20fail")
+			return
+		case r.URL.Path == "/hulaki.test.EchoService/Echo" && message == "details":
+			st, _ := status.New(codes.InvalidArgument, "message is invalid").WithDetails(
+				&errdetails.ErrorInfo{Reason: "BAD_MESSAGE", Domain: "hulaki.test"},
+			)
+			details, _ := proto.Marshal(st.Proto())
+			trailer := fmt.Sprintf("grpc-status: %d\r\ngrpc-message: message is invalid\r\ngrpc-status-details-bin: %s\r\n",
+				codes.InvalidArgument, base64.RawStdEncoding.EncodeToString(details))
+			writeTestFrame(w, 0x80, []byte(trailer))
+		case r.URL.Path == "/hulaki.test.EchoService/Echo":
+			writeTestFrame(w, 0, encodeEchoResponse(message, int32(echoRequestField(in, "count").Int())))
+			writeTestFrame(w, 0x80, []byte("grpc-status: 0\r\ngrpc-message: \r\nx-echo-trailer: trailer\r\n"))
+		case r.URL.Path == "/hulaki.test.EchoService/ServerStream":
+			for i := range echoRequestField(in, "count").Int() {
+				writeTestFrame(w, 0, encodeEchoResponse(message, int32(i+1)))
+			}
+			writeTestFrame(w, 0x80, []byte("grpc-status: 0\r\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// startConnectServer serves the echo service over the Connect protocol.
+func startConnectServer(t *testing.T) string {
+	t.Helper()
+
+	endStream := func(w io.Writer, end map[string]any) {
+		data, _ := json.Marshal(end)
+		writeTestFrame(w, 0x02, data)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Connect-Protocol-Version") != "1" {
+			http.Error(w, "missing protocol version", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("X-Echo-Header", "header")
+
+		switch r.URL.Path {
+		case "/hulaki.test.EchoService/Echo":
+			if r.Header.Get("Content-Type") != "application/proto" {
+				http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			in := decodeEchoRequest(body)
+			message := echoRequestField(in, "message").String()
+			w.Header().Set("Trailer-X-Echo-Trailer", "trailer")
+			if message == "fail" {
+				detail, _ := proto.Marshal(&errdetails.ErrorInfo{Reason: "BAD_MESSAGE", Domain: "hulaki.test"})
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]any{
+					"code":    "invalid_argument",
+					"message": "message cannot be fail",
+					"details": []map[string]string{
+						{"type": "google.rpc.ErrorInfo", "value": base64.RawStdEncoding.EncodeToString(detail)},
+					},
+				})
+				return
+			}
+			w.Header().Set("Content-Type", "application/proto")
+			w.Write(encodeEchoResponse(message, int32(echoRequestField(in, "count").Int())))
+		case "/hulaki.test.EchoService/ServerStream":
+			frames := readTestFrames(r.Body)
+			in := decodeEchoRequest(frames[0])
+			message := echoRequestField(in, "message").String()
+			w.Header().Set("Content-Type", "application/connect+proto")
+			if message == "fail" {
+				endStream(w, map[string]any{"error": map[string]any{"code": "canceled", "message": "stream canceled"}})
+				return
+			}
+			for i := range echoRequestField(in, "count").Int() {
+				writeTestFrame(w, 0, encodeEchoResponse(message, int32(i+1)))
+			}
+			endStream(w, map[string]any{"metadata": map[string][]string{"x-echo-trailer": {"trailer"}}})
+		case "/hulaki.test.EchoService/ClientStream":
+			var messages []string
+			for _, frame := range readTestFrames(r.Body) {
+				messages = append(messages, echoRequestField(decodeEchoRequest(frame), "message").String())
+			}
+			w.Header().Set("Content-Type", "application/connect+proto")
+			writeTestFrame(w, 0, encodeEchoResponse(strings.Join(messages, " "), int32(len(messages))))
+			endStream(w, map[string]any{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestGRPCWeb(t *testing.T) {
+	addr := startGRPCWebServer(t)
+	src := echoDescriptors(t)
+	web := utils.WithGRPCProtocol(utils.ProtocolGRPCWeb)
+
+	t.Run("test unary call", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"message":"hi","count":2}`)
+		resp, err := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", web, utils.WithDescriptorSource(src), utils.WithBody(body),
+			utils.WithHeaders(map[string]string{"authorization": "Bearer token"}))
+		if err != nil {
+			t.Fatalf("GRPCCall should not return error, got: %s", err.Error())
+		}
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		data := resp.Data.(map[string]any)
+		if data["message"] != "hi" || data["count"] != json.Number("2") {
+			t.Errorf("got: %v", resp.Data)
+		}
+		if got := resp.Metadata.Get("x-echo-authorization"); !slices.Equal(got, []string{"Bearer token"}) {
+			t.Errorf("headers were not sent: %v", resp.Metadata)
+		}
+		if got := resp.Trailers.Get("x-echo-trailer"); !slices.Equal(got, []string{"trailer"}) {
+			t.Errorf("got trailers: %v", resp.Trailers)
+		}
+		if len(resp.Trailers.Get("grpc-status")) != 0 {
+			t.Errorf("status leaked into trailers: %v", resp.Trailers)
+		}
+	})
+
+	t.Run("test server streaming", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"message":"tick","count":3}`)
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "ServerStream", web, utils.WithDescriptorSource(src), utils.WithBody(body))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if messages := resp.Data.([]any); len(messages) != 3 {
+			t.Errorf("got %d messages, want 3", len(messages))
+		}
+	})
+
+	t.Run("test trailers-only error", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"message":"fail"}`)
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", web, utils.WithDescriptorSource(src), utils.WithBody(body))
+		if resp.Error == nil || resp.Error.Code != "INVALID_ARGUMENT" {
+			t.Fatalf("expected INVALID_ARGUMENT, got: %v", resp.Error)
+		}
+		if resp.Error.Message != "message cannot be fail" {
+			t.Errorf("got message: %q", resp.Error.Message)
+		}
+	})
+
+	t.Run("test status details", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"message":"details"}`)
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", web, utils.WithDescriptorSource(src), utils.WithBody(body))
+		if resp.Error == nil || len(resp.Error.Details) != 1 {
+			t.Fatalf("expected an error with details, got: %v", resp.Error)
+		}
+		if reason := resp.Error.Details[0].(map[string]any)["reason"]; reason != "BAD_MESSAGE" {
+			t.Errorf("got details: %v", resp.Error.Details)
+		}
+	})
+
+	t.Run("test HTTP error", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"message":"missing"}`)
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", web, utils.WithDescriptorSource(src), utils.WithBody(body))
+		if resp.Error == nil || resp.Error.Code != "UNIMPLEMENTED" {
+			t.Errorf("expected UNIMPLEMENTED, got: %v", resp.Error)
+		}
+	})
+
+	t.Run("test client streaming", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "ClientStream", web, utils.WithDescriptorSource(src))
+		if resp.Error == nil || resp.Error.Code != "UNIMPLEMENTED" {
+			t.Errorf("expected UNIMPLEMENTED, got: %v", resp.Error)
+		}
+	})
+
+	t.Run("test without descriptors", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", web)
+		if resp.Error == nil || resp.Error.Code != "FAILED_PRECONDITION" {
+			t.Errorf("expected FAILED_PRECONDITION, got: %v", resp.Error)
+		}
+	})
+}
+
+func TestGRPCConnect(t *testing.T) {
+	addr := startConnectServer(t)
+	src := echoDescriptors(t)
+	connect := utils.WithGRPCProtocol(utils.ProtocolConnect)
+
+	t.Run("test unary call", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"message":"hi"}`)
+		resp, err := utils.GRPCCall(addr, "EchoService", "Echo", connect, utils.WithDescriptorSource(src), utils.WithBody(body))
+		if err != nil {
+			t.Fatalf("GRPCCall should not return error, got: %s", err.Error())
+		}
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if resp.Data.(map[string]any)["message"] != "hi" {
+			t.Errorf("got: %v", resp.Data)
+		}
+		if got := resp.Metadata.Get("x-echo-header"); !slices.Equal(got, []string{"header"}) {
+			t.Errorf("got headers: %v", resp.Metadata)
+		}
+		if got := resp.Trailers.Get("x-echo-trailer"); !slices.Equal(got, []string{"trailer"}) {
+			t.Errorf("got trailers: %v", resp.Trailers)
+		}
+	})
+
+	t.Run("test unary error", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"message":"fail"}`)
+		resp, _ := utils.GRPCCall(addr, "EchoService", "Echo", connect, utils.WithDescriptorSource(src), utils.WithBody(body))
+		if resp.Error == nil || resp.Error.Code != "INVALID_ARGUMENT" {
+			t.Fatalf("expected INVALID_ARGUMENT, got: %v", resp.Error)
+		}
+		if len(resp.Error.Details) != 1 {
+			t.Fatalf("got %d details, want 1", len(resp.Error.Details))
+		}
+		if reason := resp.Error.Details[0].(map[string]any)["reason"]; reason != "BAD_MESSAGE" {
+			t.Errorf("got details: %v", resp.Error.Details)
+		}
+	})
+
+	t.Run("test server streaming", func(t *testing.T) {
+		var received []any
+		body := bytes.NewBufferString(`{"message":"tick","count":3}`)
+		resp, _ := utils.GRPCStreamCall(addr, "EchoService", "ServerStream", utils.JSONRequestSource(body), func(msg any) {
+			received = append(received, msg)
+		}, connect, utils.WithDescriptorSource(src))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if len(received) != 3 {
+			t.Errorf("got %d messages, want 3", len(received))
+		}
+		if got := resp.Trailers.Get("x-echo-trailer"); !slices.Equal(got, []string{"trailer"}) {
+			t.Errorf("got trailers: %v", resp.Trailers)
+		}
+	})
+
+	t.Run("test stream error", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"message":"fail"}`)
+		resp, _ := utils.GRPCCall(addr, "EchoService", "ServerStream", connect, utils.WithDescriptorSource(src), utils.WithBody(body))
+		if resp.Error == nil || resp.Error.Code != "CANCELLED" {
+			t.Errorf("expected CANCELLED, got: %v", resp.Error)
+		}
+	})
+
+	t.Run("test client streaming", func(t *testing.T) {
+		body := bytes.NewBufferString("{\"message\":\"a\"}\n{\"message\":\"b\"}")
+		resp, _ := utils.GRPCCall(addr, "EchoService", "ClientStream", connect, utils.WithDescriptorSource(src), utils.WithBody(body))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if data := resp.Data.(map[string]any); data["message"] != "a b" || data["count"] != json.Number("2") {
+			t.Errorf("got: %v", resp.Data)
+		}
+	})
+
+	t.Run("test bidirectional streaming", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "EchoService", "Bidi", connect, utils.WithDescriptorSource(src))
+		if resp.Error == nil || resp.Error.Code != "UNIMPLEMENTED" {
+			t.Errorf("expected UNIMPLEMENTED, got: %v", resp.Error)
+		}
+	})
+}
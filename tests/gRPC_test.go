@@ -1,9 +1,25 @@
 package tests
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/suryanshu-09/hulaki/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 func TestGRPCCall(t *testing.T) {
@@ -18,8 +34,8 @@ func TestGRPCCall(t *testing.T) {
 			t.Errorf("Expected connection error, got nil")
 		}
 
-		if resp.Error.Code != "CONNECTION_ERROR" && resp.Error.Code != "CONNECTION_FAILED" {
-			t.Errorf("Expected CONNECTION_ERROR or CONNECTION_FAILED, got: %s", resp.Error.Code)
+		if resp.Error.Code != "UNAVAILABLE" {
+			t.Errorf("Expected UNAVAILABLE, got: %s", resp.Error.Code)
 		}
 	})
 }
@@ -36,8 +52,8 @@ func TestGRPCReflect(t *testing.T) {
 			t.Errorf("Expected connection error, got nil")
 		}
 
-		if resp.Error.Code != "CONNECTION_ERROR" && resp.Error.Code != "CONNECTION_FAILED" {
-			t.Errorf("Expected CONNECTION_ERROR or CONNECTION_FAILED, got: %s", resp.Error.Code)
+		if resp.Error.Code != "UNAVAILABLE" {
+			t.Errorf("Expected UNAVAILABLE, got: %s", resp.Error.Code)
 		}
 	})
 }
@@ -74,3 +90,722 @@ func TestGRPCClient(t *testing.T) {
 		client.Close()
 	})
 }
+
+func TestGRPCReflection(t *testing.T) {
+	t.Run("test list services over reflection v1", func(t *testing.T) {
+		addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+		resp, err := utils.GRPCReflect(addr)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s", resp.Error.Message)
+		}
+		services := resp.Data.(map[string]any)["services"].([]string)
+		if !slices.Contains(services, "hulaki.test.EchoService") {
+			t.Errorf("got: %v, want hulaki.test.EchoService", services)
+		}
+	})
+
+	t.Run("test list services over reflection v1alpha", func(t *testing.T) {
+		addr := startEchoServer(t, echoServerOptions{reflectionV1alpha: true})
+		resp, err := utils.GRPCReflect(addr)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s", resp.Error.Message)
+		}
+		services := resp.Data.(map[string]any)["services"].([]string)
+		if !slices.Contains(services, "hulaki.test.EchoService") {
+			t.Errorf("got: %v, want hulaki.test.EchoService", services)
+		}
+	})
+
+	t.Run("test reflection disabled", func(t *testing.T) {
+		addr := startEchoServer(t, echoServerOptions{})
+		resp, _ := utils.GRPCReflect(addr)
+		if resp.Error == nil {
+			t.Error("expected an error when reflection is disabled")
+		}
+	})
+
+	t.Run("test describe service", func(t *testing.T) {
+		addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+		resp, err := utils.GRPCDescribe(addr, "hulaki.test.EchoService")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s", resp.Error.Message)
+		}
+		data := resp.Data.(map[string]any)
+		method := data["methods"].([]any)[0].(map[string]any)
+		if method["method"] != "hulaki.test.EchoService.Echo" || method["request"] != "hulaki.test.EchoRequest" {
+			t.Errorf("got: %v", method)
+		}
+		messages := data["messages"].(map[string]any)
+		for _, name := range []string{"hulaki.test.EchoRequest", "hulaki.test.EchoResponse", "hulaki.test.Inner"} {
+			if _, ok := messages[name]; !ok {
+				t.Errorf("expected message %s in description", name)
+			}
+		}
+	})
+
+	t.Run("test describe method and unknown symbol", func(t *testing.T) {
+		addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+		resp, _ := utils.GRPCDescribe(addr, "hulaki.test.EchoService/Echo")
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s", resp.Error.Message)
+		}
+		if resp.Data.(map[string]any)["response"] != "hulaki.test.EchoResponse" {
+			t.Errorf("got: %v", resp.Data)
+		}
+
+		resp, _ = utils.GRPCDescribe(addr, "hulaki.test.Missing")
+		if resp.Error == nil {
+			t.Error("expected an error for an unknown symbol")
+		}
+	})
+}
+
+func TestGRPCDynamicCall(t *testing.T) {
+	addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+
+	t.Run("test unary round trip", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"message":"hello","count":3,"inner":{"flag":true}}`)
+		resp, err := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(body))
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		data := resp.Data.(map[string]any)
+		if data["message"] != "hello" || fmt.Sprint(data["count"]) != "3" {
+			t.Errorf("got: %v", data)
+		}
+	})
+
+	t.Run("test service without package", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "EchoService", "Echo", utils.WithBody(bytes.NewBufferString(`{"message":"short"}`)))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if resp.Data.(map[string]any)["message"] != "short" {
+			t.Errorf("got: %v", resp.Data)
+		}
+	})
+
+	t.Run("test unknown method", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Missing")
+		if resp.Error == nil || resp.Error.Code != "NOT_FOUND" {
+			t.Errorf("expected NOT_FOUND, got: %v", resp.Error)
+		}
+	})
+
+	t.Run("test invalid request body", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(bytes.NewBufferString(`{"unknown":1}`)))
+		if resp.Error == nil || resp.Error.Code != "INVALID_ARGUMENT" {
+			t.Errorf("expected INVALID_ARGUMENT, got: %v", resp.Error)
+		}
+	})
+
+	t.Run("test server error", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(bytes.NewBufferString(`{"message":"fail"}`)))
+		if resp.Error == nil || resp.Error.Code != "INVALID_ARGUMENT" {
+			t.Fatalf("expected INVALID_ARGUMENT, got: %v", resp.Error)
+		}
+		if resp.Error.Message != "message cannot be fail" {
+			t.Errorf("got: %s", resp.Error.Message)
+		}
+	})
+
+	t.Run("test canonical status code names", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(bytes.NewBufferString(`{"message":"cancel"}`)))
+		if resp.Error == nil || resp.Error.Code != "CANCELLED" {
+			t.Fatalf("expected CANCELLED, got: %v", resp.Error)
+		}
+
+		report, err := utils.GRPCBench(addr, "hulaki.test.EchoService/Echo", utils.JSONRequestSource(strings.NewReader(`{"message":"cancel"}`)), utils.GRPCBenchOptions{Concurrency: 1, Requests: 2})
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if report.StatusCodes["CANCELLED"] != 2 {
+			t.Errorf("got status codes: %v, want CANCELLED: 2", report.StatusCodes)
+		}
+	})
+}
+
+const echoProtoSource = `syntax = "proto3";
+
+package hulaki.test;
+
+import "hulaki/test/types.proto";
+import "google/protobuf/empty.proto";
+
+message EchoRequest {
+  string message = 1;
+  int32 count = 2;
+  repeated string tags = 3;
+  Inner inner = 4;
+  Color color = 5;
+  oneof choice {
+    string text = 6;
+    int64 number = 7;
+  }
+}
+
+message EchoResponse {
+  string message = 1;
+  int32 count = 2;
+}
+
+service EchoService {
+  rpc Echo(EchoRequest) returns (EchoResponse);
+  rpc Ping(google.protobuf.Empty) returns (google.protobuf.Empty);
+}
+`
+
+const typesProtoSource = `syntax = "proto3";
+
+package hulaki.test;
+
+message Inner {
+  bool flag = 1;
+}
+
+enum Color {
+  RED = 0;
+  GREEN = 1;
+}
+`
+
+func TestGRPCLocalDescriptors(t *testing.T) {
+	addr := startEchoServer(t, echoServerOptions{})
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "hulaki", "test"), 0o755)
+	os.WriteFile(filepath.Join(dir, "hulaki", "test", "echo.proto"), []byte(echoProtoSource), 0o644)
+	os.WriteFile(filepath.Join(dir, "hulaki", "test", "types.proto"), []byte(typesProtoSource), 0o644)
+
+	protoset := filepath.Join(dir, "echo.protoset")
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(echoFile)}}
+	data, _ := proto.Marshal(set)
+	os.WriteFile(protoset, data, 0o644)
+
+	t.Run("test call with proto files", func(t *testing.T) {
+		src, err := utils.LoadDescriptorSource([]string{"hulaki/test/echo.proto"}, []string{dir}, nil)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		body := bytes.NewBufferString(`{"message":"from proto"}`)
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithDescriptorSource(src), utils.WithBody(body))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if resp.Data.(map[string]any)["message"] != "from proto" {
+			t.Errorf("got: %v", resp.Data)
+		}
+	})
+
+	t.Run("test call with protoset", func(t *testing.T) {
+		src, err := utils.LoadDescriptorSource(nil, nil, []string{protoset})
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		body := bytes.NewBufferString(`{"message":"from protoset"}`)
+		resp, _ := utils.GRPCCall(addr, "EchoService", "Echo", utils.WithDescriptorSource(src), utils.WithBody(body))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if resp.Data.(map[string]any)["message"] != "from protoset" {
+			t.Errorf("got: %v", resp.Data)
+		}
+	})
+
+	t.Run("test list and describe without a server", func(t *testing.T) {
+		src, err := utils.LoadDescriptorSource([]string{"hulaki/test/echo.proto"}, []string{dir}, nil)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		resp, _ := utils.GRPCReflect("localhost:99999", utils.WithDescriptorSource(src))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		services := resp.Data.(map[string]any)["services"].([]string)
+		if !slices.Equal(services, []string{"hulaki.test.EchoService"}) {
+			t.Errorf("got: %v", services)
+		}
+
+		resp, _ = utils.GRPCDescribe("localhost:99999", "hulaki.test.EchoService.Ping", utils.WithDescriptorSource(src))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if resp.Data.(map[string]any)["request"] != "google.protobuf.Empty" {
+			t.Errorf("got: %v", resp.Data)
+		}
+	})
+
+	t.Run("test invalid proto file", func(t *testing.T) {
+		os.WriteFile(filepath.Join(dir, "broken.proto"), []byte(`syntax = "proto3"; message {`), 0o644)
+		if _, err := utils.LoadDescriptorSource([]string{"broken.proto"}, []string{dir}, nil); err == nil {
+			t.Error("expected a parse error")
+		}
+	})
+}
+
+func TestGRPCStreaming(t *testing.T) {
+	addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+
+	t.Run("test server streaming", func(t *testing.T) {
+		var got []any
+		requests := utils.JSONRequestSource(bytes.NewBufferString(`{"message":"tick","count":3}`))
+		resp, err := utils.GRPCStreamCall(addr, "hulaki.test.EchoService", "ServerStream", requests, func(msg any) {
+			got = append(got, msg)
+		})
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if len(got) != 3 {
+			t.Fatalf("got %d messages, want 3", len(got))
+		}
+		if fmt.Sprint(got[2].(map[string]any)["count"]) != "3" {
+			t.Errorf("got: %v", got[2])
+		}
+	})
+
+	t.Run("test server streaming through GRPCCall", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "ServerStream", utils.WithBody(bytes.NewBufferString(`{"message":"tick","count":2}`)))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if messages := resp.Data.([]any); len(messages) != 2 {
+			t.Errorf("got: %v", messages)
+		}
+	})
+
+	t.Run("test client streaming", func(t *testing.T) {
+		input := "{\"message\":\"a\"}\n{\"message\":\"b\"}\n[{\"message\":\"c\"},{\"message\":\"d\"}]"
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "ClientStream", utils.WithBody(bytes.NewBufferString(input)))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		data := resp.Data.(map[string]any)
+		if data["message"] != "a b c d" || fmt.Sprint(data["count"]) != "4" {
+			t.Errorf("got: %v", data)
+		}
+	})
+
+	t.Run("test bidirectional streaming", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		replies := make(chan any, 10)
+		done := make(chan *utils.GRPCResponse)
+		go func() {
+			resp, _ := utils.GRPCStreamCall(addr, "hulaki.test.EchoService", "Bidi", utils.JSONRequestSource(pr), func(msg any) {
+				replies <- msg
+			})
+			done <- resp
+		}()
+
+		// Each reply arrives before the next request is written.
+		for _, word := range []string{"one", "two"} {
+			fmt.Fprintf(pw, "{\"message\":%q}\n", word)
+			reply := <-replies
+			if reply.(map[string]any)["message"] != word {
+				t.Errorf("got: %v, want: %s", reply, word)
+			}
+		}
+		pw.Close()
+
+		if resp := <-done; resp.Error != nil {
+			t.Errorf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+	})
+
+	t.Run("test invalid streamed message", func(t *testing.T) {
+		input := "{\"message\":\"a\"}\n{\"nope\":true}"
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "ClientStream", utils.WithBody(bytes.NewBufferString(input)))
+		if resp.Error == nil || resp.Error.Code != "INVALID_ARGUMENT" {
+			t.Errorf("expected INVALID_ARGUMENT, got: %v", resp.Error)
+		}
+	})
+}
+
+func TestGRPCTimeouts(t *testing.T) {
+	addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+
+	t.Run("test call deadline", func(t *testing.T) {
+		start := time.Now()
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(bytes.NewBufferString(`{"message":"hang"}`)), utils.WithGRPCTimeout(200*time.Millisecond))
+		if resp.Error == nil || resp.Error.Code != "DEADLINE_EXCEEDED" {
+			t.Fatalf("expected DEADLINE_EXCEEDED, got: %v", resp.Error)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("call took %s", elapsed)
+		}
+	})
+
+	t.Run("test call within the deadline", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(bytes.NewBufferString(`{"message":"quick"}`)), utils.WithGRPCTimeout(5*time.Second))
+		if resp.Error != nil {
+			t.Errorf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+	})
+
+	t.Run("test reflection deadline", func(t *testing.T) {
+		// A server whose reflection service never answers.
+		hung := startEchoServer(t, echoServerOptions{serverOptions: []grpc.ServerOption{
+			grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+				<-stream.Context().Done()
+				return nil
+			}),
+		}})
+
+		for _, timeout := range []utils.Args{utils.WithGRPCTimeout(200 * time.Millisecond), utils.WithGRPCConnectTimeout(200 * time.Millisecond)} {
+			start := time.Now()
+			resp, _ := utils.GRPCReflect(hung, timeout)
+			if resp.Error == nil || resp.Error.Code != "DEADLINE_EXCEEDED" {
+				t.Fatalf("expected DEADLINE_EXCEEDED, got: %v", resp.Error)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("reflection took %s", elapsed)
+			}
+		}
+	})
+
+	t.Run("test method lookup keeps the status code", func(t *testing.T) {
+		hung := startEchoServer(t, echoServerOptions{serverOptions: []grpc.ServerOption{
+			grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+				<-stream.Context().Done()
+				return nil
+			}),
+		}})
+		bare := startEchoServer(t, echoServerOptions{})
+		reflective := startEchoServer(t, echoServerOptions{reflectionV1: true})
+
+		timeout := utils.WithGRPCTimeout(200 * time.Millisecond)
+		tests := []struct {
+			name, address, want string
+		}{
+			{"slow reflection", hung, "DEADLINE_EXCEEDED"},
+			{"no reflection", bare, "UNIMPLEMENTED"},
+			{"missing method", reflective, "NOT_FOUND"},
+		}
+		for _, tt := range tests {
+			method := "Echo"
+			if tt.want == "NOT_FOUND" {
+				method = "Missing"
+			}
+			responses := map[string]func() (*utils.GRPCResponse, error){
+				"call": func() (*utils.GRPCResponse, error) {
+					return utils.GRPCCall(tt.address, "hulaki.test.EchoService", method, timeout)
+				},
+				"describe": func() (*utils.GRPCResponse, error) {
+					return utils.GRPCDescribe(tt.address, "hulaki.test.EchoService."+method, timeout)
+				},
+				"template": func() (*utils.GRPCResponse, error) {
+					return utils.GRPCTemplate(tt.address, "hulaki.test.EchoService/"+method, timeout)
+				},
+			}
+			for kind, call := range responses {
+				resp, err := call()
+				if err != nil {
+					t.Fatalf("%s %s: got an error: %s", tt.name, kind, err.Error())
+				}
+				if resp.Error == nil || resp.Error.Code != tt.want {
+					t.Errorf("%s %s: got: %v, want: %s", tt.name, kind, resp.Error, tt.want)
+				}
+			}
+		}
+	})
+
+	t.Run("test connect timeout", func(t *testing.T) {
+		// A listener that accepts connections but never speaks HTTP/2.
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to listen: %s", err.Error())
+		}
+		defer lis.Close()
+		go func() {
+			for {
+				conn, err := lis.Accept()
+				if err != nil {
+					return
+				}
+				go io.Copy(io.Discard, conn)
+			}
+		}()
+
+		start := time.Now()
+		resp, _ := utils.GRPCCall(lis.Addr().String(), "hulaki.test.EchoService", "Echo", utils.WithGRPCConnectTimeout(300*time.Millisecond), utils.WithGRPCTimeout(5*time.Second))
+		if resp.Error == nil || resp.Error.Code != "UNAVAILABLE" {
+			t.Fatalf("expected UNAVAILABLE, got: %v", resp.Error)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("connecting took %s", elapsed)
+		}
+	})
+}
+
+func TestGRPCResponseMetadata(t *testing.T) {
+	addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+
+	t.Run("test headers and trailers", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(bytes.NewBufferString(`{"message":"hi"}`)))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if got := resp.Metadata.Get("x-echo-header"); !slices.Equal(got, []string{"header"}) {
+			t.Errorf("got headers: %v", resp.Metadata)
+		}
+		if got := resp.Trailers.Get("x-echo-trailer"); !slices.Equal(got, []string{"trailer"}) {
+			t.Errorf("got trailers: %v", resp.Trailers)
+		}
+		if len(resp.Metadata.Get("x-echo-trailer")) != 0 {
+			t.Errorf("trailer leaked into headers: %v", resp.Metadata)
+		}
+	})
+
+	t.Run("test trailers on error", func(t *testing.T) {
+		resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(bytes.NewBufferString(`{"message":"fail"}`)))
+		if resp.Error == nil || resp.Error.Code != "INVALID_ARGUMENT" {
+			t.Fatalf("expected INVALID_ARGUMENT, got: %v", resp.Error)
+		}
+		if got := resp.Trailers.Get("x-echo-trailer"); !slices.Equal(got, []string{"trailer"}) {
+			t.Errorf("got trailers: %v", resp.Trailers)
+		}
+	})
+}
+
+func TestGRPCStatusDetails(t *testing.T) {
+	addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+
+	resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(bytes.NewBufferString(`{"message":"details"}`)))
+	if resp.Error == nil {
+		t.Fatalf("expected an error")
+	}
+	if resp.Error.Code != "INVALID_ARGUMENT" || resp.Error.Message != "message is invalid" {
+		t.Errorf("got: %s: %s", resp.Error.Code, resp.Error.Message)
+	}
+	if len(resp.Error.Details) != 3 {
+		t.Fatalf("got %d details, want 3", len(resp.Error.Details))
+	}
+
+	badRequest := resp.Error.Details[0].(map[string]any)
+	if badRequest["@type"] != "type.googleapis.com/google.rpc.BadRequest" {
+		t.Errorf("got: %v", badRequest)
+	}
+	violation := badRequest["fieldViolations"].([]any)[0].(map[string]any)
+	if violation["field"] != "message" {
+		t.Errorf("got: %v", violation)
+	}
+
+	errorInfo := resp.Error.Details[1].(map[string]any)
+	if errorInfo["reason"] != "BAD_MESSAGE" || errorInfo["domain"] != "hulaki.test" {
+		t.Errorf("got: %v", errorInfo)
+	}
+
+	retryInfo := resp.Error.Details[2].(map[string]any)
+	if retryInfo["retryDelay"] != "2s" {
+		t.Errorf("got: %v", retryInfo)
+	}
+}
+
+func TestGRPCHealth(t *testing.T) {
+	hs := health.NewServer()
+	hs.SetServingStatus("hulaki.test.EchoService", healthpb.HealthCheckResponse_NOT_SERVING)
+	addr := startEchoServer(t, echoServerOptions{health: hs})
+
+	t.Run("test server health", func(t *testing.T) {
+		resp, err := utils.GRPCHealthCheck(addr, "")
+		if err != nil {
+			t.Fatalf("GRPCHealthCheck should not return error, got: %s", err.Error())
+		}
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if status := resp.Data.(map[string]any)["status"]; status != utils.HealthServing {
+			t.Errorf("got status: %v", status)
+		}
+	})
+
+	t.Run("test service not serving", func(t *testing.T) {
+		resp, _ := utils.GRPCHealthCheck(addr, "hulaki.test.EchoService")
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if status := resp.Data.(map[string]any)["status"]; status != "NOT_SERVING" {
+			t.Errorf("got status: %v", status)
+		}
+	})
+
+	t.Run("test unknown service", func(t *testing.T) {
+		resp, _ := utils.GRPCHealthCheck(addr, "hulaki.test.Missing")
+		if resp.Error == nil || resp.Error.Code != "NOT_FOUND" {
+			t.Errorf("expected NOT_FOUND, got: %v", resp.Error)
+		}
+	})
+
+	t.Run("test watch", func(t *testing.T) {
+		var statuses []string
+		resp, _ := utils.GRPCHealthWatch(addr, "hulaki.test.EchoService", func(status string) {
+			statuses = append(statuses, status)
+			if status == "NOT_SERVING" {
+				hs.SetServingStatus("hulaki.test.EchoService", healthpb.HealthCheckResponse_SERVING)
+			}
+		}, utils.WithGRPCTimeout(time.Second))
+		if want := []string{"NOT_SERVING", utils.HealthServing}; !slices.Equal(statuses, want) {
+			t.Errorf("got statuses %v, want %v", statuses, want)
+		}
+		if status := resp.Data.(map[string]any)["status"]; status != utils.HealthServing {
+			t.Errorf("got status: %v", status)
+		}
+	})
+}
+
+func TestGRPCTemplate(t *testing.T) {
+	addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+
+	t.Run("test template from reflection", func(t *testing.T) {
+		resp, err := utils.GRPCTemplate(addr, "hulaki.test.EchoService/Echo")
+		if err != nil {
+			t.Fatalf("GRPCTemplate should not return error, got: %s", err.Error())
+		}
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		got, _ := json.Marshal(resp.Data)
+		want := `{"message":"","count":0,"tags":[""],"inner":{"flag":false},"color":"RED",` +
+			`"// oneof choice":"set only one of: text, number","text":"","number":"0"}`
+		if string(got) != want {
+			t.Errorf("got template:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("test template is a valid request", func(t *testing.T) {
+		resp, _ := utils.GRPCTemplate(addr, "EchoService.Echo")
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		var template []utils.TemplateField
+		for _, field := range resp.Data.(utils.TemplateObject) {
+			if !strings.HasPrefix(field.Key, utils.OneofMarker) && field.Key != "number" {
+				template = append(template, field)
+			}
+		}
+		body, _ := json.Marshal(utils.TemplateObject(template))
+		call, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(bytes.NewBuffer(body)))
+		if call.Error != nil {
+			t.Errorf("template was rejected: %s: %s", call.Error.Code, call.Error.Message)
+		}
+	})
+
+	t.Run("test request template is sent unedited", func(t *testing.T) {
+		body, _ := json.Marshal(utils.RequestTemplate(echoMessage("EchoRequest")))
+		want := `{"message":"","count":0,"tags":[""],"inner":{"flag":false},"color":"RED","text":""}`
+		if string(body) != want {
+			t.Errorf("got template:\n%s\nwant:\n%s", body, want)
+		}
+		call, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", utils.WithBody(bytes.NewBuffer(body)))
+		if call.Error != nil {
+			t.Errorf("template was rejected: %s: %s", call.Error.Code, call.Error.Message)
+		}
+	})
+
+	t.Run("test template from local descriptors", func(t *testing.T) {
+		dir := t.TempDir()
+		os.MkdirAll(filepath.Join(dir, "hulaki", "test"), 0o755)
+		os.WriteFile(filepath.Join(dir, "hulaki", "test", "echo.proto"), []byte(echoProtoSource), 0o644)
+		os.WriteFile(filepath.Join(dir, "hulaki", "test", "types.proto"), []byte(typesProtoSource), 0o644)
+		src, err := utils.LoadDescriptorSource([]string{"hulaki/test/echo.proto"}, []string{dir}, nil)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+
+		resp, _ := utils.GRPCTemplate("localhost:99999", "hulaki.test.EchoService/Ping", utils.WithDescriptorSource(src))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if got, _ := json.Marshal(resp.Data); string(got) != "{}" {
+			t.Errorf("got template: %s", got)
+		}
+	})
+
+	t.Run("test unknown method", func(t *testing.T) {
+		resp, _ := utils.GRPCTemplate(addr, "hulaki.test.EchoService/Missing")
+		if resp.Error == nil || resp.Error.Code != "NOT_FOUND" {
+			t.Errorf("expected NOT_FOUND, got: %v", resp.Error)
+		}
+	})
+}
+
+func TestGRPCBench(t *testing.T) {
+	addr := startEchoServer(t, echoServerOptions{reflectionV1: true})
+
+	t.Run("test request count", func(t *testing.T) {
+		requests := utils.JSONRequestSource(bytes.NewBufferString(`{"message":"hi"}`))
+		report, err := utils.GRPCBench(addr, "hulaki.test.EchoService/Echo", requests, utils.GRPCBenchOptions{
+			Concurrency: 5,
+			Requests:    50,
+			Connections: 2,
+		})
+		if err != nil {
+			t.Fatalf("GRPCBench returned an error: %s", err.Error())
+		}
+		if report.Count != 50 || report.StatusCodes["OK"] != 50 {
+			t.Errorf("got %d calls, status codes %v", report.Count, report.StatusCodes)
+		}
+		histogramCount := 0
+		for _, bucket := range report.Histogram {
+			histogramCount += bucket.Count
+		}
+		if histogramCount != 50 {
+			t.Errorf("histogram holds %d calls, want 50", histogramCount)
+		}
+		if report.Latency.P50Ms > report.Latency.P99Ms || report.Latency.P99Ms > report.Latency.SlowestMs {
+			t.Errorf("inconsistent latencies: %+v", report.Latency)
+		}
+		if report.Options.Connections != 2 {
+			t.Errorf("got %d connections", report.Options.Connections)
+		}
+	})
+
+	t.Run("test status codes", func(t *testing.T) {
+		requests := utils.JSONRequestSource(bytes.NewBufferString(`{"message":"fail"}`))
+		report, err := utils.GRPCBench(addr, "EchoService/Echo", requests, utils.GRPCBenchOptions{Requests: 10})
+		if err != nil {
+			t.Fatalf("GRPCBench returned an error: %s", err.Error())
+		}
+		if report.StatusCodes["INVALID_ARGUMENT"] != 10 {
+			t.Errorf("got status codes %v", report.StatusCodes)
+		}
+		if report.Errors["message cannot be fail"] != 10 {
+			t.Errorf("got errors %v", report.Errors)
+		}
+	})
+
+	t.Run("test duration and rate limit", func(t *testing.T) {
+		report, err := utils.GRPCBench(addr, "hulaki.test.EchoService/ServerStream", utils.JSONRequestSource(nil), utils.GRPCBenchOptions{
+			Concurrency: 4,
+			Duration:    300 * time.Millisecond,
+			RPS:         50,
+		})
+		if err != nil {
+			t.Fatalf("GRPCBench returned an error: %s", err.Error())
+		}
+		if report.Count == 0 || report.Count > 20 {
+			t.Errorf("got %d calls in 300ms at 50 rps", report.Count)
+		}
+	})
+
+	t.Run("test unknown method", func(t *testing.T) {
+		_, err := utils.GRPCBench(addr, "hulaki.test.EchoService/Missing", utils.JSONRequestSource(nil), utils.GRPCBenchOptions{Requests: 1})
+		if err == nil {
+			t.Error("expected an error for an unknown method")
+		}
+	})
+}
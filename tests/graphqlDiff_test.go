@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+const oldDiffSchema = `directive @auth(role: String) on FIELD_DEFINITION | OBJECT
+
+interface Node { id: ID! }
+
+type Query {
+  user(id: ID!): User
+  users(first: Int = 10): [User]
+  search(term: String!): [SearchResult!]
+}
+
+type User implements Node {
+  id: ID!
+  name: String
+  email: String
+  tags: [String]
+  role: Role
+}
+
+type Post { id: ID! }
+
+type Draft { id: ID! }
+
+union SearchResult = User | Draft
+
+enum Role { ADMIN USER }
+
+input UserFilter {
+  name: String
+  role: Role!
+}
+
+scalar Date
+`
+
+const newDiffSchema = `directive @auth(role: String!) on FIELD_DEFINITION
+
+interface Node { id: ID! }
+
+type Query {
+  user(id: ID): User
+  users(first: Int = 20, after: String!): [User]
+  search(term: String!, limit: Int): [SearchResult!]
+}
+
+type User {
+  id: ID!
+  name: String!
+  tags: [String!]!
+  role: Role @deprecated
+  avatar: String
+}
+
+type Post { id: ID! }
+
+type Draft { id: ID! }
+
+union SearchResult = User | Post
+
+enum Role { ADMIN USER GUEST }
+
+input UserFilter {
+  name: Int
+  role: Role
+  active: Boolean!
+}
+
+input Date { day: Int }
+`
+
+func TestDiffGraphQLSchemas(t *testing.T) {
+	oldSchema, err := utils.ParseGraphQLSDL(oldDiffSchema)
+	if err != nil {
+		t.Fatalf("failed to parse SDL: %s", err.Error())
+	}
+	newSchema, err := utils.ParseGraphQLSDL(newDiffSchema)
+	if err != nil {
+		t.Fatalf("failed to parse SDL: %s", err.Error())
+	}
+
+	t.Run("test changes and their criticality", func(t *testing.T) {
+		want := []string{
+			`BREAKING @auth: Location OBJECT was removed from directive "@auth"`,
+			`BREAKING @auth(role:): Argument "role" of "@auth" changed type from "String" to "String!"`,
+			`BREAKING Date: "Date" changed kind from SCALAR to INPUT_OBJECT`,
+			`SAFE Query.user(id:): Argument "id" of "Query.user" changed type from "ID!" to "ID"`,
+			`BREAKING Query.users(after:): Required argument "after" was added to "Query.users"`,
+			`DANGEROUS Query.users(first:): Argument "first" of "Query.users" changed default value from 10 to 20`,
+			`DANGEROUS Query.search(limit:): Optional argument "limit" was added to "Query.search"`,
+			`DANGEROUS Role.GUEST: Enum value "Role.GUEST" was added`,
+			`BREAKING SearchResult: "SearchResult" no longer includes member "Draft"`,
+			`DANGEROUS SearchResult: "SearchResult" now includes member "Post"`,
+			`BREAKING User: "User" no longer implements interface "Node"`,
+			`SAFE User.avatar: Field "User.avatar" was added`,
+			`BREAKING User.email: Field "User.email" was removed`,
+			`SAFE User.name: Field "User.name" changed type from "String" to "String!"`,
+			`SAFE User.role: Field "User.role" was deprecated`,
+			`SAFE User.tags: Field "User.tags" changed type from "[String]" to "[String!]!"`,
+			`BREAKING UserFilter.active: Required input field "active" was added to "UserFilter"`,
+			`BREAKING UserFilter.name: Input field "name" of "UserFilter" changed type from "String" to "Int"`,
+			`SAFE UserFilter.role: Input field "role" of "UserFilter" changed type from "Role!" to "Role"`,
+		}
+
+		got := map[string]bool{}
+		for _, change := range utils.DiffGraphQLSchemas(oldSchema, newSchema) {
+			got[change.Criticality+" "+change.Path+": "+change.Message] = true
+		}
+		for _, change := range want {
+			if !got[change] {
+				t.Errorf("missing change: %s", change)
+			}
+			delete(got, change)
+		}
+		for change := range got {
+			t.Errorf("unexpected change: %s", change)
+		}
+	})
+
+	t.Run("test no changes between introspection and SDL", func(t *testing.T) {
+		var result struct {
+			Data json.RawMessage `json:"data"`
+		}
+		json.Unmarshal([]byte(introspectionResult), &result)
+		introspected, err := utils.ParseIntrospection(result.Data)
+		if err != nil {
+			t.Fatalf("failed to parse introspection: %s", err.Error())
+		}
+		parsed, err := utils.ParseGraphQLSDL(introspectionSDL)
+		if err != nil {
+			t.Fatalf("failed to parse SDL: %s", err.Error())
+		}
+		if changes := utils.DiffGraphQLSchemas(introspected, parsed); len(changes) > 0 {
+			var messages []string
+			for _, change := range changes {
+				messages = append(messages, change.Message)
+			}
+			t.Errorf("got changes:\n%s\nwant none", strings.Join(messages, "\n"))
+		}
+	})
+}
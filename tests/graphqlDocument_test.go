@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+const operationsDocument = `query GetUser($id: ID!) {
+  user(id: $id) { ...UserFields }
+}
+
+mutation Rename($id: ID!, $name: String!) {
+  rename(id: $id, name: $name) { id name }
+}
+`
+
+func TestPrepareGraphQLDocument(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "user.graphql"), []byte("fragment UserFields on User { id name ...Audit }\n"), 0o644)
+	os.MkdirAll(filepath.Join(dir, "shared"), 0o755)
+	os.WriteFile(filepath.Join(dir, "shared", "audit.gql"), []byte("# Shared\nfragment Audit on Node { createdAt }\nfragment Unused on User { role }\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not GraphQL"), 0o644)
+
+	fragments, err := utils.LoadGraphQLFragments(dir)
+	if err != nil {
+		t.Fatalf("got an error: %s", err.Error())
+	}
+	if len(fragments) != 3 || fragments["Audit"] != "fragment Audit on Node { createdAt }" {
+		t.Fatalf("got fragments: %v, want UserFields, Audit and Unused", fragments)
+	}
+
+	t.Run("test appends the fragments spread", func(t *testing.T) {
+		got, err := utils.PrepareGraphQLDocument(operationsDocument, "GetUser", fragments)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		want := strings.TrimSpace(operationsDocument) + "\n\nfragment Audit on Node { createdAt }\n\nfragment UserFields on User { id name ...Audit }\n"
+		if got != want {
+			t.Errorf("got document:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("test fragments defined in the document win", func(t *testing.T) {
+		document := "{ user(id: 1) { ...UserFields } }\nfragment UserFields on User { id }\n"
+		got, err := utils.PrepareGraphQLDocument(document, "", fragments)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if got != document {
+			t.Errorf("got document:\n%s\nwant it unchanged", got)
+		}
+	})
+
+	t.Run("test operation selection", func(t *testing.T) {
+		errs := map[string]string{
+			"":       "the document has 2 operations, name the one to run: GetUser, Rename",
+			"Renam":  `operation "Renam" is not defined in the document; did you mean "Rename"?`,
+			"Rename": "",
+		}
+		for operation, want := range errs {
+			_, err := utils.PrepareGraphQLDocument(operationsDocument, operation, fragments)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != want {
+				t.Errorf("got error: %q for %q, want: %q", got, operation, want)
+			}
+		}
+	})
+
+	t.Run("test fragments defined twice", func(t *testing.T) {
+		os.WriteFile(filepath.Join(dir, "copy.graphql"), []byte("fragment Audit on Node { id }"), 0o644)
+		defer os.Remove(filepath.Join(dir, "copy.graphql"))
+		if _, err := utils.LoadGraphQLFragments(dir); err == nil || !strings.Contains(err.Error(), `fragment "Audit" is defined in both`) {
+			t.Errorf("got error: %v, want the duplicate reported", err)
+		}
+	})
+}
+
+func TestParseGraphQLOperation(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		wantType  string
+		wantVars  []string
+	}{
+		{
+			name:     "comment before a mutation",
+			query:    "# Renames a user\nmutation { rename(id: 1, name: \"Ada\") { id } }",
+			wantType: "mutation",
+		},
+		{
+			name:      "fragments first",
+			query:     "fragment F on User { name }\nsubscription OnUser($id: ID!, $full: Boolean = false) { user(id: $id) { ...F } }",
+			operation: "OnUser",
+			wantType:  "subscription",
+			wantVars:  []string{"id: ID!", "full: Boolean = ..."},
+		},
+		{
+			name:      "selected operation",
+			query:     operationsDocument,
+			operation: "Rename",
+			wantType:  "mutation",
+			wantVars:  []string{"id: ID!", "name: String!"},
+		},
+		{
+			name:     "shorthand query",
+			query:    "{ users { name } }",
+			wantType: "query",
+		},
+		{
+			name:     "unicode escapes",
+			query:    `{ greet(text: "\u{1F600} \u{e9} \u00e9 \uD83D\uDE00") }`,
+			wantType: "query",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("test "+tt.name, func(t *testing.T) {
+			op, err := utils.ParseGraphQLOperation(tt.query, tt.operation)
+			if err != nil {
+				t.Fatalf("got an error: %s", err.Error())
+			}
+			if op.Type != tt.wantType || op.Name != tt.operation {
+				t.Errorf("got operation: %s %q, want: %s %q", op.Type, op.Name, tt.wantType, tt.operation)
+			}
+			var vars []string
+			for _, v := range op.Variables {
+				desc := v.Name + ": " + v.Type.String()
+				if v.HasDefault {
+					desc += " = ..."
+				}
+				vars = append(vars, desc)
+			}
+			if strings.Join(vars, ", ") != strings.Join(tt.wantVars, ", ") {
+				t.Errorf("got variables: %v, want: %v", vars, tt.wantVars)
+			}
+		})
+	}
+
+	t.Run("test missing required variables", func(t *testing.T) {
+		op, err := utils.ParseGraphQLOperation(operationsDocument, "Rename")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		var got []string
+		for _, problem := range op.MissingVariables(map[string]any{"id": "1", "name": nil}) {
+			got = append(got, problem.Error())
+		}
+		want := []string{`line 5, column 27: variable "$name" of required type "String!" was not provided`}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("got errors: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("test ambiguous and broken documents", func(t *testing.T) {
+		if _, err := utils.ParseGraphQLOperation(operationsDocument, ""); err == nil {
+			t.Errorf("got no error for a document with two operations")
+		}
+		if _, err := utils.ParseGraphQLOperation("mutation {", ""); err == nil {
+			t.Errorf("got no error for a broken document")
+		}
+	})
+
+	t.Run("test unicode escapes are decoded", func(t *testing.T) {
+		// The stray string is reported as found, with its decoded value.
+		_, err := utils.ParseGraphQLOperation(`{ a } "\u{1F600}\uD83D\uDE00\u{0041}"`, "")
+		if err == nil || !strings.Contains(err.Error(), `string "😀😀A"`) {
+			t.Errorf("got: %v, want an error showing the decoded string", err)
+		}
+		for _, escape := range []string{`\u{}`, `\u{110000}`, `\u{D83D}`, `\uD83D`, `\uD83D\u0041`, `\uDE00\uD83D`, `\u00`, `\u{41`} {
+			_, err := utils.ParseGraphQLOperation(`{ greet(text: "`+escape+`") }`, "")
+			if err == nil || !strings.Contains(err.Error(), "invalid unicode escape") {
+				t.Errorf("got: %v for %s, want an invalid unicode escape", err, escape)
+			}
+		}
+	})
+}
+
+func TestGraphQLOperationName(t *testing.T) {
+	var got utils.GraphQLRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"rename":{"id":"1","name":"Ada"}}}`))
+	}))
+	defer server.Close()
+
+	resp, err := utils.GraphQLMutation(server.URL, operationsDocument, utils.WithOperationName("Rename"), utils.WithVariables(map[string]any{"id": "1", "name": "Ada"}))
+	if err != nil {
+		t.Fatalf("got an error: %s", err.Error())
+	}
+	resp.Body.Close()
+	if got.OperationName != "Rename" || got.Query != operationsDocument || got.Variables["name"] != "Ada" {
+		t.Errorf("got request: %+v, want operation Rename with its variables", got)
+	}
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// setupMultipartServer answers every request with parts as multipart/mixed
+// incremental payloads, flushing each one.
+func setupMultipartServer(t *testing.T, parts ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), "multipart/mixed") {
+			http.Error(w, "expected a multipart Accept header", http.StatusNotAcceptable)
+			return
+		}
+		mw := multipart.NewWriter(w)
+		mw.SetBoundary("-")
+		w.Header().Set("Content-Type", `multipart/mixed; boundary="-"; deferSpec=20220824`)
+		for _, part := range parts {
+			pw, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json; charset=utf-8"}})
+			pw.Write([]byte(part))
+			w.(http.Flusher).Flush()
+		}
+		mw.Close()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGraphQLIncrementalDelivery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		parts []string
+		want  string
+	}{
+		{
+			name:  "deferred fragment",
+			query: "{ user { name ... @defer { friends { name } } } }",
+			parts: []string{
+				`{"data":{"user":{"name":"Ada"}},"hasNext":true}`,
+				`{}`,
+				`{"incremental":[{"data":{"friends":[{"name":"Bob"}]},"path":["user"]}],"hasNext":false}`,
+			},
+			want: `{"user":{"friends":[{"name":"Bob"}],"name":"Ada"}}`,
+		},
+		{
+			name:  "streamed list",
+			query: "{ numbers @stream(initialCount: 1) }",
+			parts: []string{
+				`{"data":{"numbers":[1]},"hasNext":true}`,
+				`{"incremental":[{"items":[2,3],"path":["numbers",1]}],"hasNext":true}`,
+				`{"incremental":[{"items":[4],"path":["numbers",3]}],"hasNext":false}`,
+			},
+			want: `{"numbers":[1,2,3,4]}`,
+		},
+		{
+			name:  "pending ids",
+			query: "{ user { name ... @defer { age } } }",
+			parts: []string{
+				`{"data":{"user":{"name":"Ada"}},"pending":[{"id":"0","path":["user"]}],"hasNext":true}`,
+				`{"incremental":[{"id":"0","data":{"age":36}}],"completed":[{"id":"0"}],"hasNext":false}`,
+			},
+			want: `{"user":{"age":36,"name":"Ada"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("test "+tt.name, func(t *testing.T) {
+			server := setupMultipartServer(t, tt.parts...)
+
+			resp, err := utils.GraphQLQuery(server.URL, tt.query)
+			if err != nil {
+				t.Fatalf("got an error: %s", err.Error())
+			}
+			var chunks []string
+			gqlResp, err := utils.ReadGraphQLStream(resp, func(chunk json.RawMessage) {
+				chunks = append(chunks, string(chunk))
+			})
+			if err != nil {
+				t.Fatalf("failed to read the stream: %s", err.Error())
+			}
+
+			var want []string
+			for _, part := range tt.parts {
+				if part != "{}" {
+					want = append(want, part)
+				}
+			}
+			if !reflect.DeepEqual(chunks, want) {
+				t.Errorf("got chunks: %v, want: %v", chunks, want)
+			}
+			data, _ := json.Marshal(gqlResp.Data)
+			if string(data) != tt.want {
+				t.Errorf("got data: %s, want: %s", data, tt.want)
+			}
+		})
+	}
+
+	t.Run("test errors in deferred payloads", func(t *testing.T) {
+		server := setupMultipartServer(t,
+			`{"data":{"user":{"name":"Ada"}},"hasNext":true}`,
+			`{"incremental":[{"data":null,"path":["user"],"errors":[{"message":"friends are private"}]}],"hasNext":false}`,
+		)
+
+		resp, err := utils.GraphQLQuery(server.URL, "{ user { name ... @defer { friends { name } } } }")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		gqlResp, err := utils.ParseGraphQLResponse(resp)
+		if err != nil {
+			t.Fatalf("failed to parse GraphQL response: %s", err.Error())
+		}
+		if len(gqlResp.Errors) != 1 || gqlResp.Errors[0].Message != "friends are private" {
+			t.Errorf("got errors: %v, want: friends are private", gqlResp.Errors)
+		}
+		if data, _ := json.Marshal(gqlResp.Data); string(data) != `{"user":{"name":"Ada"}}` {
+			t.Errorf("got data: %s, want the initial payload", data)
+		}
+	})
+}
+
+func TestGraphQLSSE(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			http.Error(w, "expected an event stream Accept header", http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		for i := 3; i > 0; i-- {
+			fmt.Fprintf(w, "event: next\ndata: {\"data\":{\"countdown\":%d}}\n\n", i)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, "event: complete\ndata:\n\n")
+	}))
+	defer server.Close()
+
+	resp, err := utils.GraphQLSSE(server.URL, "subscription { countdown }")
+	if err != nil {
+		t.Fatalf("got an error: %s", err.Error())
+	}
+	var chunks []string
+	gqlResp, err := utils.ReadGraphQLStream(resp, func(chunk json.RawMessage) {
+		chunks = append(chunks, string(chunk))
+	})
+	if err != nil {
+		t.Fatalf("failed to read the stream: %s", err.Error())
+	}
+
+	want := []string{`{"data":{"countdown":3}}`, `{"data":{"countdown":2}}`, `{"data":{"countdown":1}}`}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("got chunks: %v, want: %v", chunks, want)
+	}
+	data, _ := json.Marshal(gqlResp.Data)
+	if string(data) != `{"countdown":1}` {
+		t.Errorf("got data: %s, want the last event", data)
+	}
+}
@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// setupPersistedQueryServer serves automatic persisted queries, recording
+// the method of every request and whether it carried the full query.
+func setupPersistedQueryServer(t *testing.T, requests *[]string) *httptest.Server {
+	t.Helper()
+	known := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req utils.GraphQLRequest
+		if r.Method == http.MethodGet {
+			req.Query = r.URL.Query().Get("query")
+			json.Unmarshal([]byte(r.URL.Query().Get("extensions")), &req.Extensions)
+			if r.Header.Get("Apollo-Require-Preflight") == "" {
+				http.Error(w, "missing preflight header", http.StatusBadRequest)
+				return
+			}
+		} else {
+			json.NewDecoder(r.Body).Decode(&req)
+		}
+		kind := "hash"
+		if req.Query != "" {
+			kind = "query"
+		}
+		*requests = append(*requests, r.Method+" "+kind)
+
+		w.Header().Set("Content-Type", "application/json")
+		persisted, _ := req.Extensions["persistedQuery"].(map[string]any)
+		hash, _ := persisted["sha256Hash"].(string)
+		if req.Query != "" {
+			sum := sha256.Sum256([]byte(req.Query))
+			if hash != hex.EncodeToString(sum[:]) {
+				http.Error(w, "hash mismatch", http.StatusBadRequest)
+				return
+			}
+			known[hash] = req.Query
+		} else if _, ok := known[hash]; !ok {
+			w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"hello":"world"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGraphQLPersistedQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		args  []utils.Args
+		want  []string
+	}{
+		{
+			name:  "POST",
+			query: "{ hello }",
+			args:  []utils.Args{utils.WithPersistedQuery()},
+			want:  []string{"POST hash", "POST query", "POST hash"},
+		},
+		{
+			name:  "GET",
+			query: "{ hello }",
+			args:  []utils.Args{utils.WithPersistedQuery(), utils.WithGET()},
+			want:  []string{"GET hash", "POST query", "GET hash"},
+		},
+		{
+			name:  "mutation over GET",
+			query: "mutation { hello }",
+			args:  []utils.Args{utils.WithPersistedQuery(), utils.WithGET()},
+			want:  []string{"POST hash", "POST query", "POST hash"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("test persisted query over "+tt.name, func(t *testing.T) {
+			var requests []string
+			server := setupPersistedQueryServer(t, &requests)
+
+			for range 2 {
+				resp, err := utils.GraphQLQuery(server.URL, tt.query, tt.args...)
+				if err != nil {
+					t.Fatalf("got an error: %s", err.Error())
+				}
+				gqlResp, err := utils.ParseGraphQLResponse(resp)
+				if err != nil {
+					t.Fatalf("failed to parse GraphQL response: %s", err.Error())
+				}
+				if len(gqlResp.Errors) > 0 || gqlResp.Data.(map[string]any)["hello"] != "world" {
+					t.Errorf("got response: %+v, want the data", gqlResp)
+				}
+			}
+			if !slices.Equal(requests, tt.want) {
+				t.Errorf("got requests: %v, want: %v", requests, tt.want)
+			}
+		})
+	}
+
+	t.Run("test persisted queries not supported", func(t *testing.T) {
+		var bodies []utils.GraphQLRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req utils.GraphQLRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			bodies = append(bodies, req)
+			if req.Query == "" {
+				w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotSupported"}]}`))
+				return
+			}
+			w.Write([]byte(`{"data":{"hello":"world"}}`))
+		}))
+		defer server.Close()
+
+		resp, err := utils.GraphQLQuery(server.URL, "{ hello }", utils.WithPersistedQuery())
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		resp.Body.Close()
+		if len(bodies) != 2 || bodies[1].Query != "{ hello }" || bodies[1].Extensions != nil {
+			t.Errorf("got requests: %+v, want the plain query sent again", bodies)
+		}
+	})
+}
+
+func TestGraphQLBatch(t *testing.T) {
+	document := `query Users { users { ...Name } }
+query User($id: ID!) { user(id: $id) { ...Name } }
+fragment Name on User { name }
+`
+	requests, err := utils.SplitGraphQLDocument(document)
+	if err != nil {
+		t.Fatalf("got an error: %s", err.Error())
+	}
+	want := []utils.GraphQLRequest{
+		{Query: "query Users { users { ...Name } }\n\nfragment Name on User { name }", OperationName: "Users"},
+		{Query: "query User($id: ID!) { user(id: $id) { ...Name } }\n\nfragment Name on User { name }", OperationName: "User"},
+	}
+	for i := range want {
+		if i >= len(requests) || requests[i].Query != want[i].Query || requests[i].OperationName != want[i].OperationName {
+			t.Fatalf("got requests: %+v, want: %+v", requests, want)
+		}
+	}
+
+	t.Run("test batch results", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var batch []utils.GraphQLRequest
+			if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+				w.Write([]byte(`{"errors":[{"message":"Operation batching disabled."}]}`))
+				return
+			}
+			results := make([]utils.GraphQLResponse, 0, len(batch))
+			for _, req := range batch {
+				results = append(results, utils.GraphQLResponse{Data: map[string]any{"operation": req.OperationName, "id": req.Variables["id"]}})
+			}
+			json.NewEncoder(w).Encode(results)
+		}))
+		defer server.Close()
+
+		resp, err := utils.GraphQLBatch(server.URL, requests, utils.WithVariables(map[string]any{"id": "7"}))
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		results, err := utils.ParseGraphQLBatchResponse(resp)
+		if err != nil {
+			t.Fatalf("failed to parse batch response: %s", err.Error())
+		}
+		if len(results) != 2 {
+			t.Fatalf("got %d results, want 2", len(results))
+		}
+		for i, result := range results {
+			data := result.Data.(map[string]any)
+			if data["operation"] != want[i].OperationName || data["id"] != "7" {
+				t.Errorf("got result %d: %v, want %s with the shared variables", i, data, want[i].OperationName)
+			}
+		}
+
+		resp, err = utils.GraphQLQuery(server.URL, "{ users { name } }")
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if _, err := utils.ParseGraphQLBatchResponse(resp); err == nil || !strings.Contains(err.Error(), "batching disabled") {
+			t.Errorf("got error: %v, want the server's error", err)
+		}
+	})
+}
@@ -0,0 +1,397 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// introspectionResult is a trimmed introspection response covering every
+// kind of type.
+const introspectionResult = `{
+  "data": {
+    "__schema": {
+      "queryType": {"name": "Query"},
+      "mutationType": {"name": "Mutation"},
+      "subscriptionType": null,
+      "types": [
+        {"kind": "SCALAR", "name": "String", "description": "The String scalar type."},
+        {"kind": "SCALAR", "name": "ID"},
+        {"kind": "SCALAR", "name": "DateTime", "description": "An ISO-8601 timestamp."},
+        {
+          "kind": "OBJECT", "name": "Query",
+          "fields": [
+            {
+              "name": "user",
+              "args": [{"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}],
+              "type": {"kind": "OBJECT", "name": "User"}
+            },
+            {
+              "name": "search",
+              "args": [
+                {"name": "term", "description": "Text to look for.", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}},
+                {"name": "first", "type": {"kind": "SCALAR", "name": "Int"}, "defaultValue": "10"}
+              ],
+              "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "NON_NULL", "ofType": {"kind": "UNION", "name": "SearchResult"}}}}
+            }
+          ],
+          "interfaces": []
+        },
+        {
+          "kind": "OBJECT", "name": "Mutation",
+          "fields": [
+            {
+              "name": "createUser",
+              "args": [{"name": "input", "type": {"kind": "NON_NULL", "ofType": {"kind": "INPUT_OBJECT", "name": "UserInput"}}}],
+              "type": {"kind": "OBJECT", "name": "User"}
+            }
+          ],
+          "interfaces": []
+        },
+        {
+          "kind": "INTERFACE", "name": "Node",
+          "fields": [{"name": "id", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}],
+          "possibleTypes": [{"kind": "OBJECT", "name": "User"}]
+        },
+        {
+          "kind": "OBJECT", "name": "User", "description": "A person with an account.",
+          "fields": [
+            {"name": "id", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}},
+            {"name": "name", "description": "Full name.", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+            {"name": "username", "args": [], "type": {"kind": "SCALAR", "name": "String"}, "isDeprecated": true, "deprecationReason": "Use name."},
+            {"name": "role", "args": [], "type": {"kind": "ENUM", "name": "Role"}},
+            {"name": "createdAt", "args": [], "type": {"kind": "SCALAR", "name": "DateTime"}}
+          ],
+          "interfaces": [{"kind": "INTERFACE", "name": "Node"}]
+        },
+        {
+          "kind": "OBJECT", "name": "Post",
+          "fields": [{"name": "title", "args": [], "type": {"kind": "SCALAR", "name": "String"}}],
+          "interfaces": []
+        },
+        {
+          "kind": "UNION", "name": "SearchResult",
+          "possibleTypes": [{"kind": "OBJECT", "name": "User"}, {"kind": "OBJECT", "name": "Post"}]
+        },
+        {
+          "kind": "ENUM", "name": "Role",
+          "enumValues": [
+            {"name": "ADMIN"},
+            {"name": "MEMBER", "description": "Line one.\nLine two."},
+            {"name": "GUEST", "isDeprecated": true, "deprecationReason": "No longer supported"}
+          ]
+        },
+        {
+          "kind": "INPUT_OBJECT", "name": "UserInput",
+          "inputFields": [
+            {"name": "name", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}},
+            {"name": "role", "type": {"kind": "ENUM", "name": "Role"}, "defaultValue": "MEMBER"}
+          ]
+        },
+        {"kind": "OBJECT", "name": "__Schema", "fields": []}
+      ],
+      "directives": [
+        {"name": "deprecated", "locations": ["FIELD_DEFINITION"], "args": []},
+        {"name": "cached", "description": "Cache the result.", "locations": ["FIELD_DEFINITION", "OBJECT"], "args": [{"name": "ttl", "type": {"kind": "SCALAR", "name": "Int"}}]}
+      ]
+    }
+  }
+}`
+
+const introspectionSDL = `"""Cache the result."""
+directive @cached(ttl: Int) on FIELD_DEFINITION | OBJECT
+
+"""An ISO-8601 timestamp."""
+scalar DateTime
+
+type Query {
+  user(id: ID!): User
+  search(
+    """Text to look for."""
+    term: String!
+    first: Int = 10
+  ): [SearchResult!]!
+}
+
+type Mutation {
+  createUser(input: UserInput!): User
+}
+
+interface Node {
+  id: ID!
+}
+
+"""A person with an account."""
+type User implements Node {
+  id: ID!
+  """Full name."""
+  name: String
+  username: String @deprecated(reason: "Use name.")
+  role: Role
+  createdAt: DateTime
+}
+
+type Post {
+  title: String
+}
+
+union SearchResult = User | Post
+
+enum Role {
+  ADMIN
+  """
+  Line one.
+  Line two.
+  """
+  MEMBER
+  GUEST @deprecated
+}
+
+input UserInput {
+  name: String!
+  role: Role = MEMBER
+}
+`
+
+func setupIntrospectionServer(t *testing.T, response string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var gqlReq utils.GraphQLRequest
+		if err := json.Unmarshal(body, &gqlReq); err != nil || !strings.Contains(gqlReq.Query, "__schema") {
+			http.Error(w, "expected an introspection query", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGraphQLIntrospect(t *testing.T) {
+	t.Run("test introspection printed as SDL", func(t *testing.T) {
+		server := setupIntrospectionServer(t, introspectionResult)
+
+		data, err := utils.GraphQLIntrospect(server.URL)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		schema, err := utils.ParseIntrospection(data)
+		if err != nil {
+			t.Fatalf("failed to parse introspection: %s", err.Error())
+		}
+
+		if got := schema.SDL(); got != introspectionSDL {
+			t.Errorf("got SDL:\n%s\nwant:\n%s", got, introspectionSDL)
+		}
+	})
+
+	t.Run("test schema block for custom root types", func(t *testing.T) {
+		schema, err := utils.ParseIntrospection(json.RawMessage(`{"__schema": {
+			"queryType": {"name": "RootQuery"},
+			"types": [{"kind": "OBJECT", "name": "RootQuery", "fields": [{"name": "ok", "args": [], "type": {"kind": "SCALAR", "name": "Boolean"}}]}]
+		}}`))
+		if err != nil {
+			t.Fatalf("failed to parse introspection: %s", err.Error())
+		}
+
+		want := "schema {\n  query: RootQuery\n}\n\ntype RootQuery {\n  ok: Boolean\n}\n"
+		if got := schema.SDL(); got != want {
+			t.Errorf("got SDL:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("test introspection disabled", func(t *testing.T) {
+		server := setupIntrospectionServer(t, `{"errors": [{"message": "introspection is disabled"}]}`)
+
+		_, err := utils.GraphQLIntrospect(server.URL)
+		if err == nil || !strings.Contains(err.Error(), "introspection is disabled") {
+			t.Errorf("got error: %v, want: introspection is disabled", err)
+		}
+	})
+
+	t.Run("test introspection without a schema", func(t *testing.T) {
+		_, err := utils.ParseIntrospection(json.RawMessage(`{"hello": "world"}`))
+		if err == nil {
+			t.Error("expected an error for a result without __schema but got none")
+		}
+	})
+}
+
+func TestParseGraphQLSDL(t *testing.T) {
+	t.Run("test SDL round trip", func(t *testing.T) {
+		schema, err := utils.ParseGraphQLSDL(introspectionSDL)
+		if err != nil {
+			t.Fatalf("failed to parse SDL: %s", err.Error())
+		}
+		if got := schema.SDL(); got != introspectionSDL {
+			t.Errorf("got SDL:\n%s\nwant:\n%s", got, introspectionSDL)
+		}
+		if schema.QueryType == nil || schema.QueryType.Name != "Query" {
+			t.Errorf("got query type: %v, want: Query", schema.QueryType)
+		}
+	})
+
+	t.Run("test SDL syntax error", func(t *testing.T) {
+		_, err := utils.ParseGraphQLSDL("type Query {\n  user(id: ID!: User\n}")
+		if err == nil || !strings.Contains(err.Error(), "line 2, column 15") {
+			t.Errorf("got error: %v, want one at line 2, column 15", err)
+		}
+	})
+}
+
+func TestValidateGraphQL(t *testing.T) {
+	data, err := utils.GraphQLIntrospect(setupIntrospectionServer(t, introspectionResult).URL)
+	if err != nil {
+		t.Fatalf("got an error: %s", err.Error())
+	}
+	introspected, err := utils.ParseIntrospection(data)
+	if err != nil {
+		t.Fatalf("failed to parse introspection: %s", err.Error())
+	}
+	parsed, err := utils.ParseGraphQLSDL(introspectionSDL)
+	if err != nil {
+		t.Fatalf("failed to parse SDL: %s", err.Error())
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]any
+		want      []string
+	}{
+		{
+			name: "valid query",
+			query: `query Find($term: String!) {
+  search(term: $term, first: 5) {
+    __typename
+    ... on User { ...UserFields }
+    ... on Post { title }
+  }
+  createdBy: user(id: "1") @include(if: true) { role }
+}
+
+fragment UserFields on User { id name createdAt }`,
+			variables: map[string]any{"term": "ada"},
+			want:      nil,
+		},
+		{
+			name:  "syntax error",
+			query: "{\n  user(id: \"1\" {\n    name\n  }\n}",
+			want:  []string{`line 2, column 16: syntax error: expected a name, found "{"`},
+		},
+		{
+			name:  "unknown field",
+			query: "{\n  user(id: 1) {\n    nmae\n  }\n}",
+			want:  []string{`line 3, column 5: cannot query field "nmae" on type "User"; did you mean "name"?`},
+		},
+		{
+			name:  "field on a union",
+			query: `{ search(term: "x") { title } }`,
+			want:  []string{`line 1, column 23: cannot query field "title" on union type "SearchResult"; use a fragment on one of its types`},
+		},
+		{
+			name:  "wrong argument type",
+			query: `{ user(id: true) { name } search(term: "x", first: "ten") { __typename } }`,
+			want: []string{
+				`line 1, column 12: expected value of type "ID", found true`,
+				`line 1, column 52: expected value of type "Int", found "ten"`,
+			},
+		},
+		{
+			name:  "missing selection and argument",
+			query: `{ user { id } search }`,
+			want: []string{
+				`line 1, column 3: argument "id" of type "ID!" is required on field "Query.user" but not provided`,
+				`line 1, column 15: argument "term" of type "String!" is required on field "Query.search" but not provided`,
+				`line 1, column 15: field "search" of type "[SearchResult!]!" must have a selection of subfields`,
+			},
+		},
+		{
+			name:  "input object fields",
+			query: `mutation { createUser(input: {role: OWNER, nickname: "x"}) { id } }`,
+			want: []string{
+				`line 1, column 30: field "UserInput.name" of required type "String!" was not provided`,
+				`line 1, column 37: value OWNER does not exist in "Role" enum`,
+				`line 1, column 44: field "nickname" is not defined by type "UserInput"; did you mean "name"?`,
+			},
+		},
+		{
+			name:  "missing required variable",
+			query: `query ($id: ID!) { user(id: $id) { name } }`,
+			want:  []string{`line 1, column 8: variable "$id" of required type "ID!" was not provided`},
+		},
+		{
+			name:      "variable value does not match its type",
+			query:     `mutation ($input: UserInput!) { createUser(input: $input) { id } }`,
+			variables: map[string]any{"input": map[string]any{"name": "Ada", "role": "OWNER"}},
+			want:      []string{`line 1, column 11: variable "$input" got an invalid value: expected value of type "Role" at "$input.role", found "OWNER"`},
+		},
+		{
+			name:      "variable used in the wrong position",
+			query:     `query ($id: String, $first: Int) { user(id: $id) { name } search(term: "x", first: $first) { __typename } }`,
+			variables: map[string]any{"id": "1", "first": 2.5},
+			want: []string{
+				`line 1, column 21: variable "$first" got an invalid value: expected value of type "Int" at "$first", found 2.5`,
+				`line 1, column 45: variable "$id" of type "String" used in position expecting type "ID!"`,
+			},
+		},
+		{
+			name:  "undefined and unused variables",
+			query: `query Find($unused: Int) { user(id: $id) { name } }`,
+			want: []string{
+				`line 1, column 12: variable "$unused" is never used`,
+				`line 1, column 37: variable "$id" is not defined by operation "Find"`,
+			},
+		},
+		{
+			name:  "unknown fragment and type condition",
+			query: `{ user(id: 1) { ...Missing ... on Droid { id } } }`,
+			want: []string{
+				`line 1, column 17: unknown fragment "Missing"`,
+				`line 1, column 28: unknown type "Droid"`,
+			},
+		},
+		{
+			name:      "variables of the selected operation only",
+			query:     "query One($id: ID!) { user(id: $id) { name } }\nquery Two($id: ID!) { user(id: $id) { role } }",
+			operation: "Two",
+			variables: map[string]any{},
+			want: []string{
+				`line 2, column 11: variable "$id" of required type "ID!" was not provided`,
+			},
+		},
+		{
+			name:      "unknown operation",
+			query:     `query One { user(id: 1) { name } }`,
+			operation: "Three",
+			want: []string{
+				`line 1, column 1: operation "Three" is not defined in the document`,
+			},
+		},
+	}
+
+	for _, schema := range []struct {
+		name   string
+		schema *utils.GraphQLSchema
+	}{{"introspection", introspected}, {"SDL", parsed}} {
+		for _, tt := range tests {
+			t.Run("test "+tt.name+" against "+schema.name, func(t *testing.T) {
+				var got []string
+				for _, problem := range utils.ValidateGraphQL(schema.schema, tt.query, tt.operation, tt.variables) {
+					got = append(got, problem.Error())
+				}
+				if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+					t.Errorf("got errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+type subscriptionMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// setupSubscriptionServer serves a countdown subscription over the given
+// subprotocols. Queries containing "fail" get an error, and "forever"
+// subscriptions send one event and wait to be stopped; the messages the
+// client sends are reported on received.
+func setupSubscriptionServer(t *testing.T, protocols []string, received chan<- subscriptionMessage) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{Subprotocols: protocols}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		legacy := conn.Subprotocol() == utils.GraphQLWS
+		next := "next"
+		if legacy {
+			next = "data"
+		}
+
+		for {
+			var msg subscriptionMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if received != nil {
+				received <- msg
+			}
+			switch msg.Type {
+			case "connection_init":
+				var payload map[string]string
+				json.Unmarshal(msg.Payload, &payload)
+				if payload["token"] != "secret" {
+					if legacy {
+						conn.WriteJSON(subscriptionMessage{Type: "connection_error", Payload: json.RawMessage(`{"message":"Forbidden"}`)})
+					} else {
+						conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4403, "Forbidden"))
+					}
+					return
+				}
+				conn.WriteJSON(subscriptionMessage{Type: "ping"})
+				conn.WriteJSON(subscriptionMessage{Type: "connection_ack"})
+			case "subscribe", "start":
+				var req utils.GraphQLRequest
+				json.Unmarshal(msg.Payload, &req)
+				switch {
+				case strings.Contains(req.Query, "fail"):
+					conn.WriteJSON(subscriptionMessage{ID: msg.ID, Type: "error", Payload: json.RawMessage(`[{"message":"Cannot query field \"fail\""}]`)})
+				case strings.Contains(req.Query, "forever"):
+					conn.WriteJSON(subscriptionMessage{ID: msg.ID, Type: next, Payload: json.RawMessage(`{"data":{"tick":0}}`)})
+				default:
+					from, _ := req.Variables["from"].(float64)
+					for i := int(from); i > 0; i-- {
+						payload, _ := json.Marshal(map[string]any{"data": map[string]any{"countdown": i}})
+						conn.WriteJSON(subscriptionMessage{ID: msg.ID, Type: next, Payload: payload})
+					}
+					conn.WriteJSON(subscriptionMessage{ID: msg.ID, Type: "complete"})
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGraphQLSubscribe(t *testing.T) {
+	auth := utils.WithConnectionPayload(map[string]any{"token": "secret"})
+	countdown := "subscription ($from: Int!) { countdown(from: $from) }"
+
+	for _, protocol := range []string{utils.GraphQLTransportWS, utils.GraphQLWS} {
+		t.Run("test events until complete over "+protocol, func(t *testing.T) {
+			server := setupSubscriptionServer(t, []string{protocol}, nil)
+
+			var got []float64
+			err := utils.GraphQLSubscribe(context.Background(), server.URL, countdown, func(event *utils.GraphQLResponse) {
+				got = append(got, event.Data.(map[string]any)["countdown"].(float64))
+			}, auth, utils.WithVariables(map[string]any{"from": 3}))
+			if err != nil {
+				t.Fatalf("got an error: %s", err.Error())
+			}
+			if want := []float64{3, 2, 1}; !slices.Equal(got, want) {
+				t.Errorf("got events: %v, want: %v", got, want)
+			}
+		})
+
+		t.Run("test subscription error over "+protocol, func(t *testing.T) {
+			server := setupSubscriptionServer(t, []string{protocol}, nil)
+
+			err := utils.GraphQLSubscribe(context.Background(), server.URL, "subscription { fail }", func(*utils.GraphQLResponse) {}, auth)
+			if err == nil || !strings.Contains(err.Error(), `Cannot query field "fail"`) {
+				t.Errorf("got error: %v, want the server's error", err)
+			}
+		})
+
+		t.Run("test rejected connection over "+protocol, func(t *testing.T) {
+			server := setupSubscriptionServer(t, []string{protocol}, nil)
+
+			err := utils.GraphQLSubscribe(context.Background(), server.URL, countdown, func(*utils.GraphQLResponse) {})
+			if err == nil || !strings.Contains(err.Error(), "Forbidden") {
+				t.Errorf("got error: %v, want Forbidden", err)
+			}
+		})
+	}
+
+	t.Run("test cancel stops the subscription", func(t *testing.T) {
+		for protocol, stop := range map[string]string{utils.GraphQLTransportWS: "complete", utils.GraphQLWS: "stop"} {
+			received := make(chan subscriptionMessage, 10)
+			server := setupSubscriptionServer(t, []string{protocol}, received)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			err := utils.GraphQLSubscribe(ctx, server.URL, "subscription { forever }", func(*utils.GraphQLResponse) {
+				cancel()
+			}, auth, utils.WithGraphQLSubprotocol(protocol))
+			if err != nil {
+				t.Fatalf("got an error: %s", err.Error())
+			}
+
+			var types []string
+			timeout := time.After(time.Second)
+			for !slices.Contains(types, stop) {
+				select {
+				case msg := <-received:
+					types = append(types, msg.Type)
+				case <-timeout:
+					t.Fatalf("got messages: %v over %s, want a %q message", types, protocol, stop)
+				}
+			}
+		}
+	})
+
+	t.Run("test subscription detection", func(t *testing.T) {
+		queries := map[string]bool{
+			"subscription { tick }":                    true,
+			"  subscription OnTick { tick }":           true,
+			"{ tick }":                                 false,
+			"fragment F on T { a } subscription { b }": true,
+			"mutation { subscription }":                false,
+			"# Ticks\nsubscription { tick }":           true,
+		}
+		for query, want := range queries {
+			op, err := utils.ParseGraphQLOperation(query, "")
+			if err != nil {
+				t.Fatalf("got an error for %q: %s", query, err.Error())
+			}
+			if got := op.Type == "subscription"; got != want {
+				t.Errorf("got: %v for %q, want: %v", got, query, want)
+			}
+		}
+	})
+}
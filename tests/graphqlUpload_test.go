@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+// uploadRequest is what setupUploadServer received: the operations and map
+// fields, and the contents of the files by field name.
+type uploadRequest struct {
+	operations map[string]any
+	fileMap    map[string][]string
+	files      map[string]string
+	names      map[string]string
+}
+
+func setupUploadServer(t *testing.T, got *uploadRequest) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		*got = uploadRequest{files: map[string]string{}, names: map[string]string{}}
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			data, _ := io.ReadAll(part)
+			switch part.FormName() {
+			case "operations":
+				json.Unmarshal(data, &got.operations)
+			case "map":
+				json.Unmarshal(data, &got.fileMap)
+			default:
+				got.files[part.FormName()] = string(data)
+				got.names[part.FormName()] = part.FileName()
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"upload":true}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGraphQLUpload(t *testing.T) {
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "photo.png")
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	os.WriteFile(photo, []byte("photo"), 0o644)
+	os.WriteFile(a, []byte("first"), 0o644)
+	os.WriteFile(b, []byte("second"), 0o644)
+
+	var uploads []utils.GraphQLUpload
+	for _, flag := range []string{"photo=@" + photo, "variables.input.docs=@" + a, "variables.input.docs=@" + b, "variables.cover=@" + photo} {
+		upload, err := utils.ParseGraphQLUpload(flag)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		uploads = append(uploads, upload)
+	}
+
+	t.Run("test multipart request", func(t *testing.T) {
+		var got uploadRequest
+		server := setupUploadServer(t, &got)
+
+		query := "mutation ($photo: Upload!, $cover: Upload, $input: DocsInput!) { upload(photo: $photo, cover: $cover, input: $input) }"
+		resp, err := utils.GraphQLMutation(server.URL, query,
+			utils.WithVariables(map[string]any{"input": map[string]any{"title": "Docs"}}),
+			utils.WithUploads(uploads...),
+		)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		gqlResp, err := utils.ParseGraphQLResponse(resp)
+		if err != nil || gqlResp.Data.(map[string]any)["upload"] != true {
+			t.Fatalf("got response: %v, %v, want the upload accepted", gqlResp, err)
+		}
+
+		wantOperations := map[string]any{
+			"query": query,
+			"variables": map[string]any{
+				"photo": nil,
+				"cover": nil,
+				"input": map[string]any{"title": "Docs", "docs": []any{nil, nil}},
+			},
+		}
+		if !reflect.DeepEqual(got.operations, wantOperations) {
+			t.Errorf("got operations: %v, want: %v", got.operations, wantOperations)
+		}
+		wantMap := map[string][]string{
+			"0": {"variables.photo", "variables.cover"},
+			"1": {"variables.input.docs.0"},
+			"2": {"variables.input.docs.1"},
+		}
+		if !reflect.DeepEqual(got.fileMap, wantMap) {
+			t.Errorf("got map: %v, want: %v", got.fileMap, wantMap)
+		}
+		wantFiles := map[string]string{"0": "photo", "1": "first", "2": "second"}
+		if !reflect.DeepEqual(got.files, wantFiles) {
+			t.Errorf("got files: %v, want: %v", got.files, wantFiles)
+		}
+		if got.names["0"] != "photo.png" {
+			t.Errorf("got file name: %q, want photo.png", got.names["0"])
+		}
+	})
+
+	t.Run("test upload variables for validation", func(t *testing.T) {
+		variables := map[string]any{"input": map[string]any{"title": "Docs"}}
+		got, err := utils.GraphQLUploadVariables(variables, uploads)
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		want := map[string]any{
+			"photo": "photo.png",
+			"cover": "photo.png",
+			"input": map[string]any{"title": "Docs", "docs": []any{"a.txt", "b.txt"}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got variables: %v, want: %v", got, want)
+		}
+		if _, ok := variables["photo"]; ok {
+			t.Errorf("got variables changed: %v, want them left as they were", variables)
+		}
+	})
+
+	t.Run("test invalid uploads", func(t *testing.T) {
+		for _, flag := range []string{"photo", "photo=@", "=@photo.png"} {
+			if _, err := utils.ParseGraphQLUpload(flag); err == nil {
+				t.Errorf("got no error for %q", flag)
+			}
+		}
+		_, err := utils.GraphQLUploadVariables(map[string]any{"photo": "x"}, []utils.GraphQLUpload{{Path: "variables.photo.0", File: photo}})
+		if err == nil {
+			t.Errorf("got no error for a path through a string")
+		}
+	})
+}
@@ -242,6 +242,42 @@ func TestGraphQLMutation(t *testing.T) {
 	})
 }
 
+func TestGraphQLGET(t *testing.T) {
+	var method string
+	var query, variables string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		query = r.URL.Query().Get("query")
+		variables = r.URL.Query().Get("variables")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"hello":"world"}}`))
+	}))
+	defer server.Close()
+
+	t.Run("test query sent with GET", func(t *testing.T) {
+		q := "query GetUser($id: ID!) { user(id: $id) { name } }"
+		resp, err := utils.GraphQLQuery(server.URL, q, utils.WithGET(), utils.WithVariables(map[string]any{"id": "1"}))
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		resp.Body.Close()
+		if method != http.MethodGet || query != q || variables != `{"id":"1"}` {
+			t.Errorf("got %s with query %q and variables %q, want GET with both", method, query, variables)
+		}
+	})
+
+	t.Run("test mutation still sent with POST", func(t *testing.T) {
+		resp, err := utils.GraphQLMutation(server.URL, "# Reset\nmutation { reset }", utils.WithGET())
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		resp.Body.Close()
+		if method != http.MethodPost {
+			t.Errorf("got method: %s, want POST", method)
+		}
+	})
+}
+
 func TestGraphQLErrorHandling(t *testing.T) {
 	server := setupGraphQLServer()
 	defer server.Close()
@@ -284,6 +320,50 @@ func TestGraphQLErrorHandling(t *testing.T) {
 	})
 }
 
+func TestGraphQLErrorDetails(t *testing.T) {
+	responseJSON := `{
+		"data": {"user": {"name": "Ada", "friends": [{"name": "Bob"}, null]}},
+		"errors": [{
+			"message": "Friend not found",
+			"locations": [{"line": 1, "column": 24}],
+			"path": ["user", "friends", 1],
+			"extensions": {"code": "NOT_FOUND", "classification": "DataFetchingException"}
+		}]
+	}`
+	resp := &http.Response{Body: io.NopCloser(bytes.NewBufferString(responseJSON))}
+
+	gqlResp, err := utils.ParseGraphQLResponse(resp)
+	if err != nil {
+		t.Fatalf("failed to parse response: %s", err.Error())
+	}
+	gqlErr := gqlResp.Errors[0]
+
+	t.Run("test extensions", func(t *testing.T) {
+		if gqlErr.Extensions["code"] != "NOT_FOUND" || gqlErr.Extensions["classification"] != "DataFetchingException" {
+			t.Errorf("got extensions: %v, want code and classification", gqlErr.Extensions)
+		}
+	})
+
+	t.Run("test path", func(t *testing.T) {
+		if got := utils.FormatGraphQLPath(gqlErr.Path); got != "user.friends[1]" {
+			t.Errorf("got path: %s, want: user.friends[1]", got)
+		}
+		value, ok := utils.GraphQLDataAt(gqlResp.Data, gqlErr.Path)
+		if !ok || value != nil {
+			t.Errorf("got value: %v, %v, want null in data", value, ok)
+		}
+		value, ok = utils.GraphQLDataAt(gqlResp.Data, []any{"user", "friends", 0, "name"})
+		if !ok || value != "Bob" {
+			t.Errorf("got value: %v, %v, want: Bob", value, ok)
+		}
+		for _, path := range [][]any{{"user", "age"}, {"user", "friends", 2}, {"user", "name", "first"}} {
+			if _, ok := utils.GraphQLDataAt(gqlResp.Data, path); ok {
+				t.Errorf("got a value for %v, want the path to lead nowhere", path)
+			}
+		}
+	})
+}
+
 func TestParseGraphQLResponse(t *testing.T) {
 	t.Run("test parse valid GraphQL response", func(t *testing.T) {
 		responseJSON := `{
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/suryanshu-09/hulaki/app"
+	"github.com/suryanshu-09/hulaki/utils"
+)
+
+var (
+	keyTab     = tea.KeyPressMsg{Code: tea.KeyTab}
+	keyNextTab = tea.KeyPressMsg{Code: tea.KeyRight, Mod: tea.ModCtrl}
+)
+
+// typeText types s into the focused input, pressing enter for newlines.
+func typeText(m tea.Model, s string) {
+	for _, r := range s {
+		if r == '\n' {
+			m.Update(keyEnter)
+			continue
+		}
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+}
+
+// sendRequest fills in the URL and the params, headers and auth tabs of a
+// request builder, sends the request and returns the builder's view.
+func sendRequest(t *testing.T, env *utils.Environment, url, params, headers, auth string) string {
+	t.Helper()
+	rb := app.NewRequestBuilder(env)
+	rb.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	typeText(rb, url)
+	rb.Update(keyTab)
+	for i, text := range []string{params, headers, auth} {
+		if i > 0 {
+			rb.Update(keyNextTab)
+		}
+		typeText(rb, text)
+	}
+
+	_, cmd := rb.Update(keySend)
+	if cmd == nil {
+		t.Fatal("got no command")
+	}
+	rb.Update(cmd())
+	return builderView(rb)
+}
+
+// builderView renders the builder as one line of words.
+func builderView(rb *app.RequestBuilder) string {
+	return strings.Join(strings.Fields(rb.View()), " ")
+}
+
+func TestRequestBuilder(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	}))
+	defer server.Close()
+
+	env := &utils.Environment{Name: "test", Variables: map[string]string{
+		"baseUrl": server.URL,
+		"user":    "alice",
+		"pass":    "s3cret",
+		"token":   "a.b.c",
+	}}
+
+	tests := []struct {
+		name                  string
+		params, headers, auth string
+		wantParams            map[string]string
+		wantHeaders           map[string]string
+	}{
+		{
+			name:        "test basic auth with variables",
+			auth:        "type=basic\nusername={{user}}\npassword={{pass}}",
+			wantHeaders: map[string]string{"Authorization": "Basic YWxpY2U6czNjcmV0"},
+		},
+		{
+			name:        "test basic password containing colon",
+			auth:        "type=Basic\nusername=user\npassword=a:b",
+			wantHeaders: map[string]string{"Authorization": "Basic dXNlcjphOmI="},
+		},
+		{
+			name:        "test bearer auth with variables",
+			headers:     "Accept: application/json",
+			auth:        "# bearer auth\ntype=bearer\ntoken={{token}}",
+			wantHeaders: map[string]string{"Accept": "application/json", "Authorization": "Bearer a.b.c"},
+		},
+		{
+			name:        "test values containing separators",
+			params:      "filter=name=john\n\n# skipped\ntoken=abc==",
+			headers:     "X-Url: http://example.com\n=novalue\nnokey",
+			wantParams:  map[string]string{"filter": "name=john", "token": "abc=="},
+			wantHeaders: map[string]string{"X-Url": "http://example.com", "Authorization": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			view := sendRequest(t, env, "{{baseUrl}}/users", tt.params, tt.headers, tt.auth)
+			if got == nil {
+				t.Fatalf("got no request:\n%s", view)
+			}
+			for key, want := range tt.wantParams {
+				if value := got.URL.Query().Get(key); value != want {
+					t.Errorf("got param %s: %q, want: %q", key, value, want)
+				}
+			}
+			for key, want := range tt.wantHeaders {
+				if value := got.Header.Get(key); value != want {
+					t.Errorf("got header %s: %q, want: %q", key, value, want)
+				}
+			}
+		})
+	}
+
+	failures := []struct {
+		name, auth, want string
+	}{
+		{"test missing auth type", "token=abc", "missing type"},
+		{"test unknown auth type", "type=digest", "unsupported type"},
+		{"test unknown variable in auth", "type=basic\nusername={{missing}}", "missing"},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			view := sendRequest(t, env, "{{baseUrl}}/users", "", "", tt.auth)
+			if got != nil {
+				t.Error("got a request despite the error")
+			}
+			if !strings.Contains(view, tt.want) {
+				t.Errorf("got view without %q:\n%s", tt.want, view)
+			}
+		})
+	}
+}
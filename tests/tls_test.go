@@ -0,0 +1,226 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/suryanshu-09/hulaki/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// testCA is a throwaway certificate authority used to issue test
+// certificates in memory.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err.Error())
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "hulaki test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %s", err.Error())
+	}
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pool: pool,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue signs a certificate for the given DNS name, valid for both server
+// and client authentication. It returns the certificate and its PEM form.
+func (ca *testCA) issue(t *testing.T, name string) (tls.Certificate, []byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err.Error())
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if name == "localhost" {
+		tmpl.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err.Error())
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("failed to load certificate: %s", err.Error())
+	}
+	return cert, certPEM, keyPEM
+}
+
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %s", name, err.Error())
+	}
+	return path
+}
+
+func echoCall(addr string, args ...utils.Args) *utils.GRPCResponse {
+	args = append(args, utils.WithBody(bytes.NewBufferString(`{"message":"secure"}`)))
+	resp, _ := utils.GRPCCall(addr, "hulaki.test.EchoService", "Echo", args...)
+	return resp
+}
+
+func TestGRPCTLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, _, _ := ca.issue(t, "localhost")
+
+	addr := startEchoServer(t, echoServerOptions{
+		reflectionV1: true,
+		serverOptions: []grpc.ServerOption{
+			grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{serverCert}})),
+		},
+	})
+
+	t.Run("test TLS with a trusted CA", func(t *testing.T) {
+		resp := echoCall(addr, utils.WithTLSConfig(&tls.Config{RootCAs: ca.pool}))
+		if resp.Error != nil {
+			t.Fatalf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+		if resp.Data.(map[string]any)["message"] != "secure" {
+			t.Errorf("got: %v", resp.Data)
+		}
+	})
+
+	t.Run("test plaintext against a TLS server", func(t *testing.T) {
+		if resp := echoCall(addr); resp.Error == nil {
+			t.Errorf("expected a connection error")
+		}
+	})
+
+	t.Run("test untrusted server certificate", func(t *testing.T) {
+		if resp := echoCall(addr, utils.WithTLSConfig(&tls.Config{})); resp.Error == nil {
+			t.Errorf("expected a certificate error")
+		}
+	})
+
+	t.Run("test insecure skip verify", func(t *testing.T) {
+		resp := echoCall(addr, utils.WithTLSConfig(&tls.Config{InsecureSkipVerify: true}))
+		if resp.Error != nil {
+			t.Errorf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+	})
+
+	t.Run("test CA certificate from a file", func(t *testing.T) {
+		cfg, err := utils.LoadTLSConfig(utils.TLSOptions{CACert: writeFile(t, t.TempDir(), "ca.pem", ca.pem)})
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if resp := echoCall(addr, utils.WithTLSConfig(cfg)); resp.Error != nil {
+			t.Errorf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+	})
+}
+
+func TestGRPCServerNameOverride(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, _, _ := ca.issue(t, "echo.hulaki.test")
+
+	addr := startEchoServer(t, echoServerOptions{
+		reflectionV1: true,
+		serverOptions: []grpc.ServerOption{
+			grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{serverCert}})),
+		},
+	})
+
+	if resp := echoCall(addr, utils.WithTLSConfig(&tls.Config{RootCAs: ca.pool})); resp.Error == nil {
+		t.Errorf("expected a name mismatch without --servername")
+	}
+	resp := echoCall(addr, utils.WithTLSConfig(&tls.Config{RootCAs: ca.pool, ServerName: "echo.hulaki.test"}))
+	if resp.Error != nil {
+		t.Errorf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+	}
+}
+
+func TestGRPCMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, _, _ := ca.issue(t, "localhost")
+	_, clientPEM, clientKeyPEM := ca.issue(t, "client")
+
+	addr := startEchoServer(t, echoServerOptions{
+		reflectionV1: true,
+		serverOptions: []grpc.ServerOption{
+			grpc.Creds(credentials.NewTLS(&tls.Config{
+				Certificates: []tls.Certificate{serverCert},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    ca.pool,
+			})),
+		},
+	})
+
+	dir := t.TempDir()
+	caFile := writeFile(t, dir, "ca.pem", ca.pem)
+	certFile := writeFile(t, dir, "client.pem", clientPEM)
+	keyFile := writeFile(t, dir, "client-key.pem", clientKeyPEM)
+
+	t.Run("test without a client certificate", func(t *testing.T) {
+		cfg, _ := utils.LoadTLSConfig(utils.TLSOptions{CACert: caFile})
+		if resp := echoCall(addr, utils.WithTLSConfig(cfg)); resp.Error == nil {
+			t.Errorf("expected the server to reject the call")
+		}
+	})
+
+	t.Run("test with a client certificate", func(t *testing.T) {
+		cfg, err := utils.LoadTLSConfig(utils.TLSOptions{CACert: caFile, Cert: certFile, Key: keyFile})
+		if err != nil {
+			t.Fatalf("got an error: %s", err.Error())
+		}
+		if resp := echoCall(addr, utils.WithTLSConfig(cfg)); resp.Error != nil {
+			t.Errorf("got a gRPC error: %s: %s", resp.Error.Code, resp.Error.Message)
+		}
+	})
+
+	t.Run("test certificate without a key", func(t *testing.T) {
+		if _, err := utils.LoadTLSConfig(utils.TLSOptions{Cert: certFile}); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
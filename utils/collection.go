@@ -0,0 +1,317 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Protocols a collection request can be sent over.
+const (
+	ProtocolHTTP     = "http"
+	ProtocolGraphQL  = "graphql"
+	ProtocolGRPC     = "grpc"
+	ProtocolWS       = "ws"
+	ProtocolSocketIO = "socketio"
+)
+
+// CollectionRequest is a saved, named request. One request is stored per
+// file inside its collection directory, as YAML (.yaml/.yml) or JSON (.json).
+type CollectionRequest struct {
+	Name      string            `yaml:"name" json:"name"`
+	Protocol  string            `yaml:"protocol" json:"protocol"`
+	URL       string            `yaml:"url" json:"url"`
+	Method    string            `yaml:"method,omitempty" json:"method,omitempty"`
+	Service   string            `yaml:"service,omitempty" json:"service,omitempty"`
+	Event     string            `yaml:"event,omitempty" json:"event,omitempty"`
+	Namespace string            `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Headers   map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Params    map[string]string `yaml:"params,omitempty" json:"params,omitempty"`
+	Body      string            `yaml:"body,omitempty" json:"body,omitempty"`
+	Query     string            `yaml:"query,omitempty" json:"query,omitempty"`
+	Variables map[string]any    `yaml:"variables,omitempty" json:"variables,omitempty"`
+}
+
+var collectionExts = []string{".yaml", ".yml", ".json"}
+
+// HomeDir returns the directory hulaki keeps its state in. It can be
+// overridden with the HULAKI_HOME environment variable.
+func HomeDir() (string, error) {
+	if home := os.Getenv("HULAKI_HOME"); home != "" {
+		return home, nil
+	}
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(config, "hulaki"), nil
+}
+
+// CollectionsDir returns the directory named collections are stored in.
+func CollectionsDir() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "collections"), nil
+}
+
+// CollectionPath resolves a collection name to its directory. Names that look
+// like paths (absolute, or starting with ".") are used as-is so collections
+// can live in a project repository.
+func CollectionPath(collection string) (string, error) {
+	if collection == "" {
+		return "", errors.New("collection name cannot be empty")
+	}
+	if isCollectionPath(collection) {
+		return collection, nil
+	}
+	if strings.ContainsAny(collection, `/\`) {
+		return "", fmt.Errorf("invalid collection name %q, names cannot contain a path separator", collection)
+	}
+	dir, err := CollectionsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, collection), nil
+}
+
+func isCollectionPath(collection string) bool {
+	return filepath.IsAbs(collection) || strings.HasPrefix(collection, ".")
+}
+
+// SplitCollectionRef splits "collection/request" into its two parts. A named
+// collection ends at the first "/" and the request cannot contain another
+// one. For collections given as paths the request is the last path element,
+// and the path must name a directory other than "." or "..", so "./api" is
+// not mistaken for request "api" in the current directory.
+func SplitCollectionRef(ref string) (collection, request string, err error) {
+	invalid := fmt.Errorf("invalid request reference %q, expected <collection>/<request>", ref)
+	if isCollectionPath(ref) {
+		i := strings.LastIndex(ref, "/")
+		if i < 0 {
+			return "", "", invalid
+		}
+		collection, request = strings.TrimRight(ref[:i], "/"), ref[i+1:]
+		if collection == "" || collection == "." || collection == ".." || request == "" {
+			return "", "", invalid
+		}
+		return collection, request, nil
+	}
+	collection, request, ok := strings.Cut(ref, "/")
+	if !ok || collection == "" || request == "" || strings.Contains(request, "/") {
+		return "", "", invalid
+	}
+	return collection, request, nil
+}
+
+func findRequestFile(dir, name string) (string, error) {
+	for _, ext := range collectionExts {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("request %q not found in %s", name, dir)
+}
+
+// SaveCollectionRequest writes req into the collection, creating the
+// collection if needed. An existing request with the same name is replaced.
+func SaveCollectionRequest(collection string, req *CollectionRequest) error {
+	if req.Name == "" || strings.ContainsAny(req.Name, `/\`) {
+		return fmt.Errorf("invalid request name %q", req.Name)
+	}
+	if err := ValidateCollectionRequest(req); err != nil {
+		return err
+	}
+	dir, err := CollectionPath(collection)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if old, err := findRequestFile(dir, req.Name); err == nil {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+	data, err := yaml.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, req.Name+".yaml"), data, 0o644)
+}
+
+// LoadCollectionRequest reads a single request from a collection.
+func LoadCollectionRequest(collection, name string) (*CollectionRequest, error) {
+	dir, err := CollectionPath(collection)
+	if err != nil {
+		return nil, err
+	}
+	path, err := findRequestFile(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	return ReadCollectionRequest(path)
+}
+
+// ReadCollectionRequest parses a request file. The format is picked from the
+// file extension.
+func ReadCollectionRequest(path string) (*CollectionRequest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	req := &CollectionRequest{}
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(data, req)
+	} else {
+		err = yaml.Unmarshal(data, req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if req.Name == "" {
+		req.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := ValidateCollectionRequest(req); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return req, nil
+}
+
+// ValidateCollectionRequest checks that req has what its protocol needs.
+func ValidateCollectionRequest(req *CollectionRequest) error {
+	if req.URL == "" {
+		return errors.New("request url cannot be empty")
+	}
+	switch req.Protocol {
+	case ProtocolHTTP:
+		if req.Method == "" {
+			req.Method = "GET"
+		}
+		req.Method = strings.ToUpper(req.Method)
+	case ProtocolGraphQL:
+		if req.Query == "" {
+			return errors.New("graphql requests need a query")
+		}
+	case ProtocolGRPC:
+		if req.Service == "" || req.Method == "" {
+			return errors.New("grpc requests need a service and a method")
+		}
+	case ProtocolWS, ProtocolSocketIO:
+	default:
+		return fmt.Errorf("unknown protocol %q (must be http, graphql, grpc, ws or socketio)", req.Protocol)
+	}
+	return nil
+}
+
+// ListCollections returns the names of all stored collections.
+func ListCollections() ([]string, error) {
+	dir, err := CollectionsDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	collections := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			collections = append(collections, entry.Name())
+		}
+	}
+	return collections, nil
+}
+
+// ListCollectionRequests returns the names of the requests in a collection.
+func ListCollectionRequests(collection string) ([]string, error) {
+	dir, err := CollectionPath(collection)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("collection %q does not exist", collection)
+		}
+		return nil, err
+	}
+	requests := make([]string, 0)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || !slices.Contains(collectionExts, ext) {
+			continue
+		}
+		requests = append(requests, strings.TrimSuffix(entry.Name(), ext))
+	}
+	return requests, nil
+}
+
+// RemoveCollectionRequest deletes a single request from a collection.
+func RemoveCollectionRequest(collection, name string) error {
+	dir, err := CollectionPath(collection)
+	if err != nil {
+		return err
+	}
+	path, err := findRequestFile(dir, name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// RemoveCollection deletes a collection and every request in it. Named
+// collections are removed from the collections directory. Collections given
+// as paths are removed only when they hold nothing but request files, so a
+// path that is not a collection is never deleted.
+func RemoveCollection(collection string) error {
+	dir, err := CollectionPath(collection)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("collection %q does not exist", collection)
+	}
+	if !isCollectionPath(collection) {
+		root, err := CollectionsDir()
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(root, dir); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("collection %q is not in %s", collection, root)
+		}
+		return os.RemoveAll(dir)
+	}
+
+	clean := filepath.Clean(dir)
+	if clean == "." || clean == ".." || clean == filepath.Dir(clean) {
+		return fmt.Errorf("refusing to remove %q, it is not a collection", collection)
+	}
+	entries, err := os.ReadDir(clean)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !slices.Contains(collectionExts, filepath.Ext(entry.Name())) {
+			return fmt.Errorf("refusing to remove %q, it holds %s which is not a request", collection, entry.Name())
+		}
+	}
+	for _, entry := range entries {
+		if err := os.Remove(filepath.Join(clean, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Remove(clean)
+}
@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment is a named set of variables that are substituted into
+// requests wherever a {{variable}} placeholder appears.
+type Environment struct {
+	Name      string            `yaml:"name"`
+	Variables map[string]string `yaml:"variables"`
+}
+
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// EnvironmentsDir returns the directory environments are stored in.
+func EnvironmentsDir() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "environments"), nil
+}
+
+func environmentPath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid environment name %q", name)
+	}
+	dir, err := EnvironmentsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".yaml"), nil
+}
+
+// CreateEnvironment creates a new, empty environment.
+func CreateEnvironment(name string) (*Environment, error) {
+	path, err := environmentPath(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("environment %q already exists", name)
+	}
+	env := &Environment{Name: name, Variables: make(map[string]string)}
+	return env, SaveEnvironment(env)
+}
+
+// LoadEnvironment reads a stored environment.
+func LoadEnvironment(name string) (*Environment, error) {
+	path, err := environmentPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("environment %q does not exist", name)
+		}
+		return nil, err
+	}
+	env := &Environment{}
+	if err := yaml.Unmarshal(data, env); err != nil {
+		return nil, fmt.Errorf("failed to parse environment %q: %w", name, err)
+	}
+	env.Name = name
+	if env.Variables == nil {
+		env.Variables = make(map[string]string)
+	}
+	return env, nil
+}
+
+// SaveEnvironment writes env, replacing any stored copy.
+func SaveEnvironment(env *Environment) error {
+	path, err := environmentPath(env.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// ListEnvironments returns the names of all stored environments.
+func ListEnvironments() ([]string, error) {
+	dir, err := EnvironmentsDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	envs := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".yaml" {
+			envs = append(envs, strings.TrimSuffix(entry.Name(), ".yaml"))
+		}
+	}
+	return envs, nil
+}
+
+// RemoveEnvironment deletes a stored environment.
+func RemoveEnvironment(name string) error {
+	path, err := environmentPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("environment %q does not exist", name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Interpolate replaces every {{variable}} in s with its value. A nil
+// environment has no variables. Unknown variables are reported as an error
+// rather than being left in place.
+func (e *Environment) Interpolate(s string) (string, error) {
+	var missing []string
+	out := variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if e != nil {
+			if value, ok := e.Variables[name]; ok {
+				return value
+			}
+		}
+		if !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+		return match
+	})
+	if len(missing) == 0 {
+		return out, nil
+	}
+	if e == nil {
+		return "", fmt.Errorf("unknown variable(s) %s: no environment selected", strings.Join(missing, ", "))
+	}
+	return "", fmt.Errorf("unknown variable(s) %s in environment %q", strings.Join(missing, ", "), e.Name)
+}
+
+// InterpolateMap interpolates the keys and values of m into a new map.
+func (e *Environment) InterpolateMap(m map[string]string) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+	out := make(map[string]string, len(m))
+	for key, value := range m {
+		k, err := e.Interpolate(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := e.Interpolate(value)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// InterpolateValue interpolates every string inside a decoded JSON value.
+func (e *Environment) InterpolateValue(v any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return e.Interpolate(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			k, err := e.Interpolate(key)
+			if err != nil {
+				return nil, err
+			}
+			if out[k], err = e.InterpolateValue(value); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			var err error
+			if out[i], err = e.InterpolateValue(value); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// InterpolateJSON interpolates every string inside a JSON document. The
+// document is decoded first, so values holding quotes or backslashes are
+// escaped rather than breaking it, and numbers are kept as written so
+// 64-bit integers survive the round trip.
+func (e *Environment) InterpolateJSON(raw []byte) (json.RawMessage, error) {
+	var v any
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	v, err := e.InterpolateValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
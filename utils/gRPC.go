@@ -2,20 +2,36 @@ package utils
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	// Registers the google.rpc error detail types so status details decode.
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
 )
 
+// defaultConnectTimeout bounds how long TestConnection waits for the
+// connection to become ready when no connect timeout is given.
+const defaultConnectTimeout = 1 * time.Second
+
+// GRPCClient is a connection to a gRPC server. It can be kept for a series
+// of calls, which then share the connection and the descriptors resolved
+// through reflection.
 type GRPCClient struct {
-	conn *grpc.ClientConn
-	ctx  context.Context
+	address        string
+	arg            Arg
+	conn           *grpc.ClientConn
+	ctx            context.Context
+	reflection     *reflectionSource
+	timeout        time.Duration
+	connectTimeout time.Duration
 }
 
 type GRPCRequest struct {
@@ -24,22 +40,33 @@ type GRPCRequest struct {
 	Data    map[string]any `json:"data,omitempty"`
 }
 
+// GRPCResponse holds the result of a call. Metadata holds the response
+// headers, and Trailers the metadata sent with the final status.
 type GRPCResponse struct {
 	Data     any         `json:"data"`
 	Metadata metadata.MD `json:"metadata,omitempty"`
+	Trailers metadata.MD `json:"trailers,omitempty"`
 	Error    *GRPCError  `json:"error,omitempty"`
 }
 
+// GRPCError is a failed call. Code is the canonical status code name, e.g.
+// INVALID_ARGUMENT, and Details holds the decoded google.rpc.Status details.
 type GRPCError struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	Details []any  `json:"details,omitempty"`
 }
 
 func NewGRPCClient(address string, args ...Args) (*GRPCClient, error) {
-	_, _, headers := GetArgs(args)
+	arg := collectArgs(args)
+	headers := arg.Headers
 
+	creds := insecure.NewCredentials()
+	if arg.TLS != nil {
+		creds = credentials.NewTLS(arg.TLS)
+	}
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 	}
 
 	conn, err := grpc.Dial(address, opts...)
@@ -53,18 +80,65 @@ func NewGRPCClient(address string, args ...Args) (*GRPCClient, error) {
 		ctx = metadata.NewOutgoingContext(ctx, md)
 	}
 
+	connectTimeout := arg.ConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = defaultConnectTimeout
+	}
+
+	// Each reflection request is bounded by the call timeout, or the
+	// connect timeout when there is none.
+	reflectionTimeout := arg.Timeout
+	if reflectionTimeout <= 0 {
+		reflectionTimeout = connectTimeout
+	}
+
 	return &GRPCClient{
-		conn: conn,
-		ctx:  ctx,
+		address:        address,
+		arg:            arg,
+		conn:           conn,
+		ctx:            ctx,
+		reflection:     newReflectionSource(ctx, conn, reflectionTimeout),
+		timeout:        arg.Timeout,
+		connectTimeout: connectTimeout,
 	}, nil
 }
 
 func (c *GRPCClient) Close() error {
-	return c.conn.Close()
+	// Closing the connection first ends a reflection request in flight.
+	err := c.conn.Close()
+	c.reflection.Close()
+	return err
+}
+
+// Descriptors returns the descriptors given with WithDescriptorSource,
+// falling back to server reflection.
+func (c *GRPCClient) Descriptors() DescriptorSource {
+	if c.arg.Descriptors != nil {
+		return c.arg.Descriptors
+	}
+	return c.reflection
+}
+
+// Reflection returns a DescriptorSource that asks the server for its
+// descriptors through the reflection service. It is safe for concurrent
+// use, and its stream is closed by Close.
+func (c *GRPCClient) Reflection() DescriptorSource {
+	return c.reflection
+}
+
+// callContext returns the context for a single RPC, carrying the call
+// deadline when a timeout was given.
+func (c *GRPCClient) callContext() (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(c.ctx, c.timeout)
+	}
+	return context.WithCancel(c.ctx)
 }
 
+// TestConnection waits for the connection to become ready, for at most the
+// connect timeout.
 func (c *GRPCClient) TestConnection() error {
-	ctx, cancel := context.WithTimeout(c.ctx, 1*time.Second)
+	ctx, cancel := context.WithTimeout(c.ctx, c.connectTimeout)
 	defer cancel()
 
 	state := c.conn.GetState()
@@ -79,7 +153,7 @@ func (c *GRPCClient) TestConnection() error {
 	for {
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("connection timeout")
+			return fmt.Errorf("connection timeout after %s", c.connectTimeout)
 		default:
 			state = c.conn.GetState()
 			if state == connectivity.Ready {
@@ -92,90 +166,242 @@ func (c *GRPCClient) TestConnection() error {
 		}
 	}
 }
-func GRPCCall(address, service, method string, args ...Args) (*GRPCResponse, error) {
+
+// connectGRPCClient creates a client and waits for the connection to be
+// ready. Failures are returned as a ready-made error response.
+func connectGRPCClient(address string, args []Args) (*GRPCClient, *GRPCResponse) {
 	client, err := NewGRPCClient(address, args...)
 	if err != nil {
-		return &GRPCResponse{
-			Error: &GRPCError{
-				Code:    "CONNECTION_ERROR",
-				Message: err.Error(),
-			},
-		}, nil
+		return nil, &GRPCResponse{
+			Error: newGRPCError(codes.Unavailable, "%v", err),
+		}
 	}
-	defer client.Close()
 
 	if err := client.TestConnection(); err != nil {
-		return &GRPCResponse{
-			Error: &GRPCError{
-				Code:    "CONNECTION_FAILED",
-				Message: fmt.Sprintf("failed to connect to gRPC server: %v", err),
-			},
-		}, nil
+		client.Close()
+		return nil, &GRPCResponse{
+			Error: newGRPCError(codes.Unavailable, "failed to connect to gRPC server: %v", err),
+		}
 	}
+	return client, nil
+}
 
+// GRPCCall invokes service/method on the server at address, reading the
+// request JSON from the body. Client-streaming methods read a sequence of
+// JSON messages from the body; server-streaming methods collect every
+// response into a list.
+func GRPCCall(address, service, method string, args ...Args) (*GRPCResponse, error) {
 	body, _, _ := GetArgs(args)
-	var requestData map[string]any
-
-	if body != nil {
-		decoder := json.NewDecoder(body)
-		if err := decoder.Decode(&requestData); err != nil && err != io.EOF {
-			return &GRPCResponse{
-				Error: &GRPCError{
-					Code:    "INVALID_REQUEST",
-					Message: fmt.Sprintf("failed to decode request body: %v", err),
-				},
-			}, nil
+	messages := make([]any, 0)
+	resp, err := GRPCStreamCall(address, service, method, JSONRequestSource(body), func(msg any) {
+		messages = append(messages, msg)
+	}, args...)
+	if err != nil || resp.Error != nil {
+		return resp, err
+	}
+	if resp.Data == nil {
+		resp.Data = messages
+	}
+	return resp, nil
+}
+
+// newGRPCError builds a GRPCError for a failure detected on the client side.
+func newGRPCError(code codes.Code, format string, a ...any) *GRPCError {
+	return &GRPCError{
+		Code:    statusCodeName(code),
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+// grpcStatusError converts an RPC error into a GRPCError carrying the
+// canonical status code name, e.g. NOT_FOUND, and the status details.
+func grpcStatusError(err error) *GRPCError {
+	st := status.Convert(err)
+	return &GRPCError{
+		Code:    statusCodeName(st.Code()),
+		Message: st.Message(),
+		Details: statusDetails(st),
+	}
+}
+
+// lookupError converts a failure to find a symbol into a GRPCError. Errors
+// from the server, such as a reflection request that timed out or a server
+// without reflection, keep their status code; anything else means the
+// symbol is missing.
+func lookupError(err error) *GRPCError {
+	if st, ok := status.FromError(err); ok {
+		return newGRPCError(st.Code(), "%v", err)
+	}
+	return newGRPCError(codes.NotFound, "%v", err)
+}
+
+// statusDetails decodes the details of a google.rpc.Status, such as
+// BadRequest, ErrorInfo or RetryInfo, into JSON values tagged with their
+// "@type". Details of unknown types are kept as raw bytes.
+func statusDetails(st *status.Status) []any {
+	var details []any
+	for _, detail := range st.Proto().GetDetails() {
+		value, err := MessageToJSON(detail)
+		if err != nil {
+			value = map[string]any{
+				"@type": detail.GetTypeUrl(),
+				"value": detail.GetValue(),
+			}
 		}
+		details = append(details, value)
+	}
+	return details
+}
+
+// statusCodeNames are the canonical names of the status codes, as used in
+// the gRPC specification. They differ from codes.Code.String() in spelling
+// (CANCELLED) as well as case.
+var statusCodeNames = [...]string{
+	codes.OK:                 "OK",
+	codes.Canceled:           "CANCELLED",
+	codes.Unknown:            "UNKNOWN",
+	codes.InvalidArgument:    "INVALID_ARGUMENT",
+	codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+	codes.NotFound:           "NOT_FOUND",
+	codes.AlreadyExists:      "ALREADY_EXISTS",
+	codes.PermissionDenied:   "PERMISSION_DENIED",
+	codes.ResourceExhausted:  "RESOURCE_EXHAUSTED",
+	codes.FailedPrecondition: "FAILED_PRECONDITION",
+	codes.Aborted:            "ABORTED",
+	codes.OutOfRange:         "OUT_OF_RANGE",
+	codes.Unimplemented:      "UNIMPLEMENTED",
+	codes.Internal:           "INTERNAL",
+	codes.Unavailable:        "UNAVAILABLE",
+	codes.DataLoss:           "DATA_LOSS",
+	codes.Unauthenticated:    "UNAUTHENTICATED",
+}
+
+// statusCodeName returns the canonical name of a status code. Codes outside
+// the specification are named by their number.
+func statusCodeName(code codes.Code) string {
+	if int(code) < len(statusCodeNames) {
+		return statusCodeNames[code]
+	}
+	return fmt.Sprintf("CODE(%d)", code)
+}
+
+// GRPCReflect lists the services of the server at address. When local
+// descriptors are given with WithDescriptorSource they are listed instead,
+// without connecting.
+func GRPCReflect(address string, args ...Args) (*GRPCResponse, error) {
+	source, closer, errResp := grpcDescriptorSource(address, args)
+	if errResp != nil {
+		return errResp, nil
+	}
+	defer closer()
+
+	services, err := source.ListServices()
+	if err != nil {
+		st := status.Convert(err)
+		return &GRPCResponse{
+			Error: newGRPCError(st.Code(), "failed to list services: %s", st.Message()),
+		}, nil
 	}
 
 	return &GRPCResponse{
 		Data: map[string]any{
-			"service":    service,
-			"method":     method,
-			"request":    requestData,
-			"status":     "connected",
-			"connection": "established",
-			"address":    address,
+			"address":  address,
+			"services": services,
 		},
 	}, nil
 }
 
-func GRPCReflect(address string, args ...Args) (*GRPCResponse, error) {
-	client, err := NewGRPCClient(address, args...)
+// GRPCDescribe describes a service, method, message or enum exposed by the
+// server at address, or found in the local descriptors given with
+// WithDescriptorSource.
+func GRPCDescribe(address, symbol string, args ...Args) (*GRPCResponse, error) {
+	source, closer, errResp := grpcDescriptorSource(address, args)
+	if errResp != nil {
+		return errResp, nil
+	}
+	defer closer()
+
+	d, err := source.FindSymbol(symbol)
 	if err != nil {
 		return &GRPCResponse{
-			Error: &GRPCError{
-				Code:    "CONNECTION_ERROR",
-				Message: err.Error(),
-			},
+			Error: lookupError(err),
 		}, nil
 	}
-	defer client.Close()
 
-	if err := client.TestConnection(); err != nil {
+	return &GRPCResponse{
+		Data: DescribeDescriptor(d),
+	}, nil
+}
+
+// GRPCTemplate returns a sample request for the method named by symbol,
+// e.g. "pkg.Service/Method", to use as a starting point for --data. The
+// method is looked up like GRPCDescribe does.
+func GRPCTemplate(address, symbol string, args ...Args) (*GRPCResponse, error) {
+	service, method, err := splitMethodSymbol(symbol)
+	if err != nil {
 		return &GRPCResponse{
-			Error: &GRPCError{
-				Code:    "CONNECTION_FAILED",
-				Message: fmt.Sprintf("failed to connect to gRPC server: %v", err),
-			},
+			Error: newGRPCError(codes.InvalidArgument, "%v", err),
+		}, nil
+	}
+
+	source, closer, errResp := grpcDescriptorSource(address, args)
+	if errResp != nil {
+		return errResp, nil
+	}
+	defer closer()
+
+	md, err := ResolveMethod(source, service, method)
+	if err != nil {
+		return &GRPCResponse{
+			Error: lookupError(err),
 		}, nil
 	}
 
 	return &GRPCResponse{
-		Data: map[string]any{
-			"address":    address,
-			"status":     "connected",
-			"reflection": "available",
-			"services":   []string{"Service reflection would be implemented here"},
-		},
+		Data: MessageTemplate(md.Input()),
 	}, nil
 }
 
+// grpcDescriptorSource returns the local descriptors from args, or connects
+// to address and uses server reflection. Connection failures are returned
+// as a ready-made error response.
+func grpcDescriptorSource(address string, args []Args) (DescriptorSource, func(), *GRPCResponse) {
+	if src := collectArgs(args).Descriptors; src != nil {
+		return src, func() {}, nil
+	}
+
+	client, errResp := connectGRPCClient(address, args)
+	if errResp != nil {
+		return nil, nil, errResp
+	}
+
+	return client.Reflection(), func() { client.Close() }, nil
+}
+
+// WithDescriptorSource resolves services and messages from src instead of
+// asking the server through reflection.
+func WithDescriptorSource(src DescriptorSource) Args {
+	return func(arg *Arg) {
+		arg.Descriptors = src
+	}
+}
+
 func WithMetadata(md map[string]string) Args {
 	return WithHeaders(md)
 }
 
+// WithGRPCTimeout sets the deadline of each call. A call that runs past it
+// fails with DEADLINE_EXCEEDED.
 func WithGRPCTimeout(timeout time.Duration) Args {
 	return func(arg *Arg) {
+		arg.Timeout = timeout
+	}
+}
+
+// WithGRPCConnectTimeout bounds how long to wait for the connection to the
+// server to be established. It defaults to one second.
+func WithGRPCConnectTimeout(timeout time.Duration) Args {
+	return func(arg *Arg) {
+		arg.ConnectTimeout = timeout
 	}
 }
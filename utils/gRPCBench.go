@@ -0,0 +1,317 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// benchHistogramBuckets is the number of equal-width buckets latencies are
+// counted in.
+const benchHistogramBuckets = 10
+
+// GRPCBenchOptions controls a load test. The run stops after Requests calls
+// or once Duration has passed, whichever comes first; at least one of them
+// must be set.
+type GRPCBenchOptions struct {
+	// Concurrency is the number of calls in flight at once.
+	Concurrency int
+	// Requests is the total number of calls to make.
+	Requests int
+	// Duration bounds how long new calls are started for.
+	Duration time.Duration
+	// RPS caps the rate calls are started at. Zero means no limit.
+	RPS int
+	// Connections is the number of connections the calls are spread over.
+	// It defaults to one shared connection.
+	Connections int
+}
+
+// GRPCBenchReport summarises a load test. Latencies are in milliseconds.
+type GRPCBenchReport struct {
+	Count       int               `json:"count"`
+	TotalMs     float64           `json:"totalMs"`
+	RPS         float64           `json:"rps"`
+	Latency     GRPCBenchLatency  `json:"latency"`
+	Histogram   []GRPCBenchBucket `json:"histogram"`
+	StatusCodes map[string]int    `json:"statusCodes"`
+	Errors      map[string]int    `json:"errors,omitempty"`
+	Options     GRPCBenchSetup    `json:"options"`
+}
+
+// GRPCBenchLatency holds the latency statistics of a load test.
+type GRPCBenchLatency struct {
+	FastestMs float64 `json:"fastestMs"`
+	SlowestMs float64 `json:"slowestMs"`
+	AverageMs float64 `json:"averageMs"`
+	P50Ms     float64 `json:"p50Ms"`
+	P90Ms     float64 `json:"p90Ms"`
+	P99Ms     float64 `json:"p99Ms"`
+}
+
+// GRPCBenchBucket counts the calls that took at most UpToMs and longer than
+// the previous bucket.
+type GRPCBenchBucket struct {
+	UpToMs float64 `json:"upToMs"`
+	Count  int     `json:"count"`
+}
+
+// GRPCBenchSetup records how the load test was run, so reports can be
+// compared.
+type GRPCBenchSetup struct {
+	Method      string `json:"method"`
+	Concurrency int    `json:"concurrency"`
+	Connections int    `json:"connections"`
+	Requests    int    `json:"requests,omitempty"`
+	Duration    string `json:"duration,omitempty"`
+	RPS         int    `json:"rps,omitempty"`
+}
+
+// benchResult is the outcome of a single call.
+type benchResult struct {
+	latency time.Duration
+	err     error
+}
+
+// GRPCBench calls the method named by symbol, e.g. "pkg.Service/Method",
+// over and over as opts describe, and reports the latencies and status
+// codes. Every call sends the messages read from requests; streamed
+// responses are read to the end.
+func GRPCBench(address, symbol string, requests GRPCRequestSource, opts GRPCBenchOptions, args ...Args) (*GRPCBenchReport, error) {
+	service, method, err := splitMethodSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Requests <= 0 && opts.Duration <= 0 {
+		return nil, errors.New("a number of requests or a duration is required")
+	}
+	opts.Concurrency = max(opts.Concurrency, 1)
+	opts.Connections = min(max(opts.Connections, 1), opts.Concurrency)
+
+	clients := make([]*GRPCClient, 0, opts.Connections)
+	defer func() {
+		for _, client := range clients {
+			client.Close()
+		}
+	}()
+	for range opts.Connections {
+		client, errResp := connectGRPCClient(address, args)
+		if errResp != nil {
+			return nil, errors.New(errResp.Error.Message)
+		}
+		clients = append(clients, client)
+	}
+
+	md, err := ResolveMethod(clients[0].Descriptors(), service, method)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := benchMessages(md, requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode request body: %w", err)
+	}
+
+	ctx := context.Background()
+	cancel := context.CancelFunc(func() {})
+	if opts.Duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+	}
+	defer cancel()
+
+	// The producer hands out one token per call, paced to the rate limit.
+	jobs := make(chan struct{})
+	go func() {
+		defer close(jobs)
+		var tick <-chan time.Time
+		if opts.RPS > 0 {
+			ticker := time.NewTicker(time.Second / time.Duration(opts.RPS))
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for i := 0; opts.Requests <= 0 || i < opts.Requests; i++ {
+			if tick != nil {
+				select {
+				case <-tick:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case jobs <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	results := make([][]benchResult, opts.Concurrency)
+	start := time.Now()
+	for worker := range opts.Concurrency {
+		client := clients[worker%len(clients)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				callStart := time.Now()
+				err := benchCall(client, md, messages)
+				results[worker] = append(results[worker], benchResult{latency: time.Since(callStart), err: err})
+			}
+		}()
+	}
+	wg.Wait()
+	total := time.Since(start)
+
+	report := newBenchReport(slices.Concat(results...), total)
+	report.Options = GRPCBenchSetup{
+		Method:      string(md.FullName()),
+		Concurrency: opts.Concurrency,
+		Connections: opts.Connections,
+		Requests:    opts.Requests,
+		RPS:         opts.RPS,
+	}
+	if opts.Duration > 0 {
+		report.Options.Duration = opts.Duration.String()
+	}
+	return report, nil
+}
+
+// benchMessages decodes the request messages once, so every call sends the
+// same ones. Methods without client streaming send only the first.
+func benchMessages(md protoreflect.MethodDescriptor, requests GRPCRequestSource) ([]proto.Message, error) {
+	var messages []proto.Message
+	for {
+		raw, err := requests()
+		if errors.Is(err, io.EOF) {
+			if len(messages) == 0 && !md.IsStreamingClient() {
+				return []proto.Message{dynamicpb.NewMessage(md.Input())}, nil
+			}
+			return messages, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		msg, err := JSONToMessage(md.Input(), raw)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+		if !md.IsStreamingClient() {
+			return messages, nil
+		}
+	}
+}
+
+// benchCall makes one call: it sends every message, half-closes and reads
+// the responses until the call ends.
+func benchCall(client *GRPCClient, md protoreflect.MethodDescriptor, messages []proto.Message) error {
+	ctx, cancel := client.callContext()
+	defer cancel()
+
+	desc := &grpc.StreamDesc{
+		StreamName:    string(md.Name()),
+		ServerStreams: md.IsStreamingServer(),
+		ClientStreams: md.IsStreamingClient(),
+	}
+	stream, err := client.conn.NewStream(ctx, desc, MethodPath(md))
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		if err := stream.SendMsg(msg); err != nil {
+			// The server ended the call; its status is reported by RecvMsg.
+			break
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		if err := stream.RecvMsg(dynamicpb.NewMessage(md.Output())); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func newBenchReport(results []benchResult, total time.Duration) *GRPCBenchReport {
+	report := &GRPCBenchReport{
+		Count:       len(results),
+		TotalMs:     milliseconds(total),
+		StatusCodes: map[string]int{},
+		Histogram:   []GRPCBenchBucket{},
+	}
+	if total > 0 {
+		report.RPS = float64(len(results)) / total.Seconds()
+	}
+
+	latencies := make([]time.Duration, 0, len(results))
+	var sum time.Duration
+	for _, result := range results {
+		latencies = append(latencies, result.latency)
+		sum += result.latency
+		st := status.Convert(result.err)
+		report.StatusCodes[statusCodeName(st.Code())]++
+		if result.err != nil {
+			if report.Errors == nil {
+				report.Errors = map[string]int{}
+			}
+			report.Errors[st.Message()]++
+		}
+	}
+	if len(latencies) == 0 {
+		return report
+	}
+	slices.Sort(latencies)
+
+	report.Latency = GRPCBenchLatency{
+		FastestMs: milliseconds(latencies[0]),
+		SlowestMs: milliseconds(latencies[len(latencies)-1]),
+		AverageMs: milliseconds(sum / time.Duration(len(latencies))),
+		P50Ms:     milliseconds(percentile(latencies, 50)),
+		P90Ms:     milliseconds(percentile(latencies, 90)),
+		P99Ms:     milliseconds(percentile(latencies, 99)),
+	}
+
+	fastest, slowest := latencies[0], latencies[len(latencies)-1]
+	width := (slowest - fastest) / benchHistogramBuckets
+	next := 0
+	for i := range benchHistogramBuckets {
+		upTo := fastest + width*time.Duration(i+1)
+		if i == benchHistogramBuckets-1 || width == 0 {
+			upTo = slowest
+		}
+		bucket := GRPCBenchBucket{UpToMs: milliseconds(upTo)}
+		for next < len(latencies) && latencies[next] <= upTo {
+			bucket.Count++
+			next++
+		}
+		report.Histogram = append(report.Histogram, bucket)
+		if width == 0 {
+			break
+		}
+	}
+	return report
+}
+
+// percentile returns the latency below which p percent of the sorted
+// latencies fall.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p+99)/100 - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"io"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// HealthServing is the status reported by a healthy service.
+const HealthServing = "SERVING"
+
+// GRPCHealthCheck calls grpc.health.v1.Health/Check on the server at
+// address. An empty service asks about the server as a whole. The status,
+// e.g. SERVING or NOT_SERVING, is returned in Data.
+func GRPCHealthCheck(address, service string, args ...Args) (*GRPCResponse, error) {
+	client, errResp := connectGRPCClient(address, args)
+	if errResp != nil {
+		return errResp, nil
+	}
+	defer client.Close()
+
+	ctx, cancel := client.callContext()
+	defer cancel()
+
+	var header, trailer metadata.MD
+	resp, err := healthpb.NewHealthClient(client.conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service}, grpc.Header(&header), grpc.Trailer(&trailer))
+	if err != nil {
+		return &GRPCResponse{
+			Metadata: header,
+			Trailers: trailer,
+			Error:    grpcStatusError(err),
+		}, nil
+	}
+
+	return &GRPCResponse{
+		Data:     healthData(service, resp),
+		Metadata: header,
+		Trailers: trailer,
+	}, nil
+}
+
+// GRPCHealthWatch calls grpc.health.v1.Health/Watch and hands every status
+// update to onStatus until the server ends the stream, the call times out
+// or it fails. The last status is returned in Data.
+func GRPCHealthWatch(address, service string, onStatus func(string), args ...Args) (*GRPCResponse, error) {
+	client, errResp := connectGRPCClient(address, args)
+	if errResp != nil {
+		return errResp, nil
+	}
+	defer client.Close()
+
+	ctx, cancel := client.callContext()
+	defer cancel()
+
+	stream, err := healthpb.NewHealthClient(client.conn).Watch(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return &GRPCResponse{
+			Error: grpcStatusError(err),
+		}, nil
+	}
+
+	var last *healthpb.HealthCheckResponse
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			header, _ := stream.Header()
+			return &GRPCResponse{
+				Data:     healthData(service, last),
+				Metadata: header,
+				Trailers: stream.Trailer(),
+				Error:    grpcStatusError(err),
+			}, nil
+		}
+		last = resp
+		if onStatus != nil {
+			onStatus(resp.GetStatus().String())
+		}
+	}
+
+	header, _ := stream.Header()
+	return &GRPCResponse{
+		Data:     healthData(service, last),
+		Metadata: header,
+		Trailers: stream.Trailer(),
+	}, nil
+}
+
+func healthData(service string, resp *healthpb.HealthCheckResponse) map[string]any {
+	if resp == nil {
+		return nil
+	}
+	return map[string]any{
+		"service": service,
+		"status":  resp.GetStatus().String(),
+	}
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ResolveMethod finds the descriptor of service/method. The service may be
+// given without its package when the name is unambiguous.
+func ResolveMethod(source DescriptorSource, service, method string) (protoreflect.MethodDescriptor, error) {
+	service = strings.TrimPrefix(service, ".")
+	d, err := source.FindSymbol(service)
+	if err != nil && !strings.Contains(service, ".") {
+		services, lerr := source.ListServices()
+		if lerr != nil {
+			return nil, err
+		}
+		var matches []string
+		for _, name := range services {
+			if strings.HasSuffix(name, "."+service) {
+				matches = append(matches, name)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("service %q not found", service)
+		case 1:
+			d, err = source.FindSymbol(matches[0])
+		default:
+			return nil, fmt.Errorf("service %q is ambiguous: %s", service, strings.Join(matches, ", "))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("method %q not found in service %q", method, sd.FullName())
+	}
+	return md, nil
+}
+
+// MethodPath returns the HTTP/2 path a method is invoked on, e.g.
+// "/pkg.Service/Method".
+func MethodPath(md protoreflect.MethodDescriptor) string {
+	return fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+}
+
+// JSONToMessage decodes a JSON document into a dynamic message of type md.
+// Empty input produces an empty message.
+func JSONToMessage(md protoreflect.MessageDescriptor, data []byte) (*dynamicpb.Message, error) {
+	msg := dynamicpb.NewMessage(md)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return msg, nil
+	}
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", md.FullName(), err)
+	}
+	return msg, nil
+}
+
+// MessageToJSON converts a message into a generic JSON value suitable for
+// printing. 64-bit integers are kept exact.
+func MessageToJSON(msg proto.Message) (any, error) {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// fileSource is a DescriptorSource backed by local .proto files or
+// protoset bundles, for servers that have reflection turned off.
+type fileSource struct {
+	files *protoregistry.Files
+}
+
+// LoadDescriptorSource compiles the given .proto files, resolving imports
+// against importPaths, and loads the given protoset files (serialized
+// FileDescriptorSets, as written by protoc --descriptor_set_out). Everything
+// is parsed in-process; protoc is not needed.
+func LoadDescriptorSource(protoFiles, importPaths, protosets []string) (DescriptorSource, error) {
+	src := &fileSource{files: &protoregistry.Files{}}
+
+	if len(protosets) > 0 {
+		if err := src.loadProtosets(protosets); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(protoFiles) > 0 {
+		compiler := protocompile.Compiler{
+			Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+		}
+		compiled, err := compiler.Compile(context.Background(), protoFiles...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proto files: %w", err)
+		}
+		for _, fd := range compiled {
+			if err := src.register(fd); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return src, nil
+}
+
+func (f *fileSource) loadProtosets(paths []string) error {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fds := &descriptorpb.FileDescriptorSet{}
+		if err := proto.Unmarshal(data, fds); err != nil {
+			return fmt.Errorf("invalid protoset %s: %w", path, err)
+		}
+		for _, fdp := range fds.GetFile() {
+			if !seen[fdp.GetName()] {
+				seen[fdp.GetName()] = true
+				set.File = append(set.File, fdp)
+			}
+		}
+	}
+
+	// Protosets written without --include_imports leave out imported files;
+	// the well-known types can still be filled in from the binary.
+	for i := 0; i < len(set.File); i++ {
+		for _, dep := range set.File[i].GetDependency() {
+			if seen[dep] {
+				continue
+			}
+			if fd, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil {
+				seen[dep] = true
+				set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+			}
+		}
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return fmt.Errorf("invalid protoset: %w", err)
+	}
+	var rerr error
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		rerr = f.register(fd)
+		return rerr == nil
+	})
+	return rerr
+}
+
+// register adds fd and its imports to the registry, skipping files that are
+// already known.
+func (f *fileSource) register(fd protoreflect.FileDescriptor) error {
+	if _, err := f.files.FindFileByPath(fd.Path()); err == nil {
+		return nil
+	}
+	for i := range fd.Imports().Len() {
+		if err := f.register(fd.Imports().Get(i).FileDescriptor); err != nil {
+			return err
+		}
+	}
+	return f.files.RegisterFile(fd)
+}
+
+func (f *fileSource) ListServices() ([]string, error) {
+	services := make([]string, 0)
+	f.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := range fd.Services().Len() {
+			services = append(services, string(fd.Services().Get(i).FullName()))
+		}
+		return true
+	})
+	slices.Sort(services)
+	return services, nil
+}
+
+func (f *fileSource) FindSymbol(name string) (protoreflect.Descriptor, error) {
+	name = normalizeSymbol(name)
+	d, err := f.files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("symbol %q not found in the loaded proto files", name)
+	}
+	return d, nil
+}
@@ -0,0 +1,395 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	rpbalpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// DescriptorSource resolves the services and protobuf descriptors a gRPC
+// server exposes.
+type DescriptorSource interface {
+	ListServices() ([]string, error)
+	FindSymbol(name string) (protoreflect.Descriptor, error)
+}
+
+// reflectionStream sends one reflection request and waits for its answer.
+type reflectionStream interface {
+	roundTrip(req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error)
+	CloseSend() error
+}
+
+type v1Stream struct {
+	rpb.ServerReflection_ServerReflectionInfoClient
+}
+
+func (s v1Stream) roundTrip(req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+	if err := s.Send(req); err != nil {
+		return nil, err
+	}
+	return s.Recv()
+}
+
+// v1alphaStream speaks grpc.reflection.v1alpha. The v1 and v1alpha messages
+// are wire compatible, so requests and responses are converted by
+// re-encoding them.
+type v1alphaStream struct {
+	rpbalpha.ServerReflection_ServerReflectionInfoClient
+}
+
+func (s v1alphaStream) roundTrip(req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+	alphaReq := &rpbalpha.ServerReflectionRequest{}
+	if err := convertMessage(req, alphaReq); err != nil {
+		return nil, err
+	}
+	if err := s.Send(alphaReq); err != nil {
+		return nil, err
+	}
+	alphaResp, err := s.Recv()
+	if err != nil {
+		return nil, err
+	}
+	resp := &rpb.ServerReflectionResponse{}
+	return resp, convertMessage(alphaResp, resp)
+}
+
+func convertMessage(from, to proto.Message) error {
+	data, err := proto.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, to)
+}
+
+// reflectionSource is a DescriptorSource backed by the server reflection
+// service. It prefers grpc.reflection.v1 and falls back to v1alpha for
+// servers that only implement the older protocol. Requests share one
+// stream, so they are made one at a time.
+type reflectionSource struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	timeout time.Duration
+	conn    *grpc.ClientConn
+	stream  reflectionStream
+	cancel  context.CancelFunc
+	alpha   bool
+	files   *protoregistry.Files
+}
+
+// newReflectionSource creates a reflection source on conn. Each request,
+// opening the stream included, fails after timeout when it is positive.
+func newReflectionSource(ctx context.Context, conn *grpc.ClientConn, timeout time.Duration) *reflectionSource {
+	return &reflectionSource{
+		ctx:     ctx,
+		timeout: timeout,
+		conn:    conn,
+		files:   &protoregistry.Files{},
+	}
+}
+
+func (r *reflectionSource) roundTrip(req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+	for {
+		resp, err := r.timedRoundTrip(req)
+		if status.Code(err) == codes.Unimplemented && !r.alpha {
+			r.alpha = true
+			r.reset()
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e := resp.GetErrorResponse(); e != nil {
+			return nil, status.Error(codes.Code(e.ErrorCode), e.ErrorMessage)
+		}
+		return resp, nil
+	}
+}
+
+// timedRoundTrip sends req on the stream, opening it first if needed. The
+// stream is shared by every request, so rather than a context deadline a
+// timer cancels it when a request takes too long, and the next request opens
+// a new one.
+func (r *reflectionSource) timedRoundTrip(req *rpb.ServerReflectionRequest) (resp *rpb.ServerReflectionResponse, err error) {
+	var ctx context.Context
+	if r.stream == nil {
+		ctx, r.cancel = context.WithCancel(r.ctx)
+	}
+	if r.timeout > 0 {
+		timer := time.AfterFunc(r.timeout, r.cancel)
+		defer func() {
+			if !timer.Stop() {
+				r.reset()
+				if err != nil {
+					err = status.Errorf(codes.DeadlineExceeded, "reflection request timed out after %s", r.timeout)
+				}
+			}
+		}()
+	}
+
+	if r.stream == nil {
+		if r.alpha {
+			stream, err := rpbalpha.NewServerReflectionClient(r.conn).ServerReflectionInfo(ctx)
+			if err != nil {
+				return nil, err
+			}
+			r.stream = v1alphaStream{stream}
+		} else {
+			stream, err := rpb.NewServerReflectionClient(r.conn).ServerReflectionInfo(ctx)
+			if err != nil {
+				return nil, err
+			}
+			r.stream = v1Stream{stream}
+		}
+	}
+	return r.stream.roundTrip(req)
+}
+
+// reset closes the stream, so the next request opens a new one.
+func (r *reflectionSource) reset() {
+	if r.stream != nil {
+		r.stream.CloseSend()
+	}
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.stream, r.cancel = nil, nil
+}
+
+func (r *reflectionSource) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reset()
+}
+
+func (r *reflectionSource) ListServices() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resp, err := r.roundTrip(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{ListServices: "*"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	services := make([]string, 0)
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	slices.Sort(services)
+	return services, nil
+}
+
+func (r *reflectionSource) FindSymbol(name string) (protoreflect.Descriptor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name = normalizeSymbol(name)
+	if d, err := r.files.FindDescriptorByName(protoreflect.FullName(name)); err == nil {
+		return d, nil
+	}
+
+	resp, err := r.roundTrip(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: name},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve symbol %q: %w", name, err)
+	}
+	if err := r.addFiles(resp); err != nil {
+		return nil, err
+	}
+
+	d, err := r.files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("symbol %q not found: %w", name, err)
+	}
+	return d, nil
+}
+
+// addFiles registers the files in a reflection response, fetching any
+// dependencies the server did not send along.
+func (r *reflectionSource) addFiles(resp *rpb.ServerReflectionResponse) error {
+	pending := make(map[string]*descriptorpb.FileDescriptorProto)
+	var order []string
+	for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		fdp := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(raw, fdp); err != nil {
+			return fmt.Errorf("invalid file descriptor from server: %w", err)
+		}
+		pending[fdp.GetName()] = fdp
+		order = append(order, fdp.GetName())
+	}
+	for _, name := range order {
+		if err := r.addFile(name, pending); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *reflectionSource) addFile(name string, pending map[string]*descriptorpb.FileDescriptorProto) error {
+	if _, err := r.files.FindFileByPath(name); err == nil {
+		return nil
+	}
+
+	fdp, ok := pending[name]
+	if !ok {
+		resp, err := r.roundTrip(&rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+		})
+		if err == nil {
+			return r.addFiles(resp)
+		}
+		// Well-known types are compiled into the binary, so servers that
+		// leave them out can still be described.
+		if fd, gerr := protoregistry.GlobalFiles.FindFileByPath(name); gerr == nil {
+			return r.files.RegisterFile(fd)
+		}
+		return fmt.Errorf("failed to resolve file %q: %w", name, err)
+	}
+
+	for _, dep := range fdp.GetDependency() {
+		if err := r.addFile(dep, pending); err != nil {
+			return err
+		}
+	}
+	fd, err := protodesc.NewFile(fdp, r.files)
+	if err != nil {
+		return fmt.Errorf("invalid file descriptor %q: %w", name, err)
+	}
+	return r.files.RegisterFile(fd)
+}
+
+// normalizeSymbol accepts "pkg.Service/Method" as well as the fully
+// qualified "pkg.Service.Method", with or without a leading dot.
+func normalizeSymbol(name string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(name, "."), "/", ".")
+}
+
+// DescribeDescriptor returns a printable description of a service, method,
+// message or enum, including the fields of every message it uses.
+func DescribeDescriptor(d protoreflect.Descriptor) map[string]any {
+	messages := make(map[string]any)
+	description := map[string]any{}
+
+	switch d := d.(type) {
+	case protoreflect.ServiceDescriptor:
+		methods := make([]any, 0, d.Methods().Len())
+		for i := range d.Methods().Len() {
+			md := d.Methods().Get(i)
+			methods = append(methods, describeMethod(md))
+			collectMessages(md.Input(), messages)
+			collectMessages(md.Output(), messages)
+		}
+		description["service"] = string(d.FullName())
+		description["methods"] = methods
+	case protoreflect.MethodDescriptor:
+		description = describeMethod(d)
+		collectMessages(d.Input(), messages)
+		collectMessages(d.Output(), messages)
+	case protoreflect.MessageDescriptor:
+		description["message"] = string(d.FullName())
+		collectMessages(d, messages)
+	case protoreflect.EnumDescriptor:
+		description["enum"] = string(d.FullName())
+		description["values"] = describeEnum(d)
+	default:
+		description["symbol"] = string(d.FullName())
+	}
+
+	if len(messages) > 0 {
+		description["messages"] = messages
+	}
+	return description
+}
+
+func describeMethod(md protoreflect.MethodDescriptor) map[string]any {
+	return map[string]any{
+		"method":          string(md.FullName()),
+		"request":         string(md.Input().FullName()),
+		"response":        string(md.Output().FullName()),
+		"clientStreaming": md.IsStreamingClient(),
+		"serverStreaming": md.IsStreamingServer(),
+	}
+}
+
+func describeEnum(ed protoreflect.EnumDescriptor) []string {
+	values := make([]string, 0, ed.Values().Len())
+	for i := range ed.Values().Len() {
+		v := ed.Values().Get(i)
+		values = append(values, fmt.Sprintf("%s = %d", v.Name(), v.Number()))
+	}
+	return values
+}
+
+// collectMessages describes md and, recursively, every message its fields
+// refer to.
+func collectMessages(md protoreflect.MessageDescriptor, messages map[string]any) {
+	name := string(md.FullName())
+	if _, seen := messages[name]; seen || md.IsMapEntry() {
+		return
+	}
+
+	fields := make([]any, 0, md.Fields().Len())
+	messages[name] = map[string]any{"fields": fields}
+	var nested []protoreflect.MessageDescriptor
+	for i := range md.Fields().Len() {
+		fd := md.Fields().Get(i)
+		field := map[string]any{
+			"name":   string(fd.Name()),
+			"number": int(fd.Number()),
+			"type":   fieldTypeName(fd),
+		}
+		switch {
+		case fd.IsMap():
+			field["label"] = "map"
+		case fd.IsList():
+			field["label"] = "repeated"
+		case fd.HasOptionalKeyword():
+			field["label"] = "optional"
+		}
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			field["oneof"] = string(oneof.Name())
+		}
+		if fd.Enum() != nil {
+			field["values"] = describeEnum(fd.Enum())
+		}
+		fields = append(fields, field)
+
+		if fd.IsMap() {
+			fd = fd.MapValue()
+		}
+		if fd.Message() != nil {
+			nested = append(nested, fd.Message())
+		}
+	}
+	messages[name] = map[string]any{"fields": fields}
+
+	for _, m := range nested {
+		collectMessages(m, messages)
+	}
+}
+
+func fieldTypeName(fd protoreflect.FieldDescriptor) string {
+	if fd.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", fieldTypeName(fd.MapKey()), fieldTypeName(fd.MapValue()))
+	}
+	switch {
+	case fd.Message() != nil:
+		return string(fd.Message().FullName())
+	case fd.Enum() != nil:
+		return string(fd.Enum().FullName())
+	}
+	return fd.Kind().String()
+}
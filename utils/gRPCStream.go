@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCRequestSource yields request messages as JSON, one per call, and
+// returns io.EOF after the last one.
+type GRPCRequestSource func() (json.RawMessage, error)
+
+// JSONRequestSource reads a sequence of JSON documents from r, e.g. one
+// message per line. A top-level JSON array is treated as a list of messages.
+// Documents are decoded as they arrive, so r can be an interactive stdin.
+func JSONRequestSource(r io.Reader) GRPCRequestSource {
+	if r == nil {
+		return func() (json.RawMessage, error) { return nil, io.EOF }
+	}
+	decoder := json.NewDecoder(r)
+	var pending []json.RawMessage
+	return func() (json.RawMessage, error) {
+		for len(pending) == 0 {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				if err != io.EOF {
+					err = fmt.Errorf("invalid request JSON: %w", err)
+				}
+				return nil, err
+			}
+			if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 || trimmed[0] != '[' {
+				return raw, nil
+			}
+			if err := json.Unmarshal(raw, &pending); err != nil {
+				return nil, fmt.Errorf("invalid request JSON: %w", err)
+			}
+		}
+		msg := pending[0]
+		pending = pending[1:]
+		return msg, nil
+	}
+}
+
+// GRPCStreamCall invokes any kind of gRPC method: unary, server-streaming,
+// client-streaming or bidirectional. Requests are read from requests, and
+// every response message is handed to onMessage as soon as it arrives.
+// Unary and server-streaming methods send a single request (an empty one if
+// requests yields nothing). For methods with a single response, the
+// response is also returned in GRPCResponse.Data. WithGRPCProtocol makes
+// the call over gRPC-Web or Connect instead.
+func GRPCStreamCall(address, service, method string, requests GRPCRequestSource, onMessage func(any), args ...Args) (*GRPCResponse, error) {
+	if arg := collectArgs(args); arg.Protocol != "" && arg.Protocol != ProtocolGRPC {
+		return grpcHTTPStreamCall(context.Background(), address, service, method, requests, onMessage, arg)
+	}
+
+	client, errResp := connectGRPCClient(address, args)
+	if errResp != nil {
+		return errResp, nil
+	}
+	defer client.Close()
+
+	return client.StreamCall(context.Background(), service, method, requests, onMessage)
+}
+
+// StreamCall is GRPCStreamCall on the client's connection, so a series of
+// calls needs only one connection, and one reflection of the server.
+// Cancelling ctx ends the call early.
+func (c *GRPCClient) StreamCall(ctx context.Context, service, method string, requests GRPCRequestSource, onMessage func(any)) (*GRPCResponse, error) {
+	if c.arg.Protocol != "" && c.arg.Protocol != ProtocolGRPC {
+		return grpcHTTPStreamCall(ctx, c.address, service, method, requests, onMessage, c.arg)
+	}
+
+	md, err := ResolveMethod(c.Descriptors(), service, method)
+	if err != nil {
+		return &GRPCResponse{
+			Error: lookupError(err),
+		}, nil
+	}
+
+	// The call context carries the headers of the client, so ctx only
+	// cancels it.
+	callCtx, cancel := c.callContext()
+	defer cancel()
+	defer context.AfterFunc(ctx, cancel)()
+	ctx = callCtx
+
+	desc := &grpc.StreamDesc{
+		StreamName:    string(md.Name()),
+		ServerStreams: md.IsStreamingServer(),
+		ClientStreams: md.IsStreamingClient(),
+	}
+	stream, err := c.conn.NewStream(ctx, desc, MethodPath(md))
+	if err != nil {
+		return &GRPCResponse{
+			Error: grpcStatusError(err),
+		}, nil
+	}
+
+	// Requests are sent while responses are received, so bidirectional
+	// calls can interleave. A bad request aborts the call.
+	var sendMu sync.Mutex
+	var sendErr error
+	go func() {
+		if err := sendRequests(stream, md, requests); err != nil {
+			sendMu.Lock()
+			sendErr = err
+			sendMu.Unlock()
+			cancel()
+		}
+	}()
+
+	var last any
+	for {
+		resp := dynamicpb.NewMessage(md.Output())
+		err := stream.RecvMsg(resp)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			sendMu.Lock()
+			defer sendMu.Unlock()
+			if sendErr != nil {
+				return &GRPCResponse{
+					Error: newGRPCError(codes.InvalidArgument, "failed to decode request body: %v", sendErr),
+				}, nil
+			}
+			header, _ := stream.Header()
+			return &GRPCResponse{
+				Metadata: header,
+				Trailers: stream.Trailer(),
+				Error:    grpcStatusError(err),
+			}, nil
+		}
+
+		data, err := MessageToJSON(resp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode response: %w", err)
+		}
+		last = data
+		if onMessage != nil {
+			onMessage(data)
+		}
+	}
+
+	header, _ := stream.Header()
+	resp := &GRPCResponse{
+		Metadata: header,
+		Trailers: stream.Trailer(),
+	}
+	if !md.IsStreamingServer() {
+		resp.Data = last
+	}
+	return resp, nil
+}
+
+// sendRequests writes the request messages to the stream and half-closes
+// it. Only the first request is used for methods without client streaming.
+func sendRequests(stream grpc.ClientStream, md protoreflect.MethodDescriptor, requests GRPCRequestSource) error {
+	for sent := 0; ; sent++ {
+		raw, err := requests()
+		if errors.Is(err, io.EOF) {
+			if sent == 0 && !md.IsStreamingClient() {
+				raw = nil
+			} else {
+				break
+			}
+		} else if err != nil {
+			return err
+		}
+
+		msg, err := JSONToMessage(md.Input(), raw)
+		if err != nil {
+			return err
+		}
+		if err := stream.SendMsg(msg); err != nil {
+			// The server ended the call; its status is reported by RecvMsg.
+			break
+		}
+		if !md.IsStreamingClient() {
+			break
+		}
+	}
+	return stream.CloseSend()
+}
@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// OneofMarker prefixes the key placed before the fields of a oneof in a
+// template. Only one of those fields may be set, and the marker itself must
+// be removed before the template is sent.
+const OneofMarker = "// oneof "
+
+// TemplateObject is a JSON object that keeps its keys in the order the
+// fields are declared, unlike a map.
+type TemplateObject []TemplateField
+
+// TemplateField is one key of a TemplateObject.
+type TemplateField struct {
+	Key   string
+	Value any
+}
+
+func (o TemplateObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// MessageTemplate returns a sample JSON document for md with every field
+// set to a placeholder: zero values for scalars, the first value of enums,
+// one element for repeated fields and maps, and nested messages filled in
+// the same way. Messages that contain themselves are left empty the second
+// time round.
+func MessageTemplate(md protoreflect.MessageDescriptor) TemplateObject {
+	return (&templater{visiting: map[protoreflect.FullName]bool{}}).message(md)
+}
+
+// RequestTemplate is like MessageTemplate, but returns a request that can be
+// sent as it is: instead of listing every field of a oneof after a marker,
+// only the first one is set.
+func RequestTemplate(md protoreflect.MessageDescriptor) TemplateObject {
+	return (&templater{visiting: map[protoreflect.FullName]bool{}, sendable: true}).message(md)
+}
+
+// templater fills in templates. visiting holds the messages being filled
+// in, so messages that contain themselves end.
+type templater struct {
+	visiting map[protoreflect.FullName]bool
+	sendable bool
+}
+
+func (t *templater) message(md protoreflect.MessageDescriptor) TemplateObject {
+	template := TemplateObject{}
+	if t.visiting[md.FullName()] {
+		return template
+	}
+	t.visiting[md.FullName()] = true
+	defer delete(t.visiting, md.FullName())
+
+	seen := map[protoreflect.FullName]bool{}
+	for i := range md.Fields().Len() {
+		fd := md.Fields().Get(i)
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			first := !seen[oneof.FullName()]
+			seen[oneof.FullName()] = true
+			switch {
+			case t.sendable && !first:
+				continue
+			case !t.sendable && first:
+				template = append(template, TemplateField{
+					Key:   OneofMarker + string(oneof.Name()),
+					Value: "set only one of: " + oneofFieldNames(oneof),
+				})
+			}
+		}
+		template = append(template, TemplateField{
+			Key:   fd.JSONName(),
+			Value: t.field(fd),
+		})
+	}
+	return template
+}
+
+func oneofFieldNames(oneof protoreflect.OneofDescriptor) string {
+	names := make([]string, 0, oneof.Fields().Len())
+	for i := range oneof.Fields().Len() {
+		names = append(names, oneof.Fields().Get(i).JSONName())
+	}
+	return strings.Join(names, ", ")
+}
+
+func (t *templater) field(fd protoreflect.FieldDescriptor) any {
+	switch {
+	case fd.IsMap():
+		return TemplateObject{{
+			Key:   mapKeyTemplate(fd.MapKey()),
+			Value: t.singular(fd.MapValue()),
+		}}
+	case fd.IsList():
+		return []any{t.singular(fd)}
+	}
+	return t.singular(fd)
+}
+
+// singular is the placeholder for a single value of fd, in the JSON form
+// protojson expects.
+func (t *templater) singular(fd protoreflect.FieldDescriptor) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if value, ok := wellKnownTemplate(fd.Message()); ok {
+			return value
+		}
+		return t.message(fd.Message())
+	case protoreflect.EnumKind:
+		if values := fd.Enum().Values(); values.Len() > 0 {
+			return string(values.Get(0).Name())
+		}
+		return nil
+	case protoreflect.BoolKind:
+		return false
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return ""
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// 64-bit integers are strings in JSON, so they survive as exact.
+		return "0"
+	}
+	return 0
+}
+
+func mapKeyTemplate(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return "key"
+	case protoreflect.BoolKind:
+		return "false"
+	}
+	return "0"
+}
+
+// wellKnownTemplate returns the placeholder for the well-known types that
+// have a special JSON form.
+func wellKnownTemplate(md protoreflect.MessageDescriptor) (any, bool) {
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		return "1970-01-01T00:00:00Z", true
+	case "google.protobuf.Duration":
+		return "0s", true
+	case "google.protobuf.FieldMask":
+		return "", true
+	case "google.protobuf.Struct":
+		return TemplateObject{}, true
+	case "google.protobuf.ListValue":
+		return []any{}, true
+	case "google.protobuf.Value":
+		return nil, true
+	case "google.protobuf.Any":
+		return TemplateObject{{Key: "@type", Value: ""}}, true
+	case "google.protobuf.Empty":
+		return TemplateObject{}, true
+	case "google.protobuf.BoolValue",
+		"google.protobuf.StringValue", "google.protobuf.BytesValue",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return (&templater{}).singular(md.Fields().ByName("value")), true
+	}
+	return nil, false
+}
+
+// splitMethodSymbol splits "pkg.Service/Method" or "pkg.Service.Method"
+// into its service and method.
+func splitMethodSymbol(symbol string) (service, method string, err error) {
+	symbol = strings.TrimPrefix(symbol, ".")
+	if service, method, ok := strings.Cut(symbol, "/"); ok {
+		return service, method, nil
+	}
+	if i := strings.LastIndex(symbol, "."); i > 0 {
+		return symbol[:i], symbol[i+1:], nil
+	}
+	return "", "", fmt.Errorf("%q is not a method, expected Service/Method", symbol)
+}
@@ -0,0 +1,549 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Protocols a gRPC call can be made with besides native gRPC
+// (ProtocolGRPC). Both run over plain HTTP/1.1, so they reach services
+// behind proxies such as Envoy.
+const (
+	ProtocolGRPCWeb = "grpc-web"
+	ProtocolConnect = "connect"
+)
+
+// Frame flags of the length-prefixed messages used by both protocols.
+const (
+	frameCompressed = 0x01
+	frameEndStream  = 0x02 // Connect: the final message carries the status
+	frameTrailer    = 0x80 // gRPC-Web: the final message carries trailers
+)
+
+// WithGRPCProtocol selects the protocol calls are made with: grpc (the
+// default), grpc-web or connect.
+func WithGRPCProtocol(protocol string) Args {
+	return func(arg *Arg) {
+		arg.Protocol = protocol
+	}
+}
+
+// ValidGRPCProtocol reports whether protocol is one WithGRPCProtocol accepts.
+func ValidGRPCProtocol(protocol string) bool {
+	switch protocol {
+	case "", ProtocolGRPC, ProtocolGRPCWeb, ProtocolConnect:
+		return true
+	}
+	return false
+}
+
+// grpcHTTPStreamCall is GRPCStreamCall for the gRPC-Web and Connect
+// protocols. Reflection needs native gRPC, so the method is resolved from
+// the local descriptors. Over HTTP/1.1 the requests are sent before the
+// responses are read, which rules out bidirectional streaming.
+func grpcHTTPStreamCall(ctx context.Context, address, service, method string, requests GRPCRequestSource, onMessage func(any), arg Arg) (*GRPCResponse, error) {
+	if !ValidGRPCProtocol(arg.Protocol) {
+		return nil, fmt.Errorf("unknown protocol %q, expected grpc, grpc-web or connect", arg.Protocol)
+	}
+	if arg.Descriptors == nil {
+		return &GRPCResponse{
+			Error: newGRPCError(codes.FailedPrecondition, "%s calls need local descriptors: server reflection is only available over native gRPC", arg.Protocol),
+		}, nil
+	}
+
+	md, err := ResolveMethod(arg.Descriptors, service, method)
+	if err != nil {
+		return &GRPCResponse{
+			Error: lookupError(err),
+		}, nil
+	}
+	if md.IsStreamingClient() && md.IsStreamingServer() {
+		return &GRPCResponse{
+			Error: newGRPCError(codes.Unimplemented, "bidirectional streaming is not supported over %s", arg.Protocol),
+		}, nil
+	}
+	if md.IsStreamingClient() && arg.Protocol == ProtocolGRPCWeb {
+		return &GRPCResponse{
+			Error: newGRPCError(codes.Unimplemented, "client streaming is not supported over grpc-web"),
+		}, nil
+	}
+
+	messages, err := encodeRequests(md, requests)
+	if err != nil {
+		return &GRPCResponse{
+			Error: newGRPCError(codes.InvalidArgument, "failed to decode request body: %v", err),
+		}, nil
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if arg.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, arg.Timeout)
+	}
+	defer cancel()
+
+	target := grpcHTTPURL(address, arg.TLS != nil) + MethodPath(md)
+	SetParams(&target, arg.Params)
+
+	unary := arg.Protocol == ProtocolConnect && !md.IsStreamingClient() && !md.IsStreamingServer()
+	var body bytes.Buffer
+	for _, msg := range messages {
+		if unary {
+			body.Write(msg)
+		} else {
+			writeFrame(&body, 0, msg)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	SetHeaders(req, arg.Headers)
+	switch {
+	case arg.Protocol == ProtocolGRPCWeb:
+		req.Header.Set("Content-Type", "application/grpc-web+proto")
+		req.Header.Set("X-Grpc-Web", "1")
+		if arg.Timeout > 0 {
+			req.Header.Set("Grpc-Timeout", grpcTimeoutHeader(arg.Timeout))
+		}
+	case unary:
+		req.Header.Set("Content-Type", "application/proto")
+	default:
+		req.Header.Set("Content-Type", "application/connect+proto")
+	}
+	if arg.Protocol == ProtocolConnect {
+		req.Header.Set("Connect-Protocol-Version", "1")
+		if arg.Timeout > 0 {
+			req.Header.Set("Connect-Timeout-Ms", strconv.FormatInt(arg.Timeout.Milliseconds(), 10))
+		}
+	}
+
+	connectTimeout := arg.ConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = defaultConnectTimeout
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			DialContext:     (&net.Dialer{Timeout: connectTimeout}).DialContext,
+			TLSClientConfig: arg.TLS,
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &GRPCResponse{
+			Error: httpTransportError(ctx, fmt.Errorf("failed to connect to gRPC server: %w", err)),
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	call := &httpCall{ctx: ctx, md: md, onMessage: onMessage}
+	switch {
+	case arg.Protocol == ProtocolGRPCWeb:
+		return call.grpcWebResponse(resp)
+	case unary:
+		return call.connectUnaryResponse(resp)
+	default:
+		return call.connectStreamResponse(resp)
+	}
+}
+
+// encodeRequests reads the request messages and encodes them in the binary
+// protobuf format. Only the first request is used for methods without
+// client streaming, and an empty one is sent if there is none.
+func encodeRequests(md protoreflect.MethodDescriptor, requests GRPCRequestSource) ([][]byte, error) {
+	var messages [][]byte
+	for {
+		raw, err := requests()
+		if errors.Is(err, io.EOF) {
+			if len(messages) > 0 || md.IsStreamingClient() {
+				return messages, nil
+			}
+			raw = nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		msg, err := JSONToMessage(md.Input(), raw)
+		if err != nil {
+			return nil, err
+		}
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, data)
+		if !md.IsStreamingClient() {
+			return messages, nil
+		}
+	}
+}
+
+// grpcHTTPURL turns address into the base URL calls are posted to. A full
+// URL is used as is, so services mounted under a path prefix work too.
+func grpcHTTPURL(address string, secure bool) string {
+	if strings.HasPrefix(address, "http://") || strings.HasPrefix(address, "https://") {
+		return strings.TrimSuffix(address, "/")
+	}
+	if secure {
+		return "https://" + address
+	}
+	return "http://" + address
+}
+
+// grpcTimeoutHeader formats d as a grpc-timeout value, which allows at most
+// eight digits.
+func grpcTimeoutHeader(d time.Duration) string {
+	if ms := d.Milliseconds(); ms < 1e8 {
+		return fmt.Sprintf("%dm", max(ms, 1))
+	}
+	return fmt.Sprintf("%dS", min(int64(d.Seconds()), 1e8-1))
+}
+
+// writeFrame writes a length-prefixed message: a flags byte, the payload
+// length as a big-endian uint32, then the payload.
+func writeFrame(w *bytes.Buffer, flags byte, payload []byte) {
+	var prefix [5]byte
+	prefix[0] = flags
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(payload)))
+	w.Write(prefix[:])
+	w.Write(payload)
+}
+
+// readFrame reads a length-prefixed message. It returns io.EOF when the
+// body ends cleanly between messages.
+func readFrame(r io.Reader) (flags byte, payload []byte, err error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = errors.New("truncated message frame")
+		}
+		return 0, nil, err
+	}
+	payload = make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, fmt.Errorf("truncated message frame: %w", err)
+	}
+	return prefix[0], payload, nil
+}
+
+// httpCall decodes the response of a gRPC-Web or Connect call.
+type httpCall struct {
+	ctx       context.Context
+	md        protoreflect.MethodDescriptor
+	onMessage func(any)
+	last      any
+}
+
+// message decodes a response message and hands it to onMessage.
+func (c *httpCall) message(payload []byte) error {
+	msg := dynamicpb.NewMessage(c.md.Output())
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return fmt.Errorf("invalid %s: %w", c.md.Output().FullName(), err)
+	}
+	data, err := MessageToJSON(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	c.last = data
+	if c.onMessage != nil {
+		c.onMessage(data)
+	}
+	return nil
+}
+
+// result builds the response of a call that ended with grpcErr, or
+// successfully when it is nil.
+func (c *httpCall) result(header, trailer metadata.MD, grpcErr *GRPCError) *GRPCResponse {
+	resp := &GRPCResponse{
+		Metadata: header,
+		Trailers: trailer,
+		Error:    grpcErr,
+	}
+	if grpcErr == nil && !c.md.IsStreamingServer() {
+		resp.Data = c.last
+	}
+	return resp
+}
+
+// readError turns a failure while reading the body into a GRPCError.
+func (c *httpCall) readError(err error) *GRPCError {
+	if c.ctx.Err() != nil {
+		return httpTransportError(c.ctx, err)
+	}
+	return newGRPCError(codes.Internal, "%v", err)
+}
+
+// grpcWebResponse reads a gRPC-Web response: data frames followed by a
+// trailer frame holding the status. Errors before any message may instead
+// come as a trailers-only response, with the status in the headers.
+func (c *httpCall) grpcWebResponse(resp *http.Response) (*GRPCResponse, error) {
+	header := httpMetadata(resp.Header, "")
+	if _, ok := header["grpc-status"]; ok {
+		grpcErr := grpcWebStatus(header)
+		return c.result(header, nil, grpcErr), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return c.result(header, nil, httpStatusError(resp.StatusCode)), nil
+	}
+
+	var trailer metadata.MD
+	for trailer == nil {
+		flags, payload, err := readFrame(resp.Body)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return c.result(header, nil, c.readError(err)), nil
+		}
+		switch {
+		case flags&frameCompressed != 0:
+			return c.result(header, nil, newGRPCError(codes.Internal, "compressed messages are not supported")), nil
+		case flags&frameTrailer != 0:
+			trailer = parseGRPCWebTrailer(payload)
+		default:
+			if err := c.message(payload); err != nil {
+				return c.result(header, nil, newGRPCError(codes.Internal, "%v", err)), nil
+			}
+		}
+	}
+	if trailer == nil {
+		// Some servers send real HTTP trailers instead of a trailer frame.
+		trailer = httpMetadata(resp.Trailer, "")
+	}
+	if _, ok := trailer["grpc-status"]; !ok {
+		return c.result(header, trailer, newGRPCError(codes.Internal, "server closed the stream without sending trailers")), nil
+	}
+	return c.result(header, trailer, grpcWebStatus(trailer)), nil
+}
+
+// parseGRPCWebTrailer parses the HTTP/1 style header block of a trailer
+// frame.
+func parseGRPCWebTrailer(payload []byte) metadata.MD {
+	md := metadata.MD{}
+	for _, line := range strings.Split(string(payload), "\r\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		md[key] = append(md[key], strings.TrimSpace(value))
+	}
+	return md
+}
+
+// grpcWebStatus reads the status from the grpc-status, grpc-message and
+// grpc-status-details-bin entries of md, and removes them. It returns nil
+// for OK.
+func grpcWebStatus(md metadata.MD) *GRPCError {
+	code, _ := strconv.Atoi(first(md["grpc-status"]))
+	message, err := url.PathUnescape(first(md["grpc-message"]))
+	if err != nil {
+		message = first(md["grpc-message"])
+	}
+	st := &spb.Status{Code: int32(code), Message: message}
+	if details := first(md["grpc-status-details-bin"]); details != "" {
+		if data, err := decodeBase64(details); err == nil {
+			decoded := &spb.Status{}
+			if proto.Unmarshal(data, decoded) == nil {
+				st.Details = decoded.Details
+			}
+		}
+	}
+	delete(md, "grpc-status")
+	delete(md, "grpc-message")
+	delete(md, "grpc-status-details-bin")
+
+	if codes.Code(code) == codes.OK {
+		return nil
+	}
+	return grpcStatusError(status.FromProto(st).Err())
+}
+
+// connectUnaryResponse reads a Connect unary response: the message itself
+// on success, or a JSON error. Trailers are sent as headers prefixed with
+// "trailer-".
+func (c *httpCall) connectUnaryResponse(resp *http.Response) (*GRPCResponse, error) {
+	header := httpMetadata(resp.Header, "")
+	trailer := httpMetadata(resp.Header, "trailer-")
+	for key := range header {
+		if strings.HasPrefix(key, "trailer-") {
+			delete(header, key)
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return c.result(header, trailer, c.readError(err)), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		var wireErr connectWireError
+		if json.Unmarshal(body, &wireErr) != nil || wireErr.Code == "" {
+			return c.result(header, trailer, httpStatusError(resp.StatusCode)), nil
+		}
+		return c.result(header, trailer, wireErr.grpcError()), nil
+	}
+	if err := c.message(body); err != nil {
+		return c.result(header, trailer, newGRPCError(codes.Internal, "%v", err)), nil
+	}
+	return c.result(header, trailer, nil), nil
+}
+
+// connectStreamResponse reads a Connect streaming response: message frames
+// followed by an end-of-stream frame holding the error, if any, and the
+// trailers as JSON.
+func (c *httpCall) connectStreamResponse(resp *http.Response) (*GRPCResponse, error) {
+	header := httpMetadata(resp.Header, "")
+	if resp.StatusCode != http.StatusOK {
+		return c.result(header, nil, httpStatusError(resp.StatusCode)), nil
+	}
+
+	for {
+		flags, payload, err := readFrame(resp.Body)
+		if err == io.EOF {
+			return c.result(header, nil, newGRPCError(codes.Internal, "server closed the stream without an end-of-stream message")), nil
+		}
+		if err != nil {
+			return c.result(header, nil, c.readError(err)), nil
+		}
+		switch {
+		case flags&frameCompressed != 0:
+			return c.result(header, nil, newGRPCError(codes.Internal, "compressed messages are not supported")), nil
+		case flags&frameEndStream != 0:
+			var end struct {
+				Error    *connectWireError   `json:"error"`
+				Metadata map[string][]string `json:"metadata"`
+			}
+			if err := json.Unmarshal(payload, &end); err != nil {
+				return c.result(header, nil, newGRPCError(codes.Internal, "invalid end-of-stream message: %v", err)), nil
+			}
+			trailer := httpMetadata(end.Metadata, "")
+			if end.Error != nil {
+				return c.result(header, trailer, end.Error.grpcError()), nil
+			}
+			return c.result(header, trailer, nil), nil
+		default:
+			if err := c.message(payload); err != nil {
+				return c.result(header, nil, newGRPCError(codes.Internal, "%v", err)), nil
+			}
+		}
+	}
+}
+
+// connectWireError is the JSON error of the Connect protocol. Detail values
+// are base64-encoded protobuf messages named by their fully-qualified type.
+type connectWireError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details []struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"details"`
+}
+
+func (e *connectWireError) grpcError() *GRPCError {
+	st := &spb.Status{Code: int32(connectCode(e.Code)), Message: e.Message}
+	for _, detail := range e.Details {
+		value, err := decodeBase64(detail.Value)
+		if err != nil {
+			continue
+		}
+		st.Details = append(st.Details, &anypb.Any{
+			TypeUrl: "type.googleapis.com/" + detail.Type,
+			Value:   value,
+		})
+	}
+	return grpcStatusError(status.FromProto(st).Err())
+}
+
+// connectCode maps a Connect error code, e.g. invalid_argument, to its
+// status code. Connect spells CANCELLED as canceled.
+func connectCode(name string) codes.Code {
+	if name == "canceled" {
+		return codes.Canceled
+	}
+	for code, codeName := range statusCodeNames {
+		if strings.EqualFold(codeName, name) {
+			return codes.Code(code)
+		}
+	}
+	return codes.Unknown
+}
+
+// httpStatusError maps the HTTP status of a response without a gRPC status
+// to a status code, as the gRPC and Connect specifications do.
+func httpStatusError(statusCode int) *GRPCError {
+	code := codes.Unknown
+	switch statusCode {
+	case http.StatusBadRequest:
+		code = codes.Internal
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		code = codes.Unavailable
+	}
+	return newGRPCError(code, "unexpected HTTP status %d %s", statusCode, http.StatusText(statusCode))
+}
+
+// httpTransportError reports a failed request as DEADLINE_EXCEEDED when the
+// call ran out of time, and UNAVAILABLE otherwise.
+func httpTransportError(ctx context.Context, err error) *GRPCError {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return newGRPCError(codes.DeadlineExceeded, "context deadline exceeded")
+	}
+	return newGRPCError(codes.Unavailable, "%v", err)
+}
+
+// httpMetadata converts HTTP headers into metadata with lower case keys,
+// keeping only those starting with prefix, which is stripped.
+func httpMetadata(h map[string][]string, prefix string) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range h {
+		key = strings.ToLower(key)
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		key = strings.TrimPrefix(key, prefix)
+		md[key] = append(md[key], values...)
+	}
+	return md
+}
+
+// decodeBase64 decodes standard base64 with or without padding.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
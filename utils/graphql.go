@@ -2,15 +2,21 @@ package utils
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"strings"
 )
 
 type GraphQLRequest struct {
-	Query     string         `json:"query"`
-	Variables map[string]any `json:"variables,omitempty"`
+	Query         string         `json:"query,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
 }
 
 type GraphQLResponse struct {
@@ -19,9 +25,10 @@ type GraphQLResponse struct {
 }
 
 type GraphQLError struct {
-	Message   string                 `json:"message"`
-	Locations []GraphQLErrorLocation `json:"locations,omitempty"`
-	Path      []any                  `json:"path,omitempty"`
+	Message    string                 `json:"message"`
+	Locations  []GraphQLErrorLocation `json:"locations,omitempty"`
+	Path       []any                  `json:"path,omitempty"`
+	Extensions map[string]any         `json:"extensions,omitempty"`
 }
 
 type GraphQLErrorLocation struct {
@@ -30,8 +37,21 @@ type GraphQLErrorLocation struct {
 }
 
 func GraphQLQuery(url string, query string, args ...Args) (*http.Response, error) {
+	accept := ""
+	if strings.Contains(query, "@defer") || strings.Contains(query, "@stream") {
+		accept = IncrementalAccept
+	}
+	return graphQLPost(url, query, accept, args)
+}
+
+// graphQLPost sends query to url, asking for the accept media types if set.
+// Queries are sent with GET if asked to, and everything else with POST.
+// With a persisted query only its hash is sent first, and the full query
+// when the server does not know it yet. Operations with uploads are sent as
+// multipart/form-data.
+func graphQLPost(url, query, accept string, args []Args) (*http.Response, error) {
 	variables := make(map[string]any)
-	_, params, headers := GetArgs(args)
+	arg := collectArgs(args)
 
 	// Parse variables from args if provided
 	for _, arg := range args {
@@ -50,46 +70,161 @@ func GraphQLQuery(url string, query string, args ...Args) (*http.Response, error
 	}
 
 	reqBody := GraphQLRequest{
-		Query:     query,
-		Variables: variables,
+		Query:         query,
+		OperationName: arg.OperationName,
+		Variables:     variables,
+	}
+	if len(arg.Uploads) > 0 {
+		// Uploads are never persisted: the files go with every request.
+		return sendGraphQLUpload(url, reqBody, accept, arg)
+	}
+	// Only queries may be sent with GET: they change nothing, so caches
+	// in between can answer them.
+	method := http.MethodPost
+	if op, err := ParseGraphQLOperation(query, arg.OperationName); err == nil && arg.GET && op.Type == "query" {
+		method = http.MethodGet
+	}
+	if !arg.PersistedQuery {
+		return sendGraphQL(url, method, reqBody, accept, arg)
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
+	hash := sha256.Sum256([]byte(query))
+	reqBody.Extensions = map[string]any{
+		"persistedQuery": map[string]any{"version": 1, "sha256Hash": hex.EncodeToString(hash[:])},
+	}
+	hashed := reqBody
+	hashed.Query = ""
+	resp, err := sendGraphQL(url, method, hashed, accept, arg)
 	if err != nil {
 		return nil, err
 	}
+	switch persistedQueryStatus(resp) {
+	case persistedQueryNotFound:
+		// Sending the query along with its hash registers it.
+		return sendGraphQL(url, http.MethodPost, reqBody, accept, arg)
+	case persistedQueryNotSupported:
+		reqBody.Extensions = nil
+		return sendGraphQL(url, http.MethodPost, reqBody, accept, arg)
+	}
+	return resp, nil
+}
+
+// sendGraphQL sends a request as a JSON body, or as URL parameters with GET.
+func sendGraphQL(url, method string, reqBody GraphQLRequest, accept string, arg Arg) (*http.Response, error) {
+	SetParams(&url, arg.Params)
+	var body io.Reader
+	if method == http.MethodGet {
+		values := neturl.Values{}
+		if reqBody.Query != "" {
+			values.Set("query", reqBody.Query)
+		}
+		if reqBody.OperationName != "" {
+			values.Set("operationName", reqBody.OperationName)
+		}
+		if len(reqBody.Variables) > 0 {
+			vars, _ := json.Marshal(reqBody.Variables)
+			values.Set("variables", string(vars))
+		}
+		if len(reqBody.Extensions) > 0 {
+			extensions, _ := json.Marshal(reqBody.Extensions)
+			values.Set("extensions", string(extensions))
+		}
+		if strings.Contains(url, "?") {
+			url += "&" + values.Encode()
+		} else {
+			url += "?" + values.Encode()
+		}
+	} else {
+		jsonBody, err := json.Marshal(reqBody)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewBuffer(jsonBody)
+	}
 
-	SetParams(&url, params)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	SetHeaders(req, headers)
+	if method == http.MethodGet {
+		// Apollo Server rejects GET requests without a header a browser
+		// would have to preflight, to prevent CSRF.
+		req.Header.Set("Apollo-Require-Preflight", "true")
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	SetHeaders(req, arg.Headers)
 
 	client := http.Client{}
 	return client.Do(req)
 }
 
-func GraphQLMutation(url string, mutation string, args ...Args) (*http.Response, error) {
-	return GraphQLQuery(url, mutation, args...)
+// FormatGraphQLPath writes the path of an error the way it would be
+// written in JavaScript, such as user.friends[0].name.
+func FormatGraphQLPath(path []any) string {
+	var b strings.Builder
+	for _, segment := range path {
+		switch s := segment.(type) {
+		case string:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(s)
+		case float64:
+			fmt.Fprintf(&b, "[%d]", int(s))
+		case int:
+			fmt.Fprintf(&b, "[%d]", s)
+		}
+	}
+	return b.String()
 }
 
-func ParseGraphQLResponse(resp *http.Response) (*GraphQLResponse, error) {
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// GraphQLDataAt returns the value at path within the data of a response, and
+// whether the path leads anywhere. Fields that failed are usually null.
+func GraphQLDataAt(data any, path []any) (any, bool) {
+	for _, segment := range path {
+		switch s := segment.(type) {
+		case string:
+			object, ok := data.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if data, ok = object[s]; !ok {
+				return nil, false
+			}
+		default:
+			var i int
+			switch n := s.(type) {
+			case float64:
+				i = int(n)
+			case int:
+				i = n
+			default:
+				return nil, false
+			}
+			list, ok := data.([]any)
+			if !ok || i < 0 || i >= len(list) {
+				return nil, false
+			}
+			data = list[i]
+		}
 	}
+	return data, true
+}
 
-	var gqlResp GraphQLResponse
-	if err := json.Unmarshal(body, &gqlResp); err != nil {
-		return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
-	}
+func GraphQLMutation(url string, mutation string, args ...Args) (*http.Response, error) {
+	return GraphQLQuery(url, mutation, args...)
+}
 
-	return &gqlResp, nil
+// ParseGraphQLResponse reads the result of a GraphQL request. Streamed
+// responses, over Server-Sent Events or as multipart incremental payloads,
+// are merged into a single result.
+func ParseGraphQLResponse(resp *http.Response) (*GraphQLResponse, error) {
+	return ReadGraphQLStream(resp, nil)
 }
 
 func WithVariables(variables map[string]any) Args {
@@ -98,3 +233,13 @@ func WithVariables(variables map[string]any) Args {
 		arg.Body = bytes.NewBuffer(jsonVars)
 	}
 }
+
+// WithGET sends queries with GET, as URL parameters, so caches in between
+// can answer them. With WithPersistedQuery only the hash is sent with GET,
+// and the query itself with POST when the server does not know it yet.
+// Mutations are still sent with POST.
+func WithGET() Args {
+	return func(arg *Arg) {
+		arg.GET = true
+	}
+}
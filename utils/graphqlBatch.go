@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// GraphQLBatch sends several operations in one array payload, as servers
+// supporting query batching accept. The variables of args are sent with
+// every operation. Read the results with ParseGraphQLBatchResponse.
+func GraphQLBatch(url string, requests []GraphQLRequest, args ...Args) (*http.Response, error) {
+	arg := collectArgs(args)
+	variables := map[string]any{}
+	if err := json.NewDecoder(arg.Body).Decode(&variables); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid variables: %w", err)
+	}
+
+	batch := make([]GraphQLRequest, len(requests))
+	for i, req := range requests {
+		if req.Variables == nil && len(variables) > 0 {
+			req.Variables = variables
+		}
+		batch[i] = req
+	}
+	jsonBody, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	SetParams(&url, arg.Params)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SetHeaders(req, arg.Headers)
+
+	client := http.Client{}
+	return client.Do(req)
+}
+
+// ParseGraphQLBatchResponse reads the results of a batch, one per operation
+// in the order they were sent. A server that does not batch answers with a
+// single result, whose errors are returned.
+func ParseGraphQLBatchResponse(resp *http.Response) ([]GraphQLResponse, error) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []GraphQLResponse
+	if err := json.Unmarshal(body, &results); err == nil {
+		return results, nil
+	}
+	var single GraphQLResponse
+	if err := json.Unmarshal(body, &single); err != nil {
+		return nil, fmt.Errorf("failed to parse GraphQL batch response: %w", err)
+	}
+	if len(single.Errors) == 0 {
+		return nil, fmt.Errorf("the server answered the batch with a single result")
+	}
+	messages := make([]string, 0, len(single.Errors))
+	for _, gqlErr := range single.Errors {
+		messages = append(messages, gqlErr.Message)
+	}
+	return nil, fmt.Errorf("batch failed: %s", strings.Join(messages, "; "))
+}
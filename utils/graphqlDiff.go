@@ -0,0 +1,357 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// How much a schema change can affect existing clients.
+const (
+	// ChangeBreaking changes make valid operations fail.
+	ChangeBreaking = "BREAKING"
+	// ChangeDangerous changes keep operations valid but may change what
+	// clients receive, such as a new enum value a client does not handle.
+	ChangeDangerous = "DANGEROUS"
+	// ChangeSafe changes cannot affect existing operations.
+	ChangeSafe = "SAFE"
+)
+
+// GraphQLSchemaChange is a difference between two versions of a schema.
+type GraphQLSchemaChange struct {
+	Criticality string `json:"criticality"`
+	// Path is the schema coordinate of what changed, such as "User.email"
+	// or "Query.user(id:)".
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// schemaDiff collects the changes between an old and a new schema.
+type schemaDiff struct {
+	changes []GraphQLSchemaChange
+}
+
+func (d *schemaDiff) add(criticality, path, format string, a ...any) {
+	d.changes = append(d.changes, GraphQLSchemaChange{Criticality: criticality, Path: path, Message: fmt.Sprintf(format, a...)})
+}
+
+// DiffGraphQLSchemas lists the changes from oldSchema to newSchema: the
+// types, fields, arguments, enum values and directives that were added,
+// removed or changed, each classified by how it affects existing clients.
+// Changes are sorted by path.
+func DiffGraphQLSchemas(oldSchema, newSchema *GraphQLSchema) []GraphQLSchemaChange {
+	d := &schemaDiff{}
+
+	for _, root := range []struct {
+		name     string
+		old, new *GraphQLTypeRef
+	}{
+		{"query", oldSchema.QueryType, newSchema.QueryType},
+		{"mutation", oldSchema.MutationType, newSchema.MutationType},
+		{"subscription", oldSchema.SubscriptionType, newSchema.SubscriptionType},
+	} {
+		oldName, newName := rootName(root.old), rootName(root.new)
+		switch {
+		case oldName == newName:
+		case oldName == "":
+			d.add(ChangeSafe, "schema."+root.name, "Schema %s root type %q was added", root.name, newName)
+		case newName == "":
+			d.add(ChangeBreaking, "schema."+root.name, "Schema %s root type %q was removed", root.name, oldName)
+		default:
+			d.add(ChangeBreaking, "schema."+root.name, "Schema %s root type changed from %q to %q", root.name, oldName, newName)
+		}
+	}
+
+	oldTypes := userTypes(oldSchema)
+	newTypes := userTypes(newSchema)
+	for name, oldType := range oldTypes {
+		newType, ok := newTypes[name]
+		if !ok {
+			d.add(ChangeBreaking, name, "%s %q was removed", kindName(oldType.Kind), name)
+			continue
+		}
+		if oldType.Kind != newType.Kind {
+			d.add(ChangeBreaking, name, "%q changed kind from %s to %s", name, oldType.Kind, newType.Kind)
+			continue
+		}
+		d.diffType(oldType, newType)
+	}
+	for name, newType := range newTypes {
+		if _, ok := oldTypes[name]; !ok {
+			d.add(ChangeSafe, name, "%s %q was added", kindName(newType.Kind), name)
+		}
+	}
+
+	d.diffDirectives(oldSchema.Directives, newSchema.Directives)
+
+	slices.SortStableFunc(d.changes, func(a, b GraphQLSchemaChange) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Message, b.Message)
+	})
+	return d.changes
+}
+
+func (d *schemaDiff) diffType(oldType, newType *GraphQLType) {
+	name := oldType.Name
+	switch oldType.Kind {
+	case "OBJECT", "INTERFACE":
+		d.diffFields(name, oldType.Fields, newType.Fields)
+		d.diffMembers(name, "interface", oldType.Interfaces, newType.Interfaces, "no longer implements", "now implements")
+	case "UNION":
+		d.diffMembers(name, "member", oldType.PossibleTypes, newType.PossibleTypes, "no longer includes", "now includes")
+	case "INPUT_OBJECT":
+		d.diffInputs(name, "Input field", name+".", oldType.InputFields, newType.InputFields)
+	case "ENUM":
+		d.diffEnumValues(name, oldType.EnumValues, newType.EnumValues)
+	}
+}
+
+func (d *schemaDiff) diffFields(typeName string, oldFields, newFields []GraphQLField) {
+	for _, oldField := range oldFields {
+		path := typeName + "." + oldField.Name
+		idx := slices.IndexFunc(newFields, func(f GraphQLField) bool { return f.Name == oldField.Name })
+		if idx < 0 {
+			d.add(ChangeBreaking, path, "Field %q was removed", path)
+			continue
+		}
+		newField := newFields[idx]
+
+		if oldField.Type.String() != newField.Type.String() {
+			criticality := ChangeBreaking
+			if safeOutputChange(oldField.Type, newField.Type) {
+				criticality = ChangeSafe
+			}
+			d.add(criticality, path, "Field %q changed type from %q to %q", path, oldField.Type.String(), newField.Type.String())
+		}
+		switch {
+		case !oldField.IsDeprecated && newField.IsDeprecated:
+			d.add(ChangeSafe, path, "Field %q was deprecated", path)
+		case oldField.IsDeprecated && !newField.IsDeprecated:
+			d.add(ChangeSafe, path, "Field %q is no longer deprecated", path)
+		}
+		d.diffInputs(path, "Argument", path+"(", oldField.Args, newField.Args)
+	}
+	for _, newField := range newFields {
+		if !slices.ContainsFunc(oldFields, func(f GraphQLField) bool { return f.Name == newField.Name }) {
+			path := typeName + "." + newField.Name
+			d.add(ChangeSafe, path, "Field %q was added", path)
+		}
+	}
+}
+
+// diffInputs compares arguments or input fields. Their paths are prefix
+// followed by the name, closed with ":)" for arguments.
+func (d *schemaDiff) diffInputs(owner, what, prefix string, oldInputs, newInputs []GraphQLInput) {
+	path := func(name string) string {
+		if strings.HasSuffix(prefix, "(") {
+			return prefix + name + ":)"
+		}
+		return prefix + name
+	}
+	for _, oldInput := range oldInputs {
+		idx := slices.IndexFunc(newInputs, func(in GraphQLInput) bool { return in.Name == oldInput.Name })
+		if idx < 0 {
+			d.add(ChangeBreaking, path(oldInput.Name), "%s %q was removed from %q", what, oldInput.Name, owner)
+			continue
+		}
+		newInput := newInputs[idx]
+
+		if oldInput.Type.String() != newInput.Type.String() {
+			criticality := ChangeBreaking
+			if safeInputChange(oldInput.Type, newInput.Type) {
+				criticality = ChangeSafe
+			}
+			d.add(criticality, path(oldInput.Name), "%s %q of %q changed type from %q to %q", what, oldInput.Name, owner, oldInput.Type.String(), newInput.Type.String())
+		}
+		if !sameDefaultValue(oldInput.DefaultValue, newInput.DefaultValue) {
+			d.add(ChangeDangerous, path(oldInput.Name), "%s %q of %q changed default value from %s to %s", what, oldInput.Name, owner, describeDefault(oldInput.DefaultValue), describeDefault(newInput.DefaultValue))
+		}
+	}
+	for _, newInput := range newInputs {
+		if slices.ContainsFunc(oldInputs, func(in GraphQLInput) bool { return in.Name == newInput.Name }) {
+			continue
+		}
+		if newInput.Type.Kind == "NON_NULL" && newInput.DefaultValue == nil {
+			d.add(ChangeBreaking, path(newInput.Name), "Required %s %q was added to %q", strings.ToLower(what), newInput.Name, owner)
+		} else {
+			d.add(ChangeDangerous, path(newInput.Name), "Optional %s %q was added to %q", strings.ToLower(what), newInput.Name, owner)
+		}
+	}
+}
+
+// diffMembers compares the interfaces of a type or the members of a union.
+func (d *schemaDiff) diffMembers(typeName, what string, oldRefs, newRefs []GraphQLTypeRef, removed, added string) {
+	oldNames := refNames(oldRefs)
+	newNames := refNames(newRefs)
+	for _, name := range oldNames {
+		if !slices.Contains(newNames, name) {
+			d.add(ChangeBreaking, typeName, "%q %s %s %q", typeName, removed, what, name)
+		}
+	}
+	for _, name := range newNames {
+		if !slices.Contains(oldNames, name) {
+			d.add(ChangeDangerous, typeName, "%q %s %s %q", typeName, added, what, name)
+		}
+	}
+}
+
+func (d *schemaDiff) diffEnumValues(typeName string, oldValues, newValues []GraphQLEnumValue) {
+	for _, oldValue := range oldValues {
+		path := typeName + "." + oldValue.Name
+		idx := slices.IndexFunc(newValues, func(v GraphQLEnumValue) bool { return v.Name == oldValue.Name })
+		if idx < 0 {
+			d.add(ChangeBreaking, path, "Enum value %q was removed", path)
+			continue
+		}
+		switch newValue := newValues[idx]; {
+		case !oldValue.IsDeprecated && newValue.IsDeprecated:
+			d.add(ChangeSafe, path, "Enum value %q was deprecated", path)
+		case oldValue.IsDeprecated && !newValue.IsDeprecated:
+			d.add(ChangeSafe, path, "Enum value %q is no longer deprecated", path)
+		}
+	}
+	for _, newValue := range newValues {
+		if !slices.ContainsFunc(oldValues, func(v GraphQLEnumValue) bool { return v.Name == newValue.Name }) {
+			path := typeName + "." + newValue.Name
+			d.add(ChangeDangerous, path, "Enum value %q was added", path)
+		}
+	}
+}
+
+func (d *schemaDiff) diffDirectives(oldDirectives, newDirectives []GraphQLDirective) {
+	for _, oldDirective := range oldDirectives {
+		if slices.Contains(builtinDirectives, oldDirective.Name) {
+			continue
+		}
+		path := "@" + oldDirective.Name
+		idx := slices.IndexFunc(newDirectives, func(dir GraphQLDirective) bool { return dir.Name == oldDirective.Name })
+		if idx < 0 {
+			d.add(ChangeBreaking, path, "Directive %q was removed", path)
+			continue
+		}
+		newDirective := newDirectives[idx]
+		for _, location := range oldDirective.Locations {
+			if !slices.Contains(newDirective.Locations, location) {
+				d.add(ChangeBreaking, path, "Location %s was removed from directive %q", location, path)
+			}
+		}
+		for _, location := range newDirective.Locations {
+			if !slices.Contains(oldDirective.Locations, location) {
+				d.add(ChangeSafe, path, "Location %s was added to directive %q", location, path)
+			}
+		}
+		d.diffInputs(path, "Argument", path+"(", oldDirective.Args, newDirective.Args)
+	}
+	for _, newDirective := range newDirectives {
+		if slices.Contains(builtinDirectives, newDirective.Name) {
+			continue
+		}
+		if !slices.ContainsFunc(oldDirectives, func(dir GraphQLDirective) bool { return dir.Name == newDirective.Name }) {
+			d.add(ChangeSafe, "@"+newDirective.Name, "Directive %q was added", "@"+newDirective.Name)
+		}
+	}
+}
+
+// safeOutputChange reports whether a field may change from oldType to
+// newType without breaking clients: it may only become stricter, such as
+// String to String!.
+func safeOutputChange(oldType, newType GraphQLTypeRef) bool {
+	switch oldType.Kind {
+	case "LIST":
+		return (newType.Kind == "LIST" && safeOutputChange(*oldType.OfType, *newType.OfType)) ||
+			(newType.Kind == "NON_NULL" && safeOutputChange(oldType, *newType.OfType))
+	case "NON_NULL":
+		return newType.Kind == "NON_NULL" && safeOutputChange(*oldType.OfType, *newType.OfType)
+	}
+	return (newType.Kind != "LIST" && newType.Kind != "NON_NULL" && newType.Name == oldType.Name) ||
+		(newType.Kind == "NON_NULL" && safeOutputChange(oldType, *newType.OfType))
+}
+
+// safeInputChange reports whether an argument or input field may change
+// from oldType to newType without breaking clients: it may only become
+// looser, such as String! to String.
+func safeInputChange(oldType, newType GraphQLTypeRef) bool {
+	switch oldType.Kind {
+	case "LIST":
+		return newType.Kind == "LIST" && safeInputChange(*oldType.OfType, *newType.OfType)
+	case "NON_NULL":
+		return (newType.Kind == "NON_NULL" && safeInputChange(*oldType.OfType, *newType.OfType)) ||
+			(newType.Kind != "NON_NULL" && safeInputChange(*oldType.OfType, newType))
+	}
+	return newType.Kind != "LIST" && newType.Kind != "NON_NULL" && newType.Name == oldType.Name
+}
+
+// sameDefaultValue compares default values by their tokens, so that an
+// introspected default matches the same value written in SDL.
+func sameDefaultValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	aTokens, aErr := gqlTokenize(*a)
+	bTokens, bErr := gqlTokenize(*b)
+	if aErr != nil || bErr != nil || len(aTokens) != len(bTokens) {
+		return *a == *b
+	}
+	for i := range aTokens {
+		if aTokens[i].kind != bTokens[i].kind || aTokens[i].value != bTokens[i].value {
+			return false
+		}
+	}
+	return true
+}
+
+func describeDefault(value *string) string {
+	if value == nil {
+		return "none"
+	}
+	return *value
+}
+
+// userTypes returns the types of schema by name, leaving out the built-in
+// scalars and introspection types every schema has.
+func userTypes(schema *GraphQLSchema) map[string]*GraphQLType {
+	types := map[string]*GraphQLType{}
+	for i, t := range schema.Types {
+		if strings.HasPrefix(t.Name, "__") || slices.Contains(builtinScalars, t.Name) {
+			continue
+		}
+		types[t.Name] = &schema.Types[i]
+	}
+	return types
+}
+
+func rootName(ref *GraphQLTypeRef) string {
+	if ref == nil {
+		return ""
+	}
+	return ref.Name
+}
+
+func refNames(refs []GraphQLTypeRef) []string {
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, ref.Name)
+	}
+	return names
+}
+
+// kindName names a type kind as SDL spells it.
+func kindName(kind string) string {
+	switch kind {
+	case "OBJECT":
+		return "Type"
+	case "INPUT_OBJECT":
+		return "Input"
+	case "SCALAR":
+		return "Scalar"
+	case "INTERFACE":
+		return "Interface"
+	case "UNION":
+		return "Union"
+	case "ENUM":
+		return "Enum"
+	}
+	return kind
+}
@@ -0,0 +1,266 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// GraphQLOperation describes an operation of a document.
+type GraphQLOperation struct {
+	// Type is "query", "mutation" or "subscription".
+	Type      string
+	Name      string
+	Variables []GraphQLVariable
+}
+
+// GraphQLVariable is a variable an operation declares, at the line and
+// column of its definition.
+type GraphQLVariable struct {
+	Name       string
+	Type       GraphQLTypeRef
+	HasDefault bool
+	Line       int
+	Column     int
+}
+
+// WithOperationName picks the operation to run when the document holds
+// several.
+func WithOperationName(name string) Args {
+	return func(arg *Arg) {
+		arg.OperationName = name
+	}
+}
+
+// LoadGraphQLFragments reads the fragments defined in the .graphql and .gql
+// files under dir, by name. Operations in those files are ignored.
+func LoadGraphQLFragments(dir string) (map[string]string, error) {
+	fragments := map[string]string{}
+	defined := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (filepath.Ext(path) != ".graphql" && filepath.Ext(path) != ".gql") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		doc, err := parseGraphQLQuery(string(data))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, frag := range doc.fragments {
+			if other, ok := defined[frag.name]; ok {
+				return fmt.Errorf("fragment %q is defined in both %s and %s", frag.name, other, path)
+			}
+			defined[frag.name] = path
+			fragments[frag.name] = frag.text
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fragments, nil
+}
+
+// PrepareGraphQLDocument checks that operationName names an operation of
+// document, or that there is a single operation to run when it is empty,
+// and returns the document with the fragments it spreads but does not
+// define appended from fragments, as AppendGraphQLFragments does.
+func PrepareGraphQLDocument(document, operationName string, fragments map[string]string) (string, error) {
+	doc, err := parseGraphQLQuery(document)
+	if err != nil {
+		return "", err
+	}
+	if _, err := selectOperation(doc, operationName); err != nil {
+		return "", err
+	}
+	return AppendGraphQLFragments(document, fragments)
+}
+
+// ParseGraphQLOperation parses query and describes the operation named
+// operationName, or its only operation when the name is empty.
+func ParseGraphQLOperation(query, operationName string) (*GraphQLOperation, error) {
+	doc, err := parseGraphQLQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	op, err := selectOperation(doc, operationName)
+	if err != nil {
+		return nil, err
+	}
+
+	operation := &GraphQLOperation{Type: op.operation, Name: op.name}
+	for _, def := range op.variables {
+		operation.Variables = append(operation.Variables, GraphQLVariable{
+			Name:       def.name,
+			Type:       def.typ,
+			HasDefault: def.defaultValue != nil,
+			Line:       def.pos.line,
+			Column:     def.pos.column,
+		})
+	}
+	return operation, nil
+}
+
+// MissingVariables reports the variables of non-null types without a
+// default that variables does not provide. It needs no schema, so it can be
+// checked before anything is sent.
+func (op *GraphQLOperation) MissingVariables(variables map[string]any) []*GraphQLValidationError {
+	var missing []*GraphQLValidationError
+	for _, v := range op.Variables {
+		if v.Type.Kind != "NON_NULL" || v.HasDefault || variables[v.Name] != nil {
+			continue
+		}
+		missing = append(missing, &GraphQLValidationError{
+			Line:    v.Line,
+			Column:  v.Column,
+			Message: fmt.Sprintf("variable \"$%s\" of required type %q was not provided", v.Name, v.Type.String()),
+		})
+	}
+	return missing
+}
+
+// selectOperation returns the operation named operationName, or the only
+// operation of doc when the name is empty.
+func selectOperation(doc *gqlDocument, operationName string) (*gqlOperation, error) {
+	var names []string
+	for _, op := range doc.operations {
+		if operationName != "" && op.name == operationName {
+			return op, nil
+		}
+		names = append(names, op.name)
+	}
+	switch {
+	case len(doc.operations) == 0:
+		return nil, fmt.Errorf("the document has no operation to run")
+	case operationName != "":
+		return nil, fmt.Errorf("operation %q is not defined in the document%s", operationName, didYouMean(operationName, names))
+	case len(doc.operations) > 1:
+		return nil, fmt.Errorf("the document has %d operations, name the one to run: %s", len(doc.operations), strings.Join(names, ", "))
+	}
+	return doc.operations[0], nil
+}
+
+// AppendGraphQLFragments returns document with the fragments it spreads but
+// does not define appended from fragments, along with the ones those spread
+// in turn. The document itself is left as written, so the positions in
+// errors about it still match its source.
+func AppendGraphQLFragments(document string, fragments map[string]string) (string, error) {
+	doc, err := parseGraphQLQuery(document)
+	if err != nil {
+		return "", err
+	}
+
+	local := map[string]string{}
+	var spreads []string
+	for _, frag := range doc.fragments {
+		local[frag.name] = frag.text
+		spreads = fragmentSpreads(frag.selections, spreads)
+	}
+	for _, op := range doc.operations {
+		spreads = fragmentSpreads(op.selections, spreads)
+	}
+	names, err := fragmentClosure(spreads, local, fragments)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, name := range names {
+		if _, ok := local[name]; !ok {
+			b.WriteString("\n")
+			b.WriteString(fragments[name])
+			b.WriteString("\n")
+		}
+	}
+	if b.Len() == 0 {
+		return document, nil
+	}
+	return strings.TrimRight(document, " \t\r\n") + "\n" + b.String(), nil
+}
+
+// SplitGraphQLDocument returns a request for every operation of document,
+// each holding the operation and the fragments it spreads, in the order
+// they are defined.
+func SplitGraphQLDocument(document string) ([]GraphQLRequest, error) {
+	doc, err := parseGraphQLQuery(document)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("the document has no operation to run")
+	}
+
+	local := map[string]string{}
+	for _, frag := range doc.fragments {
+		local[frag.name] = frag.text
+	}
+	requests := make([]GraphQLRequest, 0, len(doc.operations))
+	for _, op := range doc.operations {
+		names, err := fragmentClosure(fragmentSpreads(op.selections, nil), local, nil)
+		if err != nil {
+			return nil, err
+		}
+		parts := []string{op.text}
+		for _, name := range names {
+			parts = append(parts, local[name])
+		}
+		requests = append(requests, GraphQLRequest{Query: strings.Join(parts, "\n\n"), OperationName: op.name})
+	}
+	return requests, nil
+}
+
+// fragmentSpreads appends the names of the fragments spread in selections,
+// including within inline fragments, to names.
+func fragmentSpreads(selections []*gqlSelection, names []string) []string {
+	for _, sel := range selections {
+		if sel.kind == gqlFragmentSpread {
+			names = append(names, sel.name)
+		}
+		names = fragmentSpreads(sel.selections, names)
+	}
+	return names
+}
+
+// fragmentClosure returns the names of the fragments spread by name in
+// spreads, and of those they spread in turn, sorted. Fragments defined in
+// local are preferred over those in external; unknown fragments are left
+// out for validation to report.
+func fragmentClosure(spreads []string, local, external map[string]string) ([]string, error) {
+	seen := map[string]bool{}
+	for len(spreads) > 0 {
+		name := spreads[len(spreads)-1]
+		spreads = spreads[:len(spreads)-1]
+		if seen[name] {
+			continue
+		}
+		text, ok := local[name]
+		if !ok {
+			if text, ok = external[name]; !ok {
+				continue
+			}
+		}
+		seen[name] = true
+		doc, err := parseGraphQLQuery(text)
+		if err != nil {
+			return nil, fmt.Errorf("fragment %q: %w", name, err)
+		}
+		for _, frag := range doc.fragments {
+			spreads = fragmentSpreads(frag.selections, spreads)
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
@@ -0,0 +1,278 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// IncrementalAccept is the Accept header of queries using @defer or
+// @stream: servers may answer with multipart/mixed incremental payloads.
+const IncrementalAccept = "multipart/mixed;deferSpec=20220824, application/graphql-response+json, application/json"
+
+// GraphQLSSE sends a query, mutation or subscription over Server-Sent
+// Events, as the graphql-sse protocol describes. The response streams one
+// result per event; read it with ReadGraphQLStream.
+func GraphQLSSE(url string, query string, args ...Args) (*http.Response, error) {
+	return graphQLPost(url, query, "text/event-stream", args)
+}
+
+// graphQLPayload is one result of a response: a complete result, or an
+// incremental one delivering data for @defer and @stream.
+type graphQLPayload struct {
+	Data    json.RawMessage `json:"data"`
+	Items   []any           `json:"items"`
+	Errors  []GraphQLError  `json:"errors"`
+	Path    []any           `json:"path"`
+	HasNext *bool           `json:"hasNext"`
+
+	Incremental []graphQLIncrementalResult `json:"incremental"`
+	// Pending and Completed identify incremental results by id, in newer
+	// versions of the incremental delivery format.
+	Pending []struct {
+		ID   string `json:"id"`
+		Path []any  `json:"path"`
+	} `json:"pending"`
+	Completed []struct {
+		ID     string         `json:"id"`
+		Errors []GraphQLError `json:"errors"`
+	} `json:"completed"`
+}
+
+type graphQLIncrementalResult struct {
+	ID      string          `json:"id"`
+	Path    []any           `json:"path"`
+	SubPath []any           `json:"subPath"`
+	Data    json.RawMessage `json:"data"`
+	Items   []any           `json:"items"`
+	Errors  []GraphQLError  `json:"errors"`
+}
+
+// graphQLMerger assembles the final result from the payloads of a stream.
+type graphQLMerger struct {
+	result  GraphQLResponse
+	pending map[string][]any
+}
+
+// ReadGraphQLStream reads a GraphQL response that may be a single JSON
+// result, a text/event-stream of results or multipart/mixed incremental
+// payloads. Each payload of a stream is handed to onChunk as it arrives,
+// if onChunk is not nil. The returned response holds the merged result.
+func ReadGraphQLStream(resp *http.Response, onChunk func(json.RawMessage)) (*GraphQLResponse, error) {
+	defer resp.Body.Close()
+
+	merger := &graphQLMerger{pending: map[string][]any{}}
+	handle := func(chunk []byte) (bool, error) {
+		chunk = bytes.TrimSpace(chunk)
+		if len(chunk) == 0 || string(chunk) == "{}" {
+			// Heartbeats keep idle streams open and carry nothing.
+			return false, nil
+		}
+		if onChunk != nil {
+			onChunk(json.RawMessage(chunk))
+		}
+		return merger.add(chunk)
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	var err error
+	switch mediaType {
+	case "text/event-stream":
+		err = readServerSentEvents(resp.Body, handle)
+	case "multipart/mixed":
+		err = readMultipart(resp.Body, params["boundary"], handle)
+	default:
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, readErr
+		}
+		var gqlResp GraphQLResponse
+		if err := json.Unmarshal(body, &gqlResp); err != nil {
+			return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+		}
+		return &gqlResp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &merger.result, nil
+}
+
+// readServerSentEvents hands the data of every "next" event to handle until
+// a "complete" event, the end of the stream, or handle reports it is done.
+func readServerSentEvents(body io.Reader, handle func([]byte) (bool, error)) error {
+	reader := bufio.NewReader(body)
+	var event string
+	var data []string
+	for {
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			// A blank line dispatches the event.
+			switch event {
+			case "complete":
+				return nil
+			case "", "next", "message":
+				if len(data) > 0 {
+					if done, err := handle([]byte(strings.Join(data, "\n"))); done || err != nil {
+						return err
+					}
+				}
+			}
+			event, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
+
+// readMultipart hands every part of a multipart/mixed body to handle until
+// the closing boundary or handle reports it is done.
+func readMultipart(body io.Reader, boundary string, handle func([]byte) (bool, error)) error {
+	if boundary == "" {
+		boundary = "-"
+	}
+	reader := multipart.NewReader(body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read multipart response: %w", err)
+		}
+		chunk, err := io.ReadAll(part)
+		if err != nil {
+			return fmt.Errorf("failed to read multipart response: %w", err)
+		}
+		if done, err := handle(chunk); done || err != nil {
+			return err
+		}
+	}
+}
+
+// add merges a payload into the result and reports whether it was the last.
+func (m *graphQLMerger) add(chunk []byte) (bool, error) {
+	var payload graphQLPayload
+	if err := json.Unmarshal(chunk, &payload); err != nil {
+		return false, fmt.Errorf("failed to parse GraphQL response: %w", err)
+	}
+	m.result.Errors = append(m.result.Errors, payload.Errors...)
+
+	for _, p := range payload.Pending {
+		m.pending[p.ID] = p.Path
+	}
+	switch {
+	case payload.Path != nil:
+		m.merge(payload.Path, payload.Data, listPath(payload.Path), payload.Items)
+	case payload.Data != nil:
+		// A complete result, such as the initial payload or the next event
+		// of a subscription, replaces what came before.
+		var data any
+		if err := json.Unmarshal(payload.Data, &data); err != nil {
+			return false, fmt.Errorf("failed to parse GraphQL response: %w", err)
+		}
+		m.result.Data = data
+	}
+	for _, inc := range payload.Incremental {
+		if inc.ID != "" {
+			// Pending streams point at the list itself rather than an item.
+			path := slices.Concat(m.pending[inc.ID], inc.SubPath)
+			m.merge(path, inc.Data, path, inc.Items)
+		} else {
+			m.merge(inc.Path, inc.Data, listPath(inc.Path), inc.Items)
+		}
+		m.result.Errors = append(m.result.Errors, inc.Errors...)
+	}
+	for _, c := range payload.Completed {
+		m.result.Errors = append(m.result.Errors, c.Errors...)
+		delete(m.pending, c.ID)
+	}
+	return payload.HasNext != nil && !*payload.HasNext, nil
+}
+
+// merge places deferred data at path in the result, and appends streamed
+// items to the list at listPath.
+func (m *graphQLMerger) merge(path []any, raw json.RawMessage, listPath []any, items []any) {
+	if raw != nil {
+		// Failed deferred fragments carry null data, which leaves the
+		// result as it was.
+		var data any
+		if json.Unmarshal(raw, &data) == nil && data != nil {
+			m.result.Data = updateAt(m.result.Data, path, func(target any) any {
+				return deepMerge(target, data)
+			})
+		}
+	}
+	if items != nil {
+		m.result.Data = updateAt(m.result.Data, listPath, func(target any) any {
+			list, _ := target.([]any)
+			return append(list, items...)
+		})
+	}
+}
+
+// listPath returns the path of the list streamed items belong to, given the
+// path of the first item.
+func listPath(itemPath []any) []any {
+	return itemPath[:max(len(itemPath)-1, 0)]
+}
+
+// updateAt replaces the value at path within node by what update returns.
+func updateAt(node any, path []any, update func(any) any) any {
+	if len(path) == 0 {
+		return update(node)
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		if key, ok := path[0].(string); ok {
+			n[key] = updateAt(n[key], path[1:], update)
+		}
+	case []any:
+		if i, ok := path[0].(float64); ok && int(i) >= 0 && int(i) < len(n) {
+			n[int(i)] = updateAt(n[int(i)], path[1:], update)
+		}
+	case nil:
+		if key, ok := path[0].(string); ok {
+			return map[string]any{key: updateAt(nil, path[1:], update)}
+		}
+	}
+	return node
+}
+
+func deepMerge(target, source any) any {
+	t, ok := target.(map[string]any)
+	s, ok2 := source.(map[string]any)
+	if !ok || !ok2 {
+		return source
+	}
+	for key, value := range s {
+		t[key] = deepMerge(t[key], value)
+	}
+	return t
+}
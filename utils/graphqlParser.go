@@ -0,0 +1,1010 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// GraphQLValidationError is a problem found in a GraphQL document, at the
+// line and column it starts at.
+type GraphQLValidationError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *GraphQLValidationError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+type gqlPos struct {
+	line, column int
+}
+
+type gqlTokenKind int
+
+const (
+	gqlEOF gqlTokenKind = iota
+	gqlPunct
+	gqlName
+	gqlInt
+	gqlFloat
+	gqlString
+)
+
+type gqlToken struct {
+	kind  gqlTokenKind
+	value string
+	pos   gqlPos
+	// start and end are the byte offsets of the token in the source.
+	start, end int
+}
+
+// gqlLexer splits a GraphQL document into tokens, skipping whitespace,
+// commas and comments.
+type gqlLexer struct {
+	src    string
+	offset int
+	pos    gqlPos
+}
+
+func (l *gqlLexer) errorf(pos gqlPos, format string, a ...any) *GraphQLValidationError {
+	return &GraphQLValidationError{Line: pos.line, Column: pos.column, Message: "syntax error: " + fmt.Sprintf(format, a...)}
+}
+
+func (l *gqlLexer) peek() rune {
+	if l.offset >= len(l.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.offset:])
+	return r
+}
+
+func (l *gqlLexer) next() rune {
+	r, size := utf8.DecodeRuneInString(l.src[l.offset:])
+	l.offset += size
+	switch {
+	case r == '\n' || (r == '\r' && l.peek() != '\n'):
+		l.pos.line++
+		l.pos.column = 1
+	default:
+		l.pos.column++
+	}
+	return r
+}
+
+func gqlTokenize(src string) ([]gqlToken, error) {
+	l := &gqlLexer{src: src, pos: gqlPos{1, 1}}
+	var tokens []gqlToken
+	for {
+		tok, err := l.token()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == gqlEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *gqlLexer) token() (gqlToken, error) {
+	for {
+		switch r := l.peek(); {
+		case r == ' ' || r == '\t' || r == ',' || r == '\n' || r == '\r' || r == '\ufeff':
+			l.next()
+			continue
+		case r == '#':
+			for r := l.peek(); r != -1 && r != '\n' && r != '\r'; r = l.peek() {
+				l.next()
+			}
+			continue
+		}
+		break
+	}
+
+	tok := gqlToken{pos: l.pos, start: l.offset}
+	r := l.peek()
+	switch {
+	case r == -1:
+		tok.kind = gqlEOF
+	case strings.ContainsRune("!$&():=@[]{}|", r):
+		l.next()
+		tok.kind, tok.value = gqlPunct, string(r)
+	case r == '.':
+		if !strings.HasPrefix(l.src[l.offset:], "...") {
+			return tok, l.errorf(l.pos, `unexpected "."; did you mean "..."?`)
+		}
+		l.next()
+		l.next()
+		l.next()
+		tok.kind, tok.value = gqlPunct, "..."
+	case r == '_' || isASCIILetter(r):
+		for r := l.peek(); r == '_' || isASCIILetter(r) || isASCIIDigit(r); r = l.peek() {
+			l.next()
+		}
+		tok.kind, tok.value = gqlName, l.src[tok.start:l.offset]
+	case r == '-' || isASCIIDigit(r):
+		kind, err := l.number()
+		if err != nil {
+			return tok, err
+		}
+		tok.kind, tok.value = kind, l.src[tok.start:l.offset]
+	case r == '"':
+		var (
+			value string
+			err   error
+		)
+		if strings.HasPrefix(l.src[l.offset:], `"""`) {
+			value, err = l.blockString()
+		} else {
+			value, err = l.string()
+		}
+		if err != nil {
+			return tok, err
+		}
+		tok.kind, tok.value = gqlString, value
+	default:
+		return tok, l.errorf(l.pos, "unexpected character %q", r)
+	}
+	tok.end = l.offset
+	return tok, nil
+}
+
+func (l *gqlLexer) number() (gqlTokenKind, error) {
+	kind := gqlInt
+	if l.peek() == '-' {
+		l.next()
+	}
+	digits := func() error {
+		if !isASCIIDigit(l.peek()) {
+			return l.errorf(l.pos, "invalid number, expected a digit")
+		}
+		for isASCIIDigit(l.peek()) {
+			l.next()
+		}
+		return nil
+	}
+	if l.peek() == '0' {
+		l.next()
+		if isASCIIDigit(l.peek()) {
+			return kind, l.errorf(l.pos, "invalid number, unexpected digit after 0")
+		}
+	} else if err := digits(); err != nil {
+		return kind, err
+	}
+	if l.peek() == '.' {
+		l.next()
+		kind = gqlFloat
+		if err := digits(); err != nil {
+			return kind, err
+		}
+	}
+	if r := l.peek(); r == 'e' || r == 'E' {
+		l.next()
+		kind = gqlFloat
+		if r := l.peek(); r == '+' || r == '-' {
+			l.next()
+		}
+		if err := digits(); err != nil {
+			return kind, err
+		}
+	}
+	if r := l.peek(); r == '.' || r == '_' || isASCIILetter(r) {
+		return kind, l.errorf(l.pos, "invalid number, unexpected %q", r)
+	}
+	return kind, nil
+}
+
+func (l *gqlLexer) string() (string, error) {
+	start := l.pos
+	l.next()
+	var b strings.Builder
+	for {
+		pos := l.pos
+		switch r := l.peek(); r {
+		case -1, '\n', '\r':
+			return "", l.errorf(start, "unterminated string")
+		case '"':
+			l.next()
+			return b.String(), nil
+		case '\\':
+			l.next()
+			escape := l.next()
+			switch escape {
+			case '"', '\\', '/':
+				b.WriteRune(escape)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				code, braced, ok := l.unicodeEscape()
+				// Code points beyond the Basic Multilingual Plane are
+				// written as a surrogate pair of fixed-width escapes.
+				if ok && !braced && utf16.IsSurrogate(code) && strings.HasPrefix(l.src[l.offset:], `\u`) {
+					l.next()
+					l.next()
+					var low rune
+					low, braced, ok = l.unicodeEscape()
+					code = utf16.DecodeRune(code, low)
+					ok = ok && !braced && code != utf8.RuneError
+				}
+				if !ok || !utf8.ValidRune(code) {
+					return "", l.errorf(pos, "invalid unicode escape")
+				}
+				b.WriteRune(code)
+			default:
+				return "", l.errorf(pos, "invalid escape sequence \\%c", escape)
+			}
+		default:
+			b.WriteRune(l.next())
+		}
+	}
+}
+
+// unicodeEscape reads the code point of a \u escape, after the "u": four
+// hex digits, or any number of them in braces.
+func (l *gqlLexer) unicodeEscape() (code rune, braced, ok bool) {
+	rest := l.src[l.offset:]
+	digits, width := rest[:min(4, len(rest))], 4
+	if strings.HasPrefix(rest, "{") {
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return 0, true, false
+		}
+		digits, width, braced = rest[1:end], end+1, true
+	}
+	if len(digits) == 0 || (!braced && len(digits) < 4) {
+		return 0, braced, false
+	}
+	n, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || n > unicode.MaxRune {
+		return 0, braced, false
+	}
+	for range width {
+		l.next()
+	}
+	return rune(n), braced, true
+}
+
+func (l *gqlLexer) blockString() (string, error) {
+	start := l.pos
+	for range 3 {
+		l.next()
+	}
+	var b strings.Builder
+	for {
+		rest := l.src[l.offset:]
+		switch {
+		case rest == "":
+			return "", l.errorf(start, "unterminated block string")
+		case strings.HasPrefix(rest, `"""`):
+			for range 3 {
+				l.next()
+			}
+			return blockStringValue(b.String()), nil
+		case strings.HasPrefix(rest, `\"""`):
+			for range 4 {
+				l.next()
+			}
+			b.WriteString(`"""`)
+		default:
+			b.WriteRune(l.next())
+		}
+	}
+}
+
+// blockStringValue removes the indentation and the blank first and last
+// lines of a block string, as the GraphQL spec describes.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(raw), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			lines[i] = lines[i][min(indent, len(lines[i])):]
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isASCIIDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// gqlDocument is a parsed executable document: the operations and fragments
+// of a query.
+type gqlDocument struct {
+	operations []*gqlOperation
+	fragments  []*gqlFragment
+}
+
+type gqlOperation struct {
+	pos        gqlPos
+	operation  string
+	name       string
+	variables  []*gqlVariableDefinition
+	directives []*gqlDirective
+	selections []*gqlSelection
+	// text is the definition as written in the source.
+	text string
+}
+
+type gqlVariableDefinition struct {
+	pos          gqlPos
+	name         string
+	typ          GraphQLTypeRef
+	defaultValue *gqlValue
+}
+
+type gqlFragment struct {
+	pos           gqlPos
+	name          string
+	typeCondition string
+	directives    []*gqlDirective
+	selections    []*gqlSelection
+	// text is the definition as written in the source.
+	text string
+}
+
+type gqlSelectionKind int
+
+const (
+	gqlField gqlSelectionKind = iota
+	gqlFragmentSpread
+	gqlInlineFragment
+)
+
+// gqlSelection is a field, a fragment spread or an inline fragment.
+type gqlSelection struct {
+	kind          gqlSelectionKind
+	pos           gqlPos
+	alias         string
+	name          string
+	typeCondition string
+	arguments     []*gqlArgument
+	directives    []*gqlDirective
+	selections    []*gqlSelection
+}
+
+type gqlArgument struct {
+	pos   gqlPos
+	name  string
+	value *gqlValue
+}
+
+type gqlDirective struct {
+	pos       gqlPos
+	name      string
+	arguments []*gqlArgument
+}
+
+type gqlValueKind int
+
+const (
+	gqlVariableValue gqlValueKind = iota
+	gqlIntValue
+	gqlFloatValue
+	gqlStringValue
+	gqlBooleanValue
+	gqlNullValue
+	gqlEnumValue
+	gqlListValue
+	gqlObjectValue
+)
+
+type gqlValue struct {
+	kind gqlValueKind
+	pos  gqlPos
+	// raw is the value as written in the document.
+	raw    string
+	value  string
+	list   []*gqlValue
+	fields []*gqlArgument
+}
+
+// gqlParser is a recursive descent parser over the tokens of a document.
+// The first error sticks: once it is set every method returns early and
+// the caller reports it.
+type gqlParser struct {
+	src    string
+	tokens []gqlToken
+	i      int
+	err    *GraphQLValidationError
+}
+
+func newGQLParser(src string) (*gqlParser, error) {
+	tokens, err := gqlTokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	return &gqlParser{src: src, tokens: tokens}, nil
+}
+
+func (p *gqlParser) tok() gqlToken {
+	return p.tokens[p.i]
+}
+
+func (p *gqlParser) advance() gqlToken {
+	tok := p.tokens[p.i]
+	if tok.kind != gqlEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *gqlParser) fail(tok gqlToken, format string, a ...any) {
+	if p.err == nil {
+		p.err = &GraphQLValidationError{Line: tok.pos.line, Column: tok.pos.column, Message: "syntax error: " + fmt.Sprintf(format, a...)}
+	}
+}
+
+func (p *gqlParser) describe(tok gqlToken) string {
+	switch tok.kind {
+	case gqlEOF:
+		return "end of document"
+	case gqlString:
+		return "string " + strconv.Quote(tok.value)
+	}
+	return fmt.Sprintf("%q", tok.value)
+}
+
+// is reports whether the current token is the punctuator or name s.
+func (p *gqlParser) is(s string) bool {
+	tok := p.tok()
+	return (tok.kind == gqlPunct || tok.kind == gqlName) && tok.value == s
+}
+
+// skip consumes the current token if it is s.
+func (p *gqlParser) skip(s string) bool {
+	if p.err == nil && p.is(s) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *gqlParser) expect(s string) gqlToken {
+	tok := p.tok()
+	if !p.is(s) {
+		p.fail(tok, "expected %q, found %s", s, p.describe(tok))
+		return tok
+	}
+	return p.advance()
+}
+
+func (p *gqlParser) name() gqlToken {
+	tok := p.tok()
+	if tok.kind != gqlName {
+		p.fail(tok, "expected a name, found %s", p.describe(tok))
+		return tok
+	}
+	return p.advance()
+}
+
+// many parses items between the open and close punctuators.
+func (p *gqlParser) many(open, close string, item func()) {
+	p.expect(open)
+	for p.err == nil && !p.skip(close) {
+		if p.tok().kind == gqlEOF {
+			p.fail(p.tok(), "expected %q, found end of document", close)
+			return
+		}
+		item()
+	}
+}
+
+// parseGraphQLQuery parses an executable document.
+func parseGraphQLQuery(src string) (*gqlDocument, error) {
+	p, err := newGQLParser(src)
+	if err != nil {
+		return nil, err
+	}
+	doc := &gqlDocument{}
+	if p.tok().kind == gqlEOF {
+		return nil, &GraphQLValidationError{Line: 1, Column: 1, Message: "syntax error: the document is empty"}
+	}
+	for p.err == nil && p.tok().kind != gqlEOF {
+		tok := p.tok()
+		switch {
+		case p.is("{") || p.is("query") || p.is("mutation") || p.is("subscription"):
+			doc.operations = append(doc.operations, p.operation())
+		case p.is("fragment"):
+			doc.fragments = append(doc.fragments, p.fragment())
+		default:
+			p.fail(tok, "expected an operation or a fragment, found %s", p.describe(tok))
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return doc, nil
+}
+
+func (p *gqlParser) operation() *gqlOperation {
+	start := p.tok().start
+	op := &gqlOperation{pos: p.tok().pos, operation: "query"}
+	if !p.is("{") {
+		op.operation = p.advance().value
+		if p.tok().kind == gqlName {
+			op.name = p.advance().value
+		}
+		if p.is("(") {
+			p.many("(", ")", func() {
+				op.variables = append(op.variables, p.variableDefinition())
+			})
+		}
+		op.directives = p.directives(false)
+	}
+	op.selections = p.selectionSet()
+	if p.err == nil {
+		op.text = p.src[start:p.tokens[p.i-1].end]
+	}
+	return op
+}
+
+func (p *gqlParser) variableDefinition() *gqlVariableDefinition {
+	def := &gqlVariableDefinition{pos: p.expect("$").pos}
+	def.name = p.name().value
+	p.expect(":")
+	def.typ = p.typeRef()
+	if p.skip("=") {
+		def.defaultValue = p.value(true)
+	}
+	p.directives(true)
+	return def
+}
+
+func (p *gqlParser) fragment() *gqlFragment {
+	start := p.tok().start
+	frag := &gqlFragment{pos: p.expect("fragment").pos}
+	nameTok := p.name()
+	if nameTok.value == "on" {
+		p.fail(nameTok, `a fragment cannot be named "on"`)
+	}
+	frag.name = nameTok.value
+	p.expect("on")
+	frag.typeCondition = p.name().value
+	frag.directives = p.directives(false)
+	frag.selections = p.selectionSet()
+	if p.err == nil {
+		frag.text = p.src[start:p.tokens[p.i-1].end]
+	}
+	return frag
+}
+
+func (p *gqlParser) selectionSet() []*gqlSelection {
+	selections := []*gqlSelection{}
+	p.many("{", "}", func() {
+		selections = append(selections, p.selection())
+	})
+	return selections
+}
+
+func (p *gqlParser) selection() *gqlSelection {
+	sel := &gqlSelection{pos: p.tok().pos}
+	if p.skip("...") {
+		switch {
+		case p.is("on"):
+			p.advance()
+			sel.kind = gqlInlineFragment
+			sel.typeCondition = p.name().value
+		case p.tok().kind == gqlName:
+			sel.kind = gqlFragmentSpread
+			sel.name = p.advance().value
+			sel.directives = p.directives(false)
+			return sel
+		default:
+			sel.kind = gqlInlineFragment
+		}
+		sel.directives = p.directives(false)
+		sel.selections = p.selectionSet()
+		return sel
+	}
+
+	sel.kind = gqlField
+	sel.name = p.name().value
+	if p.skip(":") {
+		sel.alias = sel.name
+		sel.name = p.name().value
+	}
+	sel.arguments = p.arguments(false)
+	sel.directives = p.directives(false)
+	if p.is("{") {
+		sel.selections = p.selectionSet()
+	}
+	return sel
+}
+
+func (p *gqlParser) arguments(constant bool) []*gqlArgument {
+	if !p.is("(") {
+		return nil
+	}
+	var args []*gqlArgument
+	p.many("(", ")", func() {
+		args = append(args, p.argument(constant))
+	})
+	return args
+}
+
+func (p *gqlParser) argument(constant bool) *gqlArgument {
+	nameTok := p.name()
+	p.expect(":")
+	return &gqlArgument{pos: nameTok.pos, name: nameTok.value, value: p.value(constant)}
+}
+
+func (p *gqlParser) directives(constant bool) []*gqlDirective {
+	var directives []*gqlDirective
+	for p.err == nil && p.is("@") {
+		d := &gqlDirective{pos: p.advance().pos}
+		d.name = p.name().value
+		d.arguments = p.arguments(constant)
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// typeRef parses a type such as [String!]!. Named types are left without a
+// kind until they are looked up in a schema.
+func (p *gqlParser) typeRef() GraphQLTypeRef {
+	var ref GraphQLTypeRef
+	if p.skip("[") {
+		of := p.typeRef()
+		p.expect("]")
+		ref = GraphQLTypeRef{Kind: "LIST", OfType: &of}
+	} else {
+		ref = GraphQLTypeRef{Name: p.name().value}
+	}
+	if p.skip("!") {
+		inner := ref
+		ref = GraphQLTypeRef{Kind: "NON_NULL", OfType: &inner}
+	}
+	return ref
+}
+
+// value parses an input value. Variables are not allowed in constant
+// values such as defaults.
+func (p *gqlParser) value(constant bool) *gqlValue {
+	tok := p.tok()
+	v := &gqlValue{pos: tok.pos, value: tok.value}
+	switch {
+	case tok.kind == gqlPunct && tok.value == "$":
+		if constant {
+			p.fail(tok, "unexpected variable in a constant value")
+			return v
+		}
+		p.advance()
+		v.kind, v.value = gqlVariableValue, p.name().value
+	case tok.kind == gqlPunct && tok.value == "[":
+		v.kind = gqlListValue
+		v.list = []*gqlValue{}
+		p.many("[", "]", func() {
+			v.list = append(v.list, p.value(constant))
+		})
+	case tok.kind == gqlPunct && tok.value == "{":
+		v.kind = gqlObjectValue
+		v.fields = []*gqlArgument{}
+		p.many("{", "}", func() {
+			v.fields = append(v.fields, p.argument(constant))
+		})
+	case tok.kind == gqlInt:
+		p.advance()
+		v.kind = gqlIntValue
+	case tok.kind == gqlFloat:
+		p.advance()
+		v.kind = gqlFloatValue
+	case tok.kind == gqlString:
+		p.advance()
+		v.kind = gqlStringValue
+	case tok.kind == gqlName:
+		p.advance()
+		switch tok.value {
+		case "true", "false":
+			v.kind = gqlBooleanValue
+		case "null":
+			v.kind = gqlNullValue
+		default:
+			v.kind = gqlEnumValue
+		}
+	default:
+		p.fail(tok, "expected a value, found %s", p.describe(tok))
+		return v
+	}
+	if p.err == nil {
+		v.raw = p.src[tok.start:p.tokens[p.i-1].end]
+	}
+	return v
+}
+
+// ParseGraphQLSDL reads a schema written in the GraphQL schema definition
+// language, such as one saved by --introspect. Types are kept in the order
+// they are defined and extensions are merged into the types they extend.
+func ParseGraphQLSDL(src string) (*GraphQLSchema, error) {
+	p, err := newGQLParser(src)
+	if err != nil {
+		return nil, err
+	}
+	schema := &GraphQLSchema{}
+	types := map[string]*GraphQLType{}
+	var order []string
+	hasSchema := false
+
+	for p.err == nil && p.tok().kind != gqlEOF {
+		description := p.description()
+		extend := p.skip("extend")
+		tok := p.tok()
+		switch {
+		case p.is("schema"):
+			p.advance()
+			hasSchema = true
+			p.directives(true)
+			p.many("{", "}", func() {
+				operation := p.name()
+				p.expect(":")
+				ref := &GraphQLTypeRef{Kind: "OBJECT", Name: p.name().value}
+				switch operation.value {
+				case "query":
+					schema.QueryType = ref
+				case "mutation":
+					schema.MutationType = ref
+				case "subscription":
+					schema.SubscriptionType = ref
+				default:
+					p.fail(operation, "unknown operation type %q", operation.value)
+				}
+			})
+		case p.is("directive"):
+			schema.Directives = append(schema.Directives, p.directiveDefinition(description))
+		case p.is("scalar") || p.is("type") || p.is("interface") || p.is("union") || p.is("enum") || p.is("input"):
+			t := p.typeDefinition(description)
+			existing, ok := types[t.Name]
+			switch {
+			case extend && ok:
+				mergeGraphQLType(existing, t)
+			case extend:
+				p.fail(tok, "cannot extend undefined type %q", t.Name)
+			case ok:
+				p.fail(tok, "type %q is defined more than once", t.Name)
+			default:
+				types[t.Name] = &t
+				order = append(order, t.Name)
+			}
+		default:
+			p.fail(tok, "expected a type system definition, found %s", p.describe(tok))
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	for _, name := range order {
+		schema.Types = append(schema.Types, *types[name])
+	}
+	if !hasSchema {
+		for _, root := range []struct {
+			name string
+			ref  **GraphQLTypeRef
+		}{
+			{"Query", &schema.QueryType},
+			{"Mutation", &schema.MutationType},
+			{"Subscription", &schema.SubscriptionType},
+		} {
+			if _, ok := types[root.name]; ok {
+				*root.ref = &GraphQLTypeRef{Kind: "OBJECT", Name: root.name}
+			}
+		}
+	}
+	resolveGraphQLKinds(schema)
+	return schema, nil
+}
+
+// description consumes the description string a definition may start with.
+func (p *gqlParser) description() string {
+	if p.err == nil && p.tok().kind == gqlString {
+		return p.advance().value
+	}
+	return ""
+}
+
+func (p *gqlParser) typeDefinition(description string) GraphQLType {
+	keyword := p.advance().value
+	t := GraphQLType{Name: p.name().value, Description: description}
+	switch keyword {
+	case "scalar":
+		t.Kind = "SCALAR"
+		p.directives(true)
+	case "type", "interface":
+		t.Kind = "OBJECT"
+		if keyword == "interface" {
+			t.Kind = "INTERFACE"
+		}
+		if p.skip("implements") {
+			p.skip("&")
+			for p.err == nil && p.tok().kind == gqlName {
+				t.Interfaces = append(t.Interfaces, GraphQLTypeRef{Kind: "INTERFACE", Name: p.advance().value})
+				p.skip("&")
+			}
+		}
+		p.directives(true)
+		if p.is("{") {
+			p.many("{", "}", func() {
+				t.Fields = append(t.Fields, p.fieldDefinition())
+			})
+		}
+	case "union":
+		t.Kind = "UNION"
+		p.directives(true)
+		if p.skip("=") {
+			p.skip("|")
+			t.PossibleTypes = append(t.PossibleTypes, GraphQLTypeRef{Kind: "OBJECT", Name: p.name().value})
+			for p.skip("|") {
+				t.PossibleTypes = append(t.PossibleTypes, GraphQLTypeRef{Kind: "OBJECT", Name: p.name().value})
+			}
+		}
+	case "enum":
+		t.Kind = "ENUM"
+		p.directives(true)
+		if p.is("{") {
+			p.many("{", "}", func() {
+				value := GraphQLEnumValue{Description: p.description(), Name: p.name().value}
+				value.IsDeprecated, value.DeprecationReason = deprecation(p.directives(true))
+				t.EnumValues = append(t.EnumValues, value)
+			})
+		}
+	case "input":
+		t.Kind = "INPUT_OBJECT"
+		p.directives(true)
+		if p.is("{") {
+			p.many("{", "}", func() {
+				t.InputFields = append(t.InputFields, p.inputValueDefinition())
+			})
+		}
+	}
+	return t
+}
+
+func (p *gqlParser) fieldDefinition() GraphQLField {
+	field := GraphQLField{Description: p.description(), Name: p.name().value, Args: []GraphQLInput{}}
+	if p.is("(") {
+		p.many("(", ")", func() {
+			field.Args = append(field.Args, p.inputValueDefinition())
+		})
+	}
+	p.expect(":")
+	field.Type = p.typeRef()
+	field.IsDeprecated, field.DeprecationReason = deprecation(p.directives(true))
+	return field
+}
+
+func (p *gqlParser) inputValueDefinition() GraphQLInput {
+	input := GraphQLInput{Description: p.description(), Name: p.name().value}
+	p.expect(":")
+	input.Type = p.typeRef()
+	if p.skip("=") {
+		if value := p.value(true); p.err == nil {
+			input.DefaultValue = &value.raw
+		}
+	}
+	p.directives(true)
+	return input
+}
+
+func (p *gqlParser) directiveDefinition(description string) GraphQLDirective {
+	p.expect("directive")
+	p.expect("@")
+	d := GraphQLDirective{Name: p.name().value, Description: description, Args: []GraphQLInput{}}
+	if p.is("(") {
+		p.many("(", ")", func() {
+			d.Args = append(d.Args, p.inputValueDefinition())
+		})
+	}
+	p.skip("repeatable")
+	p.expect("on")
+	p.skip("|")
+	d.Locations = append(d.Locations, p.name().value)
+	for p.skip("|") {
+		d.Locations = append(d.Locations, p.name().value)
+	}
+	return d
+}
+
+// deprecation reads the @deprecated directive among directives.
+func deprecation(directives []*gqlDirective) (bool, string) {
+	for _, d := range directives {
+		if d.name != "deprecated" {
+			continue
+		}
+		for _, arg := range d.arguments {
+			if arg.name == "reason" && arg.value.kind == gqlStringValue {
+				return true, arg.value.value
+			}
+		}
+		return true, defaultDeprecationReason
+	}
+	return false, ""
+}
+
+func mergeGraphQLType(t *GraphQLType, extension GraphQLType) {
+	t.Fields = append(t.Fields, extension.Fields...)
+	t.InputFields = append(t.InputFields, extension.InputFields...)
+	t.Interfaces = append(t.Interfaces, extension.Interfaces...)
+	t.EnumValues = append(t.EnumValues, extension.EnumValues...)
+	t.PossibleTypes = append(t.PossibleTypes, extension.PossibleTypes...)
+}
+
+// resolveGraphQLKinds fills in the kinds of the named type references of a
+// parsed schema and the possible types of its interfaces.
+func resolveGraphQLKinds(schema *GraphQLSchema) {
+	kinds := map[string]string{}
+	for _, name := range builtinScalars {
+		kinds[name] = "SCALAR"
+	}
+	for _, t := range schema.Types {
+		kinds[t.Name] = t.Kind
+	}
+	var resolve func(ref *GraphQLTypeRef)
+	resolve = func(ref *GraphQLTypeRef) {
+		if ref.OfType != nil {
+			resolve(ref.OfType)
+		} else if ref.Kind == "" {
+			ref.Kind = kinds[ref.Name]
+		}
+	}
+	resolveInputs := func(inputs []GraphQLInput) {
+		for i := range inputs {
+			resolve(&inputs[i].Type)
+		}
+	}
+
+	implementations := map[string][]GraphQLTypeRef{}
+	for i := range schema.Types {
+		t := &schema.Types[i]
+		for j := range t.Fields {
+			resolve(&t.Fields[j].Type)
+			resolveInputs(t.Fields[j].Args)
+		}
+		resolveInputs(t.InputFields)
+		if t.Kind == "OBJECT" {
+			for _, iface := range t.Interfaces {
+				implementations[iface.Name] = append(implementations[iface.Name], GraphQLTypeRef{Kind: "OBJECT", Name: t.Name})
+			}
+		}
+	}
+	for i := range schema.Types {
+		if t := &schema.Types[i]; t.Kind == "INTERFACE" {
+			t.PossibleTypes = implementations[t.Name]
+		}
+	}
+	for i := range schema.Directives {
+		resolveInputs(schema.Directives[i].Args)
+	}
+}
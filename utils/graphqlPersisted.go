@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+)
+
+type persistedQueryResult int
+
+const (
+	persistedQueryFound persistedQueryResult = iota
+	persistedQueryNotFound
+	persistedQueryNotSupported
+)
+
+// WithPersistedQuery sends queries as automatic persisted queries: the
+// sha256 hash of the query is sent first, and the full query only when the
+// server has not seen it yet.
+func WithPersistedQuery() Args {
+	return func(arg *Arg) {
+		arg.PersistedQuery = true
+	}
+}
+
+// persistedQueryStatus reports whether the server answered a hashed query
+// with PersistedQueryNotFound or PersistedQueryNotSupported, in which case
+// the response is closed. Otherwise the body is left to be read again.
+func persistedQueryStatus(resp *http.Response) persistedQueryResult {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" || mediaType == "multipart/mixed" {
+		// Streams only start once the query was found.
+		return persistedQueryFound
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var gqlResp GraphQLResponse
+	if json.Unmarshal(body, &gqlResp) != nil {
+		return persistedQueryFound
+	}
+	for _, gqlErr := range gqlResp.Errors {
+		code, _ := gqlErr.Extensions["code"].(string)
+		switch {
+		case gqlErr.Message == "PersistedQueryNotFound" || code == "PERSISTED_QUERY_NOT_FOUND":
+			return persistedQueryNotFound
+		case gqlErr.Message == "PersistedQueryNotSupported" || code == "PERSISTED_QUERY_NOT_SUPPORTED":
+			return persistedQueryNotSupported
+		}
+	}
+	return persistedQueryFound
+}
@@ -0,0 +1,425 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// IntrospectionQuery is the standard query that asks a GraphQL server to
+// describe its schema.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType {
+                kind
+                name
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// defaultDeprecationReason is the reason @deprecated implies when none is
+// given; it is left out when printing.
+const defaultDeprecationReason = "No longer supported"
+
+var (
+	builtinScalars    = []string{"String", "Int", "Float", "Boolean", "ID"}
+	builtinDirectives = []string{"skip", "include", "deprecated", "specifiedBy", "oneOf"}
+)
+
+// GraphQLSchema is the __schema part of an introspection result.
+type GraphQLSchema struct {
+	QueryType        *GraphQLTypeRef    `json:"queryType"`
+	MutationType     *GraphQLTypeRef    `json:"mutationType"`
+	SubscriptionType *GraphQLTypeRef    `json:"subscriptionType"`
+	Types            []GraphQLType      `json:"types"`
+	Directives       []GraphQLDirective `json:"directives"`
+}
+
+// GraphQLType is a named type of the schema.
+type GraphQLType struct {
+	Kind          string             `json:"kind"`
+	Name          string             `json:"name"`
+	Description   string             `json:"description"`
+	Fields        []GraphQLField     `json:"fields"`
+	InputFields   []GraphQLInput     `json:"inputFields"`
+	Interfaces    []GraphQLTypeRef   `json:"interfaces"`
+	EnumValues    []GraphQLEnumValue `json:"enumValues"`
+	PossibleTypes []GraphQLTypeRef   `json:"possibleTypes"`
+}
+
+type GraphQLField struct {
+	Name              string         `json:"name"`
+	Description       string         `json:"description"`
+	Args              []GraphQLInput `json:"args"`
+	Type              GraphQLTypeRef `json:"type"`
+	IsDeprecated      bool           `json:"isDeprecated"`
+	DeprecationReason string         `json:"deprecationReason"`
+}
+
+// GraphQLInput is an argument or a field of an input type.
+type GraphQLInput struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	Type         GraphQLTypeRef `json:"type"`
+	DefaultValue *string        `json:"defaultValue"`
+}
+
+type GraphQLEnumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason"`
+}
+
+// GraphQLTypeRef refers to a type, wrapped in lists and non-null markers
+// through OfType.
+type GraphQLTypeRef struct {
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name"`
+	OfType *GraphQLTypeRef `json:"ofType"`
+}
+
+type GraphQLDirective struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Locations   []string       `json:"locations"`
+	Args        []GraphQLInput `json:"args"`
+}
+
+// GraphQLIntrospect runs the introspection query against url and returns
+// the data of the response as is, e.g. to save it for other tools.
+func GraphQLIntrospect(url string, args ...Args) (json.RawMessage, error) {
+	resp, err := GraphQLQuery(url, IntrospectionQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		if resp.StatusCode >= 400 {
+			return nil, fmt.Errorf("introspection failed: %s", resp.Status)
+		}
+		return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, 0, len(result.Errors))
+		for _, gqlErr := range result.Errors {
+			messages = append(messages, gqlErr.Message)
+		}
+		return nil, fmt.Errorf("introspection failed: %s", strings.Join(messages, "; "))
+	}
+	if len(result.Data) == 0 || string(result.Data) == "null" {
+		return nil, errors.New("introspection failed: the response has no data")
+	}
+	return result.Data, nil
+}
+
+// ParseIntrospection decodes the data of an introspection response.
+func ParseIntrospection(data json.RawMessage) (*GraphQLSchema, error) {
+	var result struct {
+		Schema *GraphQLSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid introspection result: %w", err)
+	}
+	if result.Schema == nil {
+		return nil, errors.New("invalid introspection result: missing __schema")
+	}
+	return result.Schema, nil
+}
+
+// LoadGraphQLSchema reads a schema from a file. JSON files hold an
+// introspection result, with or without the "data" wrapper of the response;
+// any other file is read as SDL.
+func LoadGraphQLSchema(path string) (*GraphQLSchema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		schema, err := ParseGraphQLSDL(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+		}
+		return schema, nil
+	}
+
+	var wrapped struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(content, &wrapped); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+	if len(wrapped.Data) > 0 {
+		content = wrapped.Data
+	}
+	return ParseIntrospection(content)
+}
+
+// SDL prints the schema in the GraphQL schema definition language. Built-in
+// scalars, directives and introspection types are left out.
+func (s *GraphQLSchema) SDL() string {
+	var blocks []string
+	if block := s.schemaBlock(); block != "" {
+		blocks = append(blocks, block)
+	}
+	for _, d := range s.Directives {
+		if !slices.Contains(builtinDirectives, d.Name) {
+			blocks = append(blocks, printDirective(d))
+		}
+	}
+	for _, t := range s.Types {
+		if strings.HasPrefix(t.Name, "__") || (t.Kind == "SCALAR" && slices.Contains(builtinScalars, t.Name)) {
+			continue
+		}
+		blocks = append(blocks, printType(t))
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// schemaBlock prints the schema definition, which is only needed when the
+// root types are not named Query, Mutation and Subscription.
+func (s *GraphQLSchema) schemaBlock() string {
+	roots := []struct {
+		operation, defaultName string
+		ref                    *GraphQLTypeRef
+	}{
+		{"query", "Query", s.QueryType},
+		{"mutation", "Mutation", s.MutationType},
+		{"subscription", "Subscription", s.SubscriptionType},
+	}
+	conventional := true
+	var lines []string
+	for _, root := range roots {
+		if root.ref == nil {
+			continue
+		}
+		if root.ref.Name != root.defaultName {
+			conventional = false
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", root.operation, root.ref.Name))
+	}
+	if conventional {
+		return ""
+	}
+	return "schema {\n" + strings.Join(lines, "\n") + "\n}"
+}
+
+func printType(t GraphQLType) string {
+	var b strings.Builder
+	b.WriteString(printDescription(t.Description, ""))
+
+	switch t.Kind {
+	case "SCALAR":
+		fmt.Fprintf(&b, "scalar %s", t.Name)
+	case "OBJECT", "INTERFACE":
+		keyword := "type"
+		if t.Kind == "INTERFACE" {
+			keyword = "interface"
+		}
+		fmt.Fprintf(&b, "%s %s%s", keyword, t.Name, printImplements(t.Interfaces))
+		lines := make([]string, 0, len(t.Fields))
+		for _, f := range t.Fields {
+			lines = append(lines, printDescription(f.Description, "  ")+"  "+f.Name+printArgs(f.Args)+": "+f.Type.String()+printDeprecated(f.IsDeprecated, f.DeprecationReason))
+		}
+		b.WriteString(printBlock(lines))
+	case "UNION":
+		names := make([]string, 0, len(t.PossibleTypes))
+		for _, p := range t.PossibleTypes {
+			names = append(names, p.Name)
+		}
+		fmt.Fprintf(&b, "union %s", t.Name)
+		if len(names) > 0 {
+			b.WriteString(" = " + strings.Join(names, " | "))
+		}
+	case "ENUM":
+		fmt.Fprintf(&b, "enum %s", t.Name)
+		lines := make([]string, 0, len(t.EnumValues))
+		for _, v := range t.EnumValues {
+			lines = append(lines, printDescription(v.Description, "  ")+"  "+v.Name+printDeprecated(v.IsDeprecated, v.DeprecationReason))
+		}
+		b.WriteString(printBlock(lines))
+	case "INPUT_OBJECT":
+		fmt.Fprintf(&b, "input %s", t.Name)
+		lines := make([]string, 0, len(t.InputFields))
+		for _, f := range t.InputFields {
+			lines = append(lines, printDescription(f.Description, "  ")+"  "+printInput(f))
+		}
+		b.WriteString(printBlock(lines))
+	}
+	return b.String()
+}
+
+func printDirective(d GraphQLDirective) string {
+	return printDescription(d.Description, "") + "directive @" + d.Name + printArgs(d.Args) + " on " + strings.Join(d.Locations, " | ")
+}
+
+func printImplements(interfaces []GraphQLTypeRef) string {
+	if len(interfaces) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(interfaces))
+	for _, i := range interfaces {
+		names = append(names, i.Name)
+	}
+	return " implements " + strings.Join(names, " & ")
+}
+
+func printBlock(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return " {\n" + strings.Join(lines, "\n") + "\n}"
+}
+
+// printArgs prints arguments inline, or one per line when any of them has
+// a description.
+func printArgs(args []GraphQLInput) string {
+	if len(args) == 0 {
+		return ""
+	}
+	described := slices.ContainsFunc(args, func(a GraphQLInput) bool { return a.Description != "" })
+	parts := make([]string, 0, len(args))
+	for _, a := range args {
+		if described {
+			parts = append(parts, printDescription(a.Description, "    ")+"    "+printInput(a))
+		} else {
+			parts = append(parts, printInput(a))
+		}
+	}
+	if described {
+		return "(\n" + strings.Join(parts, "\n") + "\n  )"
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func printInput(in GraphQLInput) string {
+	s := in.Name + ": " + in.Type.String()
+	if in.DefaultValue != nil {
+		s += " = " + *in.DefaultValue
+	}
+	return s
+}
+
+func printDeprecated(deprecated bool, reason string) string {
+	if !deprecated {
+		return ""
+	}
+	if reason == "" || reason == defaultDeprecationReason {
+		return " @deprecated"
+	}
+	quoted, _ := json.Marshal(reason)
+	return fmt.Sprintf(" @deprecated(reason: %s)", quoted)
+}
+
+// printDescription prints a description as a block string on the lines
+// before the definition it belongs to.
+func printDescription(description, indent string) string {
+	if description == "" {
+		return ""
+	}
+	description = strings.ReplaceAll(description, `"""`, `\"""`)
+	if !strings.Contains(description, "\n") {
+		return indent + `"""` + description + `"""` + "\n"
+	}
+	var b strings.Builder
+	b.WriteString(indent + `"""` + "\n")
+	for line := range strings.Lines(description) {
+		b.WriteString(indent + strings.TrimRight(line, "\n") + "\n")
+	}
+	b.WriteString(indent + `"""` + "\n")
+	return b.String()
+}
+
+// String prints the type reference as written in SDL, e.g. [String!]!.
+func (r GraphQLTypeRef) String() string {
+	switch {
+	case r.Kind == "NON_NULL" && r.OfType != nil:
+		return r.OfType.String() + "!"
+	case r.Kind == "LIST" && r.OfType != nil:
+		return "[" + r.OfType.String() + "]"
+	}
+	return r.Name
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket subprotocols GraphQL subscriptions are carried over.
+const (
+	// GraphQLTransportWS is the protocol of the graphql-ws library.
+	GraphQLTransportWS = "graphql-transport-ws"
+	// GraphQLWS is the legacy protocol of subscriptions-transport-ws.
+	GraphQLWS = "graphql-ws"
+)
+
+// subscriptionID is the id of the single operation a connection carries.
+const subscriptionID = "1"
+
+type graphQLWSMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// WithGraphQLSubprotocol picks the WebSocket subprotocol of a subscription.
+// Without it both are offered and the server chooses.
+func WithGraphQLSubprotocol(protocol string) Args {
+	return func(arg *Arg) {
+		arg.Subprotocol = protocol
+	}
+}
+
+// WithConnectionPayload sets the payload of the connection_init message,
+// which servers commonly read credentials from.
+func WithConnectionPayload(payload map[string]any) Args {
+	return func(arg *Arg) {
+		arg.ConnectionPayload = payload
+	}
+}
+
+// ValidGraphQLSubprotocol reports whether protocol is a supported
+// subscription subprotocol.
+func ValidGraphQLSubprotocol(protocol string) bool {
+	return protocol == GraphQLTransportWS || protocol == GraphQLWS
+}
+
+// GraphQLSubscribe runs a subscription over WebSocket and hands every event
+// to onEvent as it arrives. An http(s) url is dialled as ws(s). It returns
+// once the server completes the subscription, or stops it and returns nil
+// when ctx is cancelled. Errors sent by the server end the subscription
+// and are returned.
+func GraphQLSubscribe(ctx context.Context, url, query string, onEvent func(*GraphQLResponse), args ...Args) error {
+	arg := collectArgs(args)
+	variables := map[string]any{}
+	if err := json.NewDecoder(arg.Body).Decode(&variables); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid variables: %w", err)
+	}
+
+	switch {
+	case strings.HasPrefix(url, "http://"):
+		url = "ws://" + strings.TrimPrefix(url, "http://")
+	case strings.HasPrefix(url, "https://"):
+		url = "wss://" + strings.TrimPrefix(url, "https://")
+	}
+	SetParams(&url, arg.Params)
+	header := http.Header{}
+	for key, val := range arg.Headers {
+		header.Set(key, val)
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 45 * time.Second,
+		TLSClientConfig:  arg.TLS,
+		Subprotocols:     []string{GraphQLTransportWS, GraphQLWS},
+	}
+	if arg.Subprotocol != "" {
+		dialer.Subprotocols = []string{arg.Subprotocol}
+	}
+	conn, _, err := dialer.DialContext(ctx, url, header)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer conn.Close()
+
+	protocol := conn.Subprotocol()
+	if protocol == "" {
+		protocol = dialer.Subprotocols[0]
+	}
+	start, stop := "subscribe", "complete"
+	if protocol == GraphQLWS {
+		start, stop = "start", "stop"
+	}
+
+	send := func(msg graphQLWSMessage) error {
+		return conn.WriteJSON(msg)
+	}
+	initPayload, _ := json.Marshal(arg.ConnectionPayload)
+	if arg.ConnectionPayload == nil {
+		initPayload = nil
+	}
+	if err := send(graphQLWSMessage{Type: "connection_init", Payload: initPayload}); err != nil {
+		return err
+	}
+
+	messages := make(chan graphQLWSMessage)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			var msg graphQLWSMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case messages <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Stop the operation and say goodbye; the server may already be gone.
+			send(graphQLWSMessage{ID: subscriptionID, Type: stop})
+			if protocol == GraphQLWS {
+				send(graphQLWSMessage{Type: "connection_terminate"})
+			}
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return nil
+		case err := <-readErr:
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) {
+				if closeErr.Code == websocket.CloseNormalClosure {
+					return nil
+				}
+				return fmt.Errorf("the server closed the connection: %d %s", closeErr.Code, closeErr.Text)
+			}
+			return err
+		case msg := <-messages:
+			switch msg.Type {
+			case "connection_ack":
+				payload, _ := json.Marshal(GraphQLRequest{Query: query, OperationName: arg.OperationName, Variables: variables})
+				if err := send(graphQLWSMessage{ID: subscriptionID, Type: start, Payload: payload}); err != nil {
+					return err
+				}
+			case "ping":
+				if err := send(graphQLWSMessage{Type: "pong", Payload: msg.Payload}); err != nil {
+					return err
+				}
+			case "next", "data":
+				var event GraphQLResponse
+				if err := json.Unmarshal(msg.Payload, &event); err != nil {
+					return fmt.Errorf("failed to parse GraphQL response: %w", err)
+				}
+				onEvent(&event)
+			case "error", "connection_error":
+				return subscriptionError(msg.Payload)
+			case "complete":
+				return nil
+			}
+		}
+	}
+}
+
+// subscriptionError describes the payload of an error message: a list of
+// GraphQL errors, or a single one in the legacy protocol.
+func subscriptionError(payload json.RawMessage) error {
+	var list []GraphQLError
+	if err := json.Unmarshal(payload, &list); err != nil {
+		var single GraphQLError
+		if err := json.Unmarshal(payload, &single); err != nil || single.Message == "" {
+			return fmt.Errorf("subscription failed: %s", payload)
+		}
+		list = []GraphQLError{single}
+	}
+	messages := make([]string, 0, len(list))
+	for _, gqlErr := range list {
+		messages = append(messages, gqlErr.Message)
+	}
+	return fmt.Errorf("subscription failed: %s", strings.Join(messages, "; "))
+}
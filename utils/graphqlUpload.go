@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GraphQLUpload is a file sent along with an operation, as the GraphQL
+// multipart request spec describes.
+type GraphQLUpload struct {
+	// Path is where the file goes in the operation, such as
+	// "variables.avatar" or "variables.photos.0" for an item of a list.
+	Path string
+	// File is the path of the file to send.
+	File string
+}
+
+// WithUploads sends the operation as multipart/form-data with the files
+// attached. Uploads sharing a path without an index fill a list.
+func WithUploads(uploads ...GraphQLUpload) Args {
+	return func(arg *Arg) {
+		arg.Uploads = append(arg.Uploads, uploads...)
+	}
+}
+
+// ParseGraphQLUpload reads an upload written as path=@file, such as
+// variables.avatar=@./photo.png. The "variables." prefix may be left out.
+func ParseGraphQLUpload(s string) (GraphQLUpload, error) {
+	path, file, ok := strings.Cut(s, "=@")
+	if !ok || path == "" || file == "" {
+		return GraphQLUpload{}, fmt.Errorf("invalid upload %q, expected path=@file", s)
+	}
+	if !strings.HasPrefix(path, "variables.") {
+		path = "variables." + path
+	}
+	return GraphQLUpload{Path: path, File: file}, nil
+}
+
+// GraphQLUploadVariables returns a copy of variables with the name of each
+// uploaded file at its path, which is how they look to a server once the
+// files are attached. It is meant for checking the variables of an upload.
+func GraphQLUploadVariables(variables map[string]any, uploads []GraphQLUpload) (map[string]any, error) {
+	data, _ := json.Marshal(variables)
+	copied := map[string]any{}
+	json.Unmarshal(data, &copied)
+
+	operations := map[string]any{"variables": copied}
+	for _, upload := range uploadPaths(uploads) {
+		var err error
+		if operations, err = setUploadPath(operations, upload.Path, filepath.Base(upload.File)); err != nil {
+			return nil, err
+		}
+	}
+	result, _ := operations["variables"].(map[string]any)
+	return result, nil
+}
+
+// uploadPaths numbers the uploads that share a path without an index, so
+// they fill a list in the order they are given.
+func uploadPaths(uploads []GraphQLUpload) []GraphQLUpload {
+	count := map[string]int{}
+	for _, upload := range uploads {
+		count[upload.Path]++
+	}
+	next := map[string]int{}
+	numbered := make([]GraphQLUpload, 0, len(uploads))
+	for _, upload := range uploads {
+		if count[upload.Path] > 1 {
+			path := upload.Path
+			upload.Path = fmt.Sprintf("%s.%d", path, next[path])
+			next[path]++
+		}
+		numbered = append(numbered, upload)
+	}
+	return numbered
+}
+
+// setUploadPath sets the value at a dotted path within operations, creating
+// the objects and growing the lists on the way.
+func setUploadPath(operations map[string]any, path string, value any) (map[string]any, error) {
+	segments := strings.Split(path, ".")
+	if segments[0] != "variables" || len(segments) < 2 {
+		return nil, fmt.Errorf("invalid upload path %q, expected it within variables", path)
+	}
+	var set func(node any, segments []string) (any, error)
+	set = func(node any, segments []string) (any, error) {
+		if len(segments) == 0 {
+			return value, nil
+		}
+		if i, err := strconv.Atoi(segments[0]); err == nil {
+			if i < 0 {
+				return nil, fmt.Errorf("invalid upload path %q: negative index", path)
+			}
+			list, _ := node.([]any)
+			if node != nil && list == nil {
+				return nil, fmt.Errorf("invalid upload path %q: %s is not a list", path, segments[0])
+			}
+			for len(list) <= i {
+				list = append(list, nil)
+			}
+			item, err := set(list[i], segments[1:])
+			list[i] = item
+			return list, err
+		}
+		object, _ := node.(map[string]any)
+		if node != nil && object == nil {
+			return nil, fmt.Errorf("invalid upload path %q: %s is not an object", path, segments[0])
+		}
+		if object == nil {
+			object = map[string]any{}
+		}
+		child, err := set(object[segments[0]], segments[1:])
+		object[segments[0]] = child
+		return object, err
+	}
+	result, err := set(operations, segments)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]any), nil
+}
+
+// sendGraphQLUpload sends a request as multipart/form-data: the operations,
+// the map from each file to the paths it fills, and the files.
+func sendGraphQLUpload(url string, reqBody GraphQLRequest, accept string, arg Arg) (*http.Response, error) {
+	operations := map[string]any{"query": reqBody.Query, "variables": reqBody.Variables}
+	if reqBody.OperationName != "" {
+		operations["operationName"] = reqBody.OperationName
+	}
+	if operations["variables"] == nil {
+		operations["variables"] = map[string]any{}
+	}
+
+	// A file given for several paths is only sent once.
+	fileMap := map[string][]string{}
+	var files []string
+	keys := map[string]string{}
+	for _, upload := range uploadPaths(arg.Uploads) {
+		var err error
+		if operations, err = setUploadPath(operations, upload.Path, nil); err != nil {
+			return nil, err
+		}
+		key, ok := keys[upload.File]
+		if !ok {
+			key = strconv.Itoa(len(files))
+			keys[upload.File] = key
+			files = append(files, upload.File)
+		}
+		fileMap[key] = append(fileMap[key], upload.Path)
+	}
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	operationsJSON, err := json.Marshal(operations)
+	if err != nil {
+		return nil, err
+	}
+	mapJSON, _ := json.Marshal(fileMap)
+	writer.WriteField("operations", string(operationsJSON))
+	writer.WriteField("map", string(mapJSON))
+	for i, file := range files {
+		if err := writeUploadPart(writer, strconv.Itoa(i), file); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	SetParams(&url, arg.Params)
+	req, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	// Servers guarding against CSRF reject multipart requests without it.
+	req.Header.Set("Apollo-Require-Preflight", "true")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	SetHeaders(req, arg.Headers)
+
+	client := http.Client{}
+	return client.Do(req)
+}
+
+func writeUploadPart(writer *multipart.Writer, key, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open upload: %w", err)
+	}
+	defer file.Close()
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, key, filepath.Base(path)))
+	header.Set("Content-Type", detectContentType(file))
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("failed to read upload: %w", err)
+	}
+	return nil
+}
+
+// detectContentType sniffs the media type of file and rewinds it.
+func detectContentType(file *os.File) string {
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	file.Seek(0, io.SeekStart)
+	return http.DetectContentType(head[:n])
+}
@@ -0,0 +1,688 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// builtinGraphQLDirectives are the directives queries may use whether or
+// not the schema lists them: @skip and @include, which every server
+// supports, and @defer and @stream, which servers without incremental
+// delivery ignore.
+var builtinGraphQLDirectives = []GraphQLDirective{
+	{
+		Name:      "skip",
+		Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:      []GraphQLInput{{Name: "if", Type: nonNullRef("Boolean")}},
+	},
+	{
+		Name:      "include",
+		Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:      []GraphQLInput{{Name: "if", Type: nonNullRef("Boolean")}},
+	},
+	{
+		Name:      "defer",
+		Locations: []string{"FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args: []GraphQLInput{
+			{Name: "if", Type: nonNullRef("Boolean"), DefaultValue: &trueLiteral},
+			{Name: "label", Type: GraphQLTypeRef{Kind: "SCALAR", Name: "String"}},
+		},
+	},
+	{
+		Name:      "stream",
+		Locations: []string{"FIELD"},
+		Args: []GraphQLInput{
+			{Name: "if", Type: nonNullRef("Boolean"), DefaultValue: &trueLiteral},
+			{Name: "label", Type: GraphQLTypeRef{Kind: "SCALAR", Name: "String"}},
+			{Name: "initialCount", Type: GraphQLTypeRef{Kind: "SCALAR", Name: "Int"}, DefaultValue: &zeroLiteral},
+		},
+	},
+}
+
+var trueLiteral, zeroLiteral = "true", "0"
+
+func nonNullRef(name string) GraphQLTypeRef {
+	return GraphQLTypeRef{Kind: "NON_NULL", OfType: &GraphQLTypeRef{Kind: "SCALAR", Name: name}}
+}
+
+// gqlVariableUsage is a place a variable is used and the type expected
+// there.
+type gqlVariableUsage struct {
+	pos        gqlPos
+	name       string
+	expected   GraphQLTypeRef
+	hasDefault bool
+}
+
+type gqlValidator struct {
+	schema     *GraphQLSchema
+	types      map[string]*GraphQLType
+	directives map[string]*GraphQLDirective
+	fragments  map[string]*gqlFragment
+	errs       []*GraphQLValidationError
+
+	// The state of the operation being validated.
+	usages  []gqlVariableUsage
+	visited map[string]bool
+	used    map[string]bool
+}
+
+// ValidateGraphQL checks query against schema before it is sent: its
+// syntax, the fields and arguments it selects and the values of its
+// variables. Variables are checked against the operation named
+// operationName, or the only operation when it is empty. It returns the
+// problems found, in the order they appear.
+func ValidateGraphQL(schema *GraphQLSchema, query, operationName string, variables map[string]any) []*GraphQLValidationError {
+	doc, err := parseGraphQLQuery(query)
+	if err != nil {
+		return []*GraphQLValidationError{err.(*GraphQLValidationError)}
+	}
+
+	v := &gqlValidator{
+		schema:     schema,
+		types:      map[string]*GraphQLType{},
+		directives: map[string]*GraphQLDirective{},
+		fragments:  map[string]*gqlFragment{},
+		used:       map[string]bool{},
+	}
+	for _, name := range builtinScalars {
+		v.types[name] = &GraphQLType{Kind: "SCALAR", Name: name}
+	}
+	for i := range schema.Types {
+		v.types[schema.Types[i].Name] = &schema.Types[i]
+	}
+	for i := range builtinGraphQLDirectives {
+		v.directives[builtinGraphQLDirectives[i].Name] = &builtinGraphQLDirectives[i]
+	}
+	for i := range schema.Directives {
+		v.directives[schema.Directives[i].Name] = &schema.Directives[i]
+	}
+
+	for _, frag := range doc.fragments {
+		if _, ok := v.fragments[frag.name]; ok {
+			v.errorf(frag.pos, "fragment %q is defined more than once", frag.name)
+		}
+		v.fragments[frag.name] = frag
+	}
+
+	names := map[string]bool{}
+	for _, op := range doc.operations {
+		if op.name == "" && len(doc.operations) > 1 {
+			v.errorf(op.pos, "an anonymous operation must be the only operation in the document")
+		}
+		if op.name != "" && names[op.name] {
+			v.errorf(op.pos, "operation %q is defined more than once", op.name)
+		}
+		names[op.name] = true
+		if op.name == operationName || (operationName == "" && len(doc.operations) == 1) {
+			if variables == nil {
+				variables = map[string]any{}
+			}
+			v.operation(op, variables)
+		} else {
+			v.operation(op, nil)
+		}
+	}
+	if operationName != "" && !names[operationName] {
+		v.errorf(gqlPos{1, 1}, "operation %q is not defined in the document", operationName)
+	}
+
+	for _, frag := range doc.fragments {
+		if !v.used[frag.name] {
+			v.errorf(frag.pos, "fragment %q is never used", frag.name)
+		}
+	}
+	return v.result()
+}
+
+func (v *gqlValidator) errorf(pos gqlPos, format string, a ...any) {
+	v.errs = append(v.errs, &GraphQLValidationError{Line: pos.line, Column: pos.column, Message: fmt.Sprintf(format, a...)})
+}
+
+// result sorts the errors by position and drops the repeats that come from
+// fragments spread more than once.
+func (v *gqlValidator) result() []*GraphQLValidationError {
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		if v.errs[i].Line != v.errs[j].Line {
+			return v.errs[i].Line < v.errs[j].Line
+		}
+		return v.errs[i].Column < v.errs[j].Column
+	})
+	return slices.CompactFunc(v.errs, func(a, b *GraphQLValidationError) bool {
+		return *a == *b
+	})
+}
+
+func (v *gqlValidator) operation(op *gqlOperation, variables map[string]any) {
+	var root *GraphQLTypeRef
+	switch op.operation {
+	case "query":
+		root = v.schema.QueryType
+	case "mutation":
+		root = v.schema.MutationType
+	case "subscription":
+		root = v.schema.SubscriptionType
+	}
+	if root == nil || v.types[root.Name] == nil {
+		v.errorf(op.pos, "the schema does not support %s operations", op.operation)
+		return
+	}
+
+	v.usages = nil
+	v.visited = map[string]bool{}
+	defined := map[string]*gqlVariableDefinition{}
+	for _, def := range op.variables {
+		if _, ok := defined[def.name]; ok {
+			v.errorf(def.pos, "variable \"$%s\" is defined more than once", def.name)
+		}
+		defined[def.name] = def
+		v.variableDefinition(def, variables)
+	}
+
+	v.directiveList(op.directives, strings.ToUpper(op.operation))
+	v.selectionSet(op.selections, v.types[root.Name])
+
+	usedVariables := map[string]bool{}
+	for _, usage := range v.usages {
+		usedVariables[usage.name] = true
+		def, ok := defined[usage.name]
+		if !ok {
+			if op.name != "" {
+				v.errorf(usage.pos, "variable \"$%s\" is not defined by operation %q", usage.name, op.name)
+			} else {
+				v.errorf(usage.pos, "variable \"$%s\" is not defined", usage.name)
+			}
+			continue
+		}
+		if !variableAllowed(def, usage) {
+			v.errorf(usage.pos, "variable \"$%s\" of type %q used in position expecting type %q", usage.name, def.typ.String(), usage.expected.String())
+		}
+	}
+	for _, def := range op.variables {
+		if !usedVariables[def.name] {
+			v.errorf(def.pos, "variable \"$%s\" is never used", def.name)
+		}
+	}
+}
+
+// variableDefinition checks the declared type of a variable and the value
+// given for it. Variables of operations that are not run are nil, and their
+// values are not checked.
+func (v *gqlValidator) variableDefinition(def *gqlVariableDefinition, variables map[string]any) {
+	named := v.types[namedType(def.typ).Name]
+	switch {
+	case named == nil:
+		v.errorf(def.pos, "unknown type %q%s", namedType(def.typ).Name, didYouMean(namedType(def.typ).Name, v.typeNames(isInputKind)))
+		return
+	case !isInputKind(named.Kind):
+		v.errorf(def.pos, "variable \"$%s\" cannot be of non-input type %q", def.name, def.typ.String())
+		return
+	}
+	if def.defaultValue != nil {
+		v.value(def.defaultValue, def.typ, false)
+	}
+	if variables == nil {
+		return
+	}
+
+	value, ok := variables[def.name]
+	if !ok || value == nil {
+		if def.typ.Kind == "NON_NULL" && def.defaultValue == nil {
+			v.errorf(def.pos, "variable \"$%s\" of required type %q was not provided", def.name, def.typ.String())
+		}
+		return
+	}
+	if problem := v.jsonValue(value, def.typ, "$"+def.name); problem != "" {
+		v.errorf(def.pos, "variable \"$%s\" got an invalid value: %s", def.name, problem)
+	}
+}
+
+func (v *gqlValidator) selectionSet(selections []*gqlSelection, parent *GraphQLType) {
+	for _, sel := range selections {
+		switch sel.kind {
+		case gqlField:
+			v.field(sel, parent)
+		case gqlFragmentSpread:
+			v.directiveList(sel.directives, "FRAGMENT_SPREAD")
+			frag, ok := v.fragments[sel.name]
+			if !ok {
+				v.errorf(sel.pos, "unknown fragment %q", sel.name)
+				continue
+			}
+			v.used[sel.name] = true
+			if v.visited[sel.name] {
+				continue
+			}
+			v.visited[sel.name] = true
+			v.directiveList(frag.directives, "FRAGMENT_DEFINITION")
+			if t := v.typeCondition(frag.pos, frag.typeCondition, parent); t != nil {
+				v.selectionSet(frag.selections, t)
+			}
+		case gqlInlineFragment:
+			v.directiveList(sel.directives, "INLINE_FRAGMENT")
+			t := parent
+			if sel.typeCondition != "" {
+				t = v.typeCondition(sel.pos, sel.typeCondition, parent)
+			}
+			if t != nil {
+				v.selectionSet(sel.selections, t)
+			}
+		}
+	}
+}
+
+// typeCondition looks up the type a fragment applies to and checks it can
+// apply within parent.
+func (v *gqlValidator) typeCondition(pos gqlPos, name string, parent *GraphQLType) *GraphQLType {
+	t := v.types[name]
+	switch {
+	case t == nil:
+		v.errorf(pos, "unknown type %q%s", name, didYouMean(name, v.typeNames(isCompositeKind)))
+		return nil
+	case !isCompositeKind(t.Kind):
+		v.errorf(pos, "fragment cannot condition on non composite type %q", name)
+		return nil
+	case !v.overlap(t, parent):
+		v.errorf(pos, "fragment on %q can never be spread within type %q", name, parent.Name)
+		return nil
+	}
+	return t
+}
+
+// overlap reports whether an object can be of both types.
+func (v *gqlValidator) overlap(a, b *GraphQLType) bool {
+	possible := func(t *GraphQLType) []string {
+		if t.Kind == "OBJECT" {
+			return []string{t.Name}
+		}
+		names := make([]string, 0, len(t.PossibleTypes))
+		for _, p := range t.PossibleTypes {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+	bTypes := possible(b)
+	for _, name := range possible(a) {
+		if slices.Contains(bTypes, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *gqlValidator) field(sel *gqlSelection, parent *GraphQLType) {
+	v.directiveList(sel.directives, "FIELD")
+	if sel.name == "__typename" {
+		if sel.selections != nil {
+			v.errorf(sel.pos, "field \"__typename\" must not have a selection since type \"String!\" has no subfields")
+		}
+		return
+	}
+	if strings.HasPrefix(sel.name, "__") {
+		// Introspection fields are answered by the server itself.
+		return
+	}
+
+	if parent.Kind == "UNION" {
+		v.errorf(sel.pos, "cannot query field %q on union type %q; use a fragment on one of its types", sel.name, parent.Name)
+		return
+	}
+	idx := slices.IndexFunc(parent.Fields, func(f GraphQLField) bool { return f.Name == sel.name })
+	if idx < 0 {
+		names := make([]string, 0, len(parent.Fields))
+		for _, f := range parent.Fields {
+			names = append(names, f.Name)
+		}
+		v.errorf(sel.pos, "cannot query field %q on type %q%s", sel.name, parent.Name, didYouMean(sel.name, names))
+		return
+	}
+	def := parent.Fields[idx]
+	v.arguments(sel.pos, sel.arguments, def.Args, fmt.Sprintf("field %q", parent.Name+"."+def.Name))
+
+	named := namedType(def.Type)
+	t := v.types[named.Name]
+	switch {
+	case t == nil:
+		// The schema references a type it does not describe; nothing more
+		// can be checked below this field.
+	case isCompositeKind(t.Kind) && sel.selections == nil:
+		v.errorf(sel.pos, "field %q of type %q must have a selection of subfields", sel.name, def.Type.String())
+	case !isCompositeKind(t.Kind) && sel.selections != nil:
+		v.errorf(sel.pos, "field %q must not have a selection since type %q has no subfields", sel.name, def.Type.String())
+	case sel.selections != nil:
+		v.selectionSet(sel.selections, t)
+	}
+}
+
+// arguments checks the arguments given to a field or directive against
+// their definitions; owner names it in errors.
+func (v *gqlValidator) arguments(pos gqlPos, args []*gqlArgument, defs []GraphQLInput, owner string) {
+	given := map[string]bool{}
+	for _, arg := range args {
+		if given[arg.name] {
+			v.errorf(arg.pos, "argument %q is given more than once", arg.name)
+		}
+		given[arg.name] = true
+		idx := slices.IndexFunc(defs, func(d GraphQLInput) bool { return d.Name == arg.name })
+		if idx < 0 {
+			names := make([]string, 0, len(defs))
+			for _, d := range defs {
+				names = append(names, d.Name)
+			}
+			v.errorf(arg.pos, "unknown argument %q on %s%s", arg.name, owner, didYouMean(arg.name, names))
+			continue
+		}
+		v.value(arg.value, defs[idx].Type, defs[idx].DefaultValue != nil)
+	}
+	for _, d := range defs {
+		if d.Type.Kind == "NON_NULL" && d.DefaultValue == nil && !given[d.Name] {
+			v.errorf(pos, "argument %q of type %q is required on %s but not provided", d.Name, d.Type.String(), owner)
+		}
+	}
+}
+
+func (v *gqlValidator) directiveList(directives []*gqlDirective, location string) {
+	for _, d := range directives {
+		def, ok := v.directives[d.name]
+		if !ok {
+			v.errorf(d.pos, "unknown directive \"@%s\"", d.name)
+			continue
+		}
+		if !slices.Contains(def.Locations, location) {
+			v.errorf(d.pos, "directive \"@%s\" may not be used on %s", d.name, location)
+		}
+		v.arguments(d.pos, d.arguments, def.Args, "directive \"@"+d.name+"\"")
+	}
+}
+
+// value checks a literal against the input type it is given for. Variables
+// are recorded and checked once the operation has been walked.
+func (v *gqlValidator) value(val *gqlValue, ref GraphQLTypeRef, hasDefault bool) {
+	if val.kind == gqlVariableValue {
+		v.usages = append(v.usages, gqlVariableUsage{pos: val.pos, name: val.value, expected: ref, hasDefault: hasDefault})
+		return
+	}
+	invalid := func() {
+		v.errorf(val.pos, "expected value of type %q, found %s", ref.String(), val.raw)
+	}
+
+	if ref.Kind == "NON_NULL" {
+		if val.kind == gqlNullValue {
+			invalid()
+			return
+		}
+		ref = *ref.OfType
+	}
+	if val.kind == gqlNullValue {
+		return
+	}
+	if ref.Kind == "LIST" {
+		if val.kind != gqlListValue {
+			v.value(val, *ref.OfType, false)
+			return
+		}
+		for _, item := range val.list {
+			v.value(item, *ref.OfType, false)
+		}
+		return
+	}
+
+	t := v.types[ref.Name]
+	if t == nil {
+		return
+	}
+	switch t.Kind {
+	case "SCALAR":
+		if !literalFitsScalar(val, t.Name) {
+			invalid()
+		}
+	case "ENUM":
+		if val.kind != gqlEnumValue || !slices.ContainsFunc(t.EnumValues, func(e GraphQLEnumValue) bool { return e.Name == val.value }) {
+			names := make([]string, 0, len(t.EnumValues))
+			for _, e := range t.EnumValues {
+				names = append(names, e.Name)
+			}
+			v.errorf(val.pos, "value %s does not exist in %q enum%s", val.raw, t.Name, didYouMean(val.value, names))
+		}
+	case "INPUT_OBJECT":
+		if val.kind != gqlObjectValue {
+			invalid()
+			return
+		}
+		given := map[string]bool{}
+		for _, field := range val.fields {
+			given[field.name] = true
+			idx := slices.IndexFunc(t.InputFields, func(f GraphQLInput) bool { return f.Name == field.name })
+			if idx < 0 {
+				names := make([]string, 0, len(t.InputFields))
+				for _, f := range t.InputFields {
+					names = append(names, f.Name)
+				}
+				v.errorf(field.pos, "field %q is not defined by type %q%s", field.name, t.Name, didYouMean(field.name, names))
+				continue
+			}
+			v.value(field.value, t.InputFields[idx].Type, t.InputFields[idx].DefaultValue != nil)
+		}
+		for _, f := range t.InputFields {
+			if f.Type.Kind == "NON_NULL" && f.DefaultValue == nil && !given[f.Name] {
+				v.errorf(val.pos, "field %q of required type %q was not provided", t.Name+"."+f.Name, f.Type.String())
+			}
+		}
+	}
+}
+
+// literalFitsScalar reports whether a literal is valid for a scalar. Custom
+// scalars accept any literal since only the server knows their format.
+func literalFitsScalar(val *gqlValue, scalar string) bool {
+	switch scalar {
+	case "Int":
+		n, err := strconv.ParseInt(val.value, 10, 32)
+		return val.kind == gqlIntValue && err == nil && n == int64(int32(n))
+	case "Float":
+		return val.kind == gqlIntValue || val.kind == gqlFloatValue
+	case "String":
+		return val.kind == gqlStringValue
+	case "Boolean":
+		return val.kind == gqlBooleanValue
+	case "ID":
+		return val.kind == gqlStringValue || val.kind == gqlIntValue
+	}
+	return true
+}
+
+// jsonValue checks a variable value decoded from JSON against its declared
+// type and describes the first problem found, if any. path locates the
+// value within the variable.
+func (v *gqlValidator) jsonValue(value any, ref GraphQLTypeRef, path string) string {
+	invalid := func() string {
+		encoded, _ := json.Marshal(value)
+		return fmt.Sprintf("expected value of type %q at %q, found %s", ref.String(), path, encoded)
+	}
+
+	if ref.Kind == "NON_NULL" {
+		if value == nil {
+			return invalid()
+		}
+		ref = *ref.OfType
+	}
+	if value == nil {
+		return ""
+	}
+	if ref.Kind == "LIST" {
+		items, ok := value.([]any)
+		if !ok {
+			return v.jsonValue(value, *ref.OfType, path)
+		}
+		for i, item := range items {
+			if problem := v.jsonValue(item, *ref.OfType, fmt.Sprintf("%s[%d]", path, i)); problem != "" {
+				return problem
+			}
+		}
+		return ""
+	}
+
+	t := v.types[ref.Name]
+	if t == nil {
+		return ""
+	}
+	switch t.Kind {
+	case "SCALAR":
+		if !jsonFitsScalar(value, t.Name) {
+			return invalid()
+		}
+	case "ENUM":
+		name, ok := value.(string)
+		if !ok || !slices.ContainsFunc(t.EnumValues, func(e GraphQLEnumValue) bool { return e.Name == name }) {
+			return invalid()
+		}
+	case "INPUT_OBJECT":
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return invalid()
+		}
+		for _, name := range slices.Sorted(maps.Keys(fields)) {
+			idx := slices.IndexFunc(t.InputFields, func(f GraphQLInput) bool { return f.Name == name })
+			if idx < 0 {
+				return fmt.Sprintf("field %q at %q is not defined by type %q", name, path, t.Name)
+			}
+			if problem := v.jsonValue(fields[name], t.InputFields[idx].Type, path+"."+name); problem != "" {
+				return problem
+			}
+		}
+		for _, f := range t.InputFields {
+			if _, ok := fields[f.Name]; !ok && f.Type.Kind == "NON_NULL" && f.DefaultValue == nil {
+				return fmt.Sprintf("field %q of required type %q was not provided at %q", f.Name, f.Type.String(), path)
+			}
+		}
+	}
+	return ""
+}
+
+func jsonFitsScalar(value any, scalar string) bool {
+	switch scalar {
+	case "Int":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32
+	case "Float":
+		_, ok := value.(float64)
+		return ok
+	case "String":
+		_, ok := value.(string)
+		return ok
+	case "Boolean":
+		_, ok := value.(bool)
+		return ok
+	case "ID":
+		switch n := value.(type) {
+		case string:
+			return true
+		case float64:
+			return n == math.Trunc(n)
+		}
+		return false
+	}
+	return true
+}
+
+// variableAllowed reports whether a variable of the declared type may be
+// used where usage expects a value. A nullable variable may stand in for a
+// non-null one when either side has a default.
+func variableAllowed(def *gqlVariableDefinition, usage gqlVariableUsage) bool {
+	declared, expected := def.typ, usage.expected
+	if expected.Kind == "NON_NULL" && declared.Kind != "NON_NULL" {
+		if def.defaultValue == nil && !usage.hasDefault {
+			return false
+		}
+		expected = *expected.OfType
+	}
+	return typeFits(declared, expected)
+}
+
+// typeFits reports whether a value of type declared is always valid for
+// type expected.
+func typeFits(declared, expected GraphQLTypeRef) bool {
+	switch {
+	case expected.Kind == "NON_NULL":
+		return declared.Kind == "NON_NULL" && typeFits(*declared.OfType, *expected.OfType)
+	case declared.Kind == "NON_NULL":
+		return typeFits(*declared.OfType, expected)
+	case expected.Kind == "LIST":
+		return declared.Kind == "LIST" && typeFits(*declared.OfType, *expected.OfType)
+	case declared.Kind == "LIST":
+		return false
+	}
+	return declared.Name == expected.Name
+}
+
+func namedType(ref GraphQLTypeRef) GraphQLTypeRef {
+	for ref.OfType != nil {
+		ref = *ref.OfType
+	}
+	return ref
+}
+
+func isInputKind(kind string) bool {
+	return kind == "SCALAR" || kind == "ENUM" || kind == "INPUT_OBJECT"
+}
+
+func isCompositeKind(kind string) bool {
+	return kind == "OBJECT" || kind == "INTERFACE" || kind == "UNION"
+}
+
+func (v *gqlValidator) typeNames(keep func(kind string) bool) []string {
+	var names []string
+	for name, t := range v.types {
+		if keep(t.Kind) && !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// didYouMean suggests the option closest to a misspelt name, if one is
+// close enough to be a likely typo.
+func didYouMean(name string, options []string) string {
+	best, bestDistance := "", len(name)*2/5+2
+	for _, option := range options {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(option)); d < bestDistance {
+			best, bestDistance = option, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf("; did you mean %q?", best)
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent characters that turn a into b.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -93,3 +93,14 @@ func HTTPOptions(url string, args ...Args) (*http.Response, error) {
 	client := http.Client{}
 	return client.Do(req)
 }
+
+// HTTPMethods maps each supported HTTP verb to the function that sends it.
+var HTTPMethods = map[string]func(string, ...Args) (*http.Response, error){
+	http.MethodGet:     HTTPGet,
+	http.MethodPost:    HTTPPost,
+	http.MethodPut:     HTTPPut,
+	http.MethodPatch:   HTTPPatch,
+	http.MethodDelete:  HTTPDelete,
+	http.MethodHead:    HTTPHead,
+	http.MethodOptions: HTTPOptions,
+}
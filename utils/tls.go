@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSOptions describes how to secure a connection. Paths point to PEM
+// files; empty fields fall back to the system defaults.
+type TLSOptions struct {
+	CACert             string
+	Cert               string
+	Key                string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// LoadTLSConfig builds a tls.Config from opts. A client certificate and key
+// enable mutual TLS and must be given together.
+func LoadTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.CACert)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (opts.Cert == "") != (opts.Key == "") {
+		return nil, fmt.Errorf("a client certificate and key must be given together")
+	}
+	if opts.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.Cert, opts.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// WithTLSConfig secures the connection with cfg. Without it gRPC connections
+// are made in plaintext.
+func WithTLSConfig(cfg *tls.Config) Args {
+	return func(arg *Arg) {
+		arg.TLS = cfg
+	}
+}
@@ -2,10 +2,12 @@ package utils
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 )
 
 func SetParams(url *string, params map[string]string) {
@@ -39,6 +41,23 @@ type (
 		Body    io.Reader
 		Params  map[string]string
 		Headers map[string]string
+		TLS     *tls.Config
+
+		// gRPC only
+		Descriptors    DescriptorSource
+		Timeout        time.Duration
+		ConnectTimeout time.Duration
+		Protocol       string
+
+		// GraphQL only
+		OperationName  string
+		PersistedQuery bool
+		GET            bool
+		Uploads        []GraphQLUpload
+
+		// GraphQL subscriptions only
+		Subprotocol       string
+		ConnectionPayload map[string]any
 	}
 )
 
@@ -61,12 +80,19 @@ func WithHeaders(headers map[string]string) Args {
 }
 
 func GetArgs(args []Args) (body io.Reader, params, headers map[string]string) {
-	arg := Arg{Body: &bytes.Buffer{}, Params: make(map[string]string, 0), Headers: make(map[string]string, 0)}
-	for _, a := range args {
-		a(&arg)
-	}
+	arg := collectArgs(args)
 	body = arg.Body
 	params = arg.Params
 	headers = arg.Headers
 	return
 }
+
+// collectArgs applies args over the defaults and returns every option,
+// including the protocol specific ones GetArgs does not expose.
+func collectArgs(args []Args) Arg {
+	arg := Arg{Body: &bytes.Buffer{}, Params: make(map[string]string, 0), Headers: make(map[string]string, 0)}
+	for _, a := range args {
+		a(&arg)
+	}
+	return arg
+}